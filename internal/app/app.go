@@ -4,10 +4,16 @@ import (
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 
+	"github.com/rufex/sftui/internal/cli"
+	"github.com/rufex/sftui/internal/history"
+	"github.com/rufex/sftui/internal/jobs"
+	"github.com/rufex/sftui/internal/logging"
 	"github.com/rufex/sftui/internal/models"
 	"github.com/rufex/sftui/internal/navigation"
+	"github.com/rufex/sftui/internal/search"
 	"github.com/rufex/sftui/internal/template"
 	"github.com/rufex/sftui/internal/ui"
+	"github.com/rufex/sftui/internal/watcher"
 )
 
 type App struct {
@@ -16,16 +22,29 @@ type App struct {
 	configManager   *template.ConfigManager
 	navHandler      *navigation.Handler
 	uiRenderer      *ui.Renderer
+	cliRunner       *cli.Runner
+	jobQueue        *jobs.Queue
+	logSink         *logging.FileSink
+	watcher         *watcher.Watcher
+	registry        *template.Registry // index of Model.Templates, see templates()
+	contentSearch   *search.Search     // running content search, nil when idle
+	contentSearchID int                // ID of the last content search started
+	confirmAction   func()             // runs when the confirmation popup is accepted
+	history         *history.History   // undo stack of the ConfigManager edits
 }
 
 func New() *App {
-	return &App{
+	app := &App{
 		Model:           &models.Model{},
 		templateManager: template.NewManager(),
 		configManager:   template.NewConfigManager(),
 		navHandler:      navigation.NewHandler(),
 		uiRenderer:      ui.NewRenderer(),
+		cliRunner:       cli.NewRunner(),
+		history:         history.New(""),
 	}
+	app.configManager.SetRecorder(app.recordEdit)
+	return app
 }
 
 func (a *App) InitialModel() *models.Model {
@@ -48,75 +67,82 @@ func (a *App) InitialModel() *models.Model {
 		CurrentSection:    models.TemplatesSection,
 		SelectedTemplate:  0,
 		Templates:         []models.Template{},
-		SelectedTemplates: make(map[int]bool),
+		SelectedTemplates: make(map[models.TemplateID]bool),
 		Firm:              "No firm set",
 		Host:              "No host set",
 		HostTextInput:     hostTextInput,
 		TextPartNameInput: textPartNameInput,
 		TextPartPathInput: textPartPathInput,
 		ShowHelp:          false,
-		Output:            "Ready",
-		SharedPartsUsage:  make(map[string][]string),
+		SharedPartsUsage:  make(map[models.TemplateID][]string),
 	}
+	a.syncHistory()
 
 	firm, host, output := a.configManager.LoadSilverfinConfig()
 	a.Model.Firm = firm
 	a.Model.Host = host
-	a.Model.Output = output
+	if output == "Ready" {
+		a.logInfo("%s", output)
+	} else {
+		a.logWarn("%s", output)
+	}
+
+	if firmID, err := a.configManager.LoadDefaultFirmID(); err == nil {
+		a.Model.FirmID = firmID
+		a.Model.FirmType = "firm"
+		a.Model.DefaultFirmID = firmID
+	}
 
 	firmOptions, err := a.configManager.LoadFirmOptions()
 	if err != nil {
-		a.Model.Output = "Error loading firm options"
+		a.logError("Error loading firm options")
 	} else {
 		a.Model.FirmOptions = firmOptions
 	}
 
-	a.Model.Templates = a.templateManager.LoadTemplates()
+	a.Model.Templates, a.Model.LoadProblems = a.templateManager.LoadTemplates()
 	a.buildSharedPartsMapping()
-	a.Model.FilteredTemplates = a.templateManager.FilterTemplates(a.Model.Templates, "")
+	a.applyFilter()
+	if len(a.Model.LoadProblems) > 0 {
+		a.logWarn("Found %d problems while loading templates (P to show)", len(a.Model.LoadProblems))
+	}
 
 	return a.Model
 }
 
 func (a *App) Init() tea.Cmd {
-	return nil
+	return a.startWatcher()
 }
 
-func (a *App) buildSharedPartsMapping() {
-	a.Model.SharedPartsUsage = make(map[string][]string)
-
+func (a *App) selectedTemplatesList() []models.Template {
+	var templates []models.Template
 	for _, template := range a.Model.Templates {
-		if template.Category == "shared_parts" {
-			if usedInInterface, exists := template.Config["used_in"]; exists {
-				if usedInArray, ok := usedInInterface.([]interface{}); ok {
-					for _, usageInterface := range usedInArray {
-						if usage, ok := usageInterface.(map[string]interface{}); ok {
-							if typeStr, typeExists := usage["type"].(string); typeExists {
-								if handleStr, handleExists := usage["handle"].(string); handleExists {
-									var templateCategory string
-									switch typeStr {
-									case "reconciliation_text", "reconciliationText", "reconciliation":
-										templateCategory = "reconciliation_texts"
-									case "export_file", "exportFile":
-										templateCategory = "export_files"
-									case "account_template", "accountTemplate":
-										templateCategory = "account_templates"
-									default:
-										continue
-									}
-
-									templateKey := templateCategory + "/" + handleStr
-
-									if a.Model.SharedPartsUsage[templateKey] == nil {
-										a.Model.SharedPartsUsage[templateKey] = []string{}
-									}
-									a.Model.SharedPartsUsage[templateKey] = append(a.Model.SharedPartsUsage[templateKey], template.Name)
-								}
-							}
-						}
-					}
-				}
-			}
+		if a.Model.SelectedTemplates[template.ID()] {
+			templates = append(templates, template)
 		}
 	}
+	return templates
+}
+
+// templates returns the registry of the loaded templates, rebuilding it when Model.Templates
+// was replaced since it was built.
+func (a *App) templates() *template.Registry {
+	if a.registry == nil || !a.registry.Indexes(a.Model.Templates) {
+		a.registry = template.NewRegistry(a.Model.Templates)
+	}
+	return a.registry
+}
+
+// currentTemplate returns the template under the cursor of the Templates section.
+func (a *App) currentTemplate() (models.Template, bool) {
+	if a.Model.SelectedTemplate < 0 || a.Model.SelectedTemplate >= len(a.Model.FilteredTemplates) {
+		return models.Template{}, false
+	}
+	return a.templates().Get(a.Model.FilteredTemplates[a.Model.SelectedTemplate])
+}
+
+// buildSharedPartsMapping maps every template to the shared parts whose used_in lists it.
+func (a *App) buildSharedPartsMapping() {
+	a.registry = template.NewRegistry(a.Model.Templates)
+	a.Model.SharedPartsUsage = a.registry.SharedPartsUsage()
 }
@@ -35,15 +35,15 @@ func TestTemplateSelection(t *testing.T) {
 
 	// Simulate template selection
 	if len(m.FilteredTemplates) > 0 {
-		actualIndex := m.FilteredTemplates[0]
-		m.SelectedTemplates[actualIndex] = true
+		id := m.FilteredTemplates[0]
+		m.SelectedTemplates[id] = true
 
 		if len(m.SelectedTemplates) != 1 {
 			t.Errorf("Expected 1 template selected, got %d", len(m.SelectedTemplates))
 		}
 
-		if !m.SelectedTemplates[actualIndex] {
-			t.Errorf("Expected template at index %d to be selected", actualIndex)
+		if !m.SelectedTemplates[id] {
+			t.Errorf("Expected template %s to be selected", id)
 		}
 	}
 }
@@ -128,10 +128,14 @@ func TestBuildSharedPartsMapping(t *testing.T) {
 func TestAppInit(t *testing.T) {
 	app := New()
 	cmd := app.Init()
+	defer app.StopWatcher()
 
-	// Init should return nil command
-	if cmd != nil {
-		t.Errorf("Expected Init() to return nil, got %v", cmd)
+	// Init starts watching the templates and waits for changes
+	if cmd == nil {
+		t.Errorf("Expected Init() to return a command waiting for template changes")
+	}
+	if app.watcher == nil {
+		t.Errorf("Expected Init() to start the watcher")
 	}
 }
 
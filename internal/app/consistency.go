@@ -0,0 +1,100 @@
+package app
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// openConsistency checks used_in metadata against the Liquid includes and shows the issues.
+func (a *App) openConsistency() {
+	a.Model.ShowConsistency = true
+	a.checkConsistency()
+	a.logInfo("%s", a.uiRenderer.ConsistencyTitle(a.Model))
+}
+
+func (a *App) checkConsistency() {
+	a.Model.ConsistencyIssues = a.templateManager.CheckConsistency(a.Model.Templates, a.Model.SharedPartsUsage)
+	a.Model.SelectedIssue = 0
+	a.Model.ConsistencyOffset = 0
+}
+
+func (a *App) closeConsistency() {
+	a.Model.ShowConsistency = false
+	a.Model.ConsistencyIssues = nil
+}
+
+func (a *App) handleConsistencyKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return a, tea.Quit
+	case "esc", "q", "C":
+		a.closeConsistency()
+	case "up", "k":
+		a.moveIssueCursor(-1)
+	case "down", "j":
+		a.moveIssueCursor(1)
+	case "f":
+		a.fixConsistency()
+	case "enter":
+		a.jumpToIssue()
+	}
+	return a, nil
+}
+
+func (a *App) moveIssueCursor(delta int) {
+	if len(a.Model.ConsistencyIssues) == 0 {
+		return
+	}
+
+	a.Model.SelectedIssue = min(max(0, a.Model.SelectedIssue+delta), len(a.Model.ConsistencyIssues)-1)
+
+	pageSize := a.Model.FullScreenContentHeight()
+	if a.Model.SelectedIssue < a.Model.ConsistencyOffset {
+		a.Model.ConsistencyOffset = a.Model.SelectedIssue
+	} else if a.Model.SelectedIssue >= a.Model.ConsistencyOffset+pageSize {
+		a.Model.ConsistencyOffset = a.Model.SelectedIssue - pageSize + 1
+	}
+}
+
+// fixConsistency rewrites used_in to match the Liquid includes and checks again.
+func (a *App) fixConsistency() {
+	issues := a.Model.ConsistencyIssues
+	fixed, err := a.configManager.FixConsistency(a.Model.Templates, issues)
+
+	// Reload every shared part that may have been rewritten, also after a partial failure
+	reloaded := make(map[string]bool)
+	for _, issue := range issues {
+		for _, candidate := range a.Model.Templates {
+			if candidate.Category == "shared_parts" && candidate.Name == issue.SharedPart && !reloaded[candidate.Path] {
+				reloaded[candidate.Path] = true
+				a.reloadTemplate(candidate.Path, candidate.Category)
+			}
+		}
+	}
+
+	a.checkConsistency()
+	if err != nil {
+		a.logError("Error fixing used_in after %d fixes: %v", fixed, err)
+		return
+	}
+	if len(a.Model.ConsistencyIssues) > 0 {
+		a.logWarn("Fixed %d issues, %d remain (Liquid changes needed)", fixed, len(a.Model.ConsistencyIssues))
+		return
+	}
+	a.logInfo("Fixed %d issues", fixed)
+}
+
+// jumpToIssue closes the panel and selects the template of the highlighted issue.
+func (a *App) jumpToIssue() {
+	if a.Model.SelectedIssue >= len(a.Model.ConsistencyIssues) {
+		return
+	}
+
+	issue := a.Model.ConsistencyIssues[a.Model.SelectedIssue]
+	if candidate, ok := a.templates().Get(issue.Template); ok {
+		a.closeConsistency()
+		a.selectTemplate(candidate.ID())
+		a.logInfo("Showing %s", candidate.Name)
+		return
+	}
+	a.logWarn("Template %s not found", issue.Template)
+}
@@ -0,0 +1,70 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/rufex/sftui/internal/models"
+)
+
+func TestConsistencyPanelFixesUsedIn(t *testing.T) {
+	root := t.TempDir()
+	rtDir := filepath.Join(root, "reconciliation_texts", "rt_1")
+	sharedPartDir := filepath.Join(root, "shared_parts", "shared_part_1")
+	files := map[string]string{
+		filepath.Join(rtDir, "config.json"):         `{"handle": "rt_one"}`,
+		filepath.Join(rtDir, "main.liquid"):         `{% include "shared/shared_part_1" %}`,
+		filepath.Join(sharedPartDir, "config.json"): `{"used_in": []}`,
+	}
+	for path, content := range files {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	app := New()
+	m := app.InitialModel()
+	m.Templates = []models.Template{
+		app.templateManager.LoadTemplate(rtDir, "reconciliation_texts"),
+		app.templateManager.LoadTemplate(sharedPartDir, "shared_parts"),
+	}
+	m.FilteredTemplates = app.templateManager.FilterTemplates(m.Templates, "")
+	m.Width = 140
+	m.Height = 30
+	app.Model = m
+	app.buildSharedPartsMapping()
+
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'C'}})
+	if !app.Model.ShowConsistency || len(app.Model.ConsistencyIssues) != 1 {
+		t.Fatalf("Expected one consistency issue, got %+v", app.Model.ConsistencyIssues)
+	}
+	if !strings.Contains(app.View(), "missing from used_in") {
+		t.Errorf("Expected the panel to describe the missing link")
+	}
+
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'f'}})
+	if len(app.Model.ConsistencyIssues) != 0 {
+		t.Errorf("Expected no issues after fixing, got %+v", app.Model.ConsistencyIssues)
+	}
+	if usage := app.Model.SharedPartsUsage[models.TemplateID{Category: "reconciliation_texts", Name: "rt_1"}]; len(usage) != 1 {
+		t.Errorf("Expected rt_1 to use shared_part_1 after fixing, got %v", usage)
+	}
+	if data, _ := os.ReadFile(filepath.Join(sharedPartDir, "config.json")); !strings.Contains(string(data), `"handle": "rt_one"`) {
+		t.Errorf("Expected used_in to list rt_1 by its handle, got %s", data)
+	}
+	if last := app.Model.Log[len(app.Model.Log)-1]; last.Message != "Fixed 1 issues" {
+		t.Errorf("Expected 'Fixed 1 issues', got %q", last.Message)
+	}
+
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if app.Model.ShowConsistency {
+		t.Errorf("Expected Esc to close the panel")
+	}
+}
@@ -0,0 +1,259 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/rufex/sftui/internal/models"
+	"github.com/rufex/sftui/internal/search"
+)
+
+// openContentSearch shows the content search screen with the query input focused.
+func (a *App) openContentSearch() {
+	a.Model.ShowContentSearch = true
+	a.Model.ContentSearchEditing = true
+	a.logInfo("Search the Liquid of every template (Enter to search, Esc to close)")
+}
+
+func (a *App) closeContentSearch() {
+	a.cancelContentSearch()
+	a.Model.ShowContentSearch = false
+	a.Model.ContentSearchEditing = false
+}
+
+func (a *App) cancelContentSearch() {
+	if a.contentSearch != nil {
+		a.contentSearch.Cancel()
+		a.contentSearch = nil
+	}
+	a.Model.ContentSearchRunning = false
+}
+
+func (a *App) handleContentSearchKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if a.Model.ContentSearchEditing {
+		return a.handleContentSearchInput(msg)
+	}
+
+	pageSize := a.Model.FullScreenContentHeight()
+
+	switch msg.String() {
+	case "ctrl+c":
+		return a, tea.Quit
+	case "esc", "q", "F":
+		a.closeContentSearch()
+	case "/":
+		a.Model.ContentSearchEditing = true
+	case "up", "k":
+		a.moveContentCursor(-1)
+	case "down", "j":
+		a.moveContentCursor(1)
+	case "pgup", "ctrl+u":
+		a.moveContentCursor(-pageSize / 2)
+	case "pgdown", "ctrl+d":
+		a.moveContentCursor(pageSize / 2)
+	case "home":
+		a.moveContentCursor(-len(a.Model.ContentRows))
+	case "G", "end":
+		a.moveContentCursor(len(a.Model.ContentRows))
+	case "enter":
+		a.openContentMatch()
+	case "s":
+		a.selectContentMatches()
+	}
+	return a, nil
+}
+
+func (a *App) handleContentSearchInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return a, tea.Quit
+	case "esc":
+		if len(a.Model.ContentRows) == 0 && !a.Model.ContentSearchRunning {
+			a.closeContentSearch()
+		} else {
+			a.Model.ContentSearchEditing = false
+		}
+	case "enter":
+		if a.Model.ContentSearchQuery == "" {
+			return a, nil
+		}
+		a.Model.ContentSearchEditing = false
+		return a, a.startContentSearch()
+	case "backspace":
+		if len(a.Model.ContentSearchQuery) > 0 {
+			a.Model.ContentSearchQuery = a.Model.ContentSearchQuery[:len(a.Model.ContentSearchQuery)-1]
+		}
+	default:
+		if msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace {
+			for _, char := range msg.Runes {
+				if char >= 32 {
+					a.Model.ContentSearchQuery += string(char)
+				}
+			}
+		}
+	}
+	return a, nil
+}
+
+// startContentSearch greps the Liquid files of every template for the query in the background,
+// replacing any search still running.
+func (a *App) startContentSearch() tea.Cmd {
+	a.cancelContentSearch()
+
+	var files []search.File
+	for _, template := range a.Model.Templates {
+		for _, file := range a.templateManager.LiquidFiles(template) {
+			files = append(files, search.File{Template: template.ID(), Path: filepath.Join(template.Path, file), Name: file})
+		}
+	}
+
+	a.contentSearchID++
+	a.contentSearch = search.New(a.contentSearchID)
+	a.Model.ContentMatches = nil
+	a.Model.ContentRows = nil
+	a.Model.ContentSelected = 0
+	a.Model.ContentOffset = 0
+	a.Model.ContentSearchFiles = 0
+	a.Model.ContentSearchTotal = len(files)
+	a.Model.ContentSearchRunning = true
+	return a.contentSearch.Start(a.Model.ContentSearchQuery, files)
+}
+
+// handleContentSearchResult merges the matches of a searched file, keeping the highlighted match.
+func (a *App) handleContentSearchResult(msg search.ResultMsg) (tea.Model, tea.Cmd) {
+	if a.contentSearch == nil || msg.Search != a.contentSearch.ID() {
+		return a, nil
+	}
+
+	a.Model.ContentSearchFiles++
+	if msg.Err != nil {
+		a.logWarn("Content search: %v", msg.Err)
+	}
+	if len(msg.Matches) > 0 {
+		selected, hasSelection := a.selectedContentMatch()
+
+		matches := append(a.Model.ContentMatches, msg.Matches...)
+		sort.SliceStable(matches, func(i, j int) bool {
+			if matches[i].Template != matches[j].Template {
+				return matches[i].Template.String() < matches[j].Template.String()
+			}
+			if matches[i].File != matches[j].File {
+				return matches[i].File < matches[j].File
+			}
+			return matches[i].Line < matches[j].Line
+		})
+		a.Model.ContentMatches = matches
+		a.Model.ContentRows = a.uiRenderer.ContentSearchRows(matches)
+
+		a.Model.ContentSelected = 0
+		for i, row := range a.Model.ContentRows {
+			if row.Match < 0 {
+				continue
+			}
+			match := matches[row.Match]
+			if !hasSelection || (match.Path == selected.Path && match.Line == selected.Line) {
+				a.Model.ContentSelected = i
+				break
+			}
+		}
+		a.moveContentCursor(0)
+	}
+
+	return a, a.contentSearch.Next()
+}
+
+func (a *App) handleContentSearchDone(msg search.DoneMsg) (tea.Model, tea.Cmd) {
+	if a.contentSearch == nil || msg.Search != a.contentSearch.ID() {
+		return a, nil
+	}
+
+	a.contentSearch = nil
+	a.Model.ContentSearchRunning = false
+	if msg.Cancelled {
+		a.logWarn("Content search for %q cancelled", a.Model.ContentSearchQuery)
+		return a, nil
+	}
+	a.logInfo("%s", a.uiRenderer.ContentSearchTitle(a.Model))
+	return a, nil
+}
+
+// selectedContentMatch returns the match on the highlighted row.
+func (a *App) selectedContentMatch() (models.ContentMatch, bool) {
+	if a.Model.ContentSelected >= len(a.Model.ContentRows) {
+		return models.ContentMatch{}, false
+	}
+	row := a.Model.ContentRows[a.Model.ContentSelected]
+	if row.Match < 0 || row.Match >= len(a.Model.ContentMatches) {
+		return models.ContentMatch{}, false
+	}
+	return a.Model.ContentMatches[row.Match], true
+}
+
+// moveContentCursor moves the highlight by delta matching lines, skipping template, file and
+// context rows, and scrolls it into view.
+func (a *App) moveContentCursor(delta int) {
+	rows := a.Model.ContentRows
+	if len(rows) == 0 {
+		return
+	}
+
+	step := 1
+	if delta < 0 {
+		step, delta = -1, -delta
+	}
+	for ; delta > 0; delta-- {
+		next := a.Model.ContentSelected + step
+		for next >= 0 && next < len(rows) && rows[next].Match < 0 {
+			next += step
+		}
+		if next < 0 || next >= len(rows) {
+			break
+		}
+		a.Model.ContentSelected = next
+	}
+
+	// Keep the context, file and template rows above the match in view
+	pageSize := a.Model.FullScreenContentHeight()
+	top := a.Model.ContentSelected
+	for top > 0 && rows[top-1].Match < 0 && a.Model.ContentSelected-top < pageSize-1 {
+		top--
+	}
+	if top < a.Model.ContentOffset {
+		a.Model.ContentOffset = top
+	} else if a.Model.ContentSelected >= a.Model.ContentOffset+pageSize {
+		a.Model.ContentOffset = a.Model.ContentSelected - pageSize + 1
+	}
+}
+
+// openContentMatch shows the file of the highlighted match in the source preview, at the match.
+func (a *App) openContentMatch() {
+	match, ok := a.selectedContentMatch()
+	if !ok {
+		return
+	}
+	a.openPreview(match.Path, fmt.Sprintf("%s/%s", match.Template.Name, match.File))
+	if !a.Model.ShowPreview {
+		return
+	}
+	a.Model.PreviewSearchQuery = a.Model.ContentSearchQuery
+	a.Model.PreviewMatchLine = match.Line - 1
+	a.scrollPreview(match.Line - 1 - a.Model.FullScreenContentHeight()/3)
+}
+
+// selectContentMatches adds every template with a match to the selection and closes the search
+// so a bulk action can be run on them.
+func (a *App) selectContentMatches() {
+	count := 0
+	for _, match := range a.Model.ContentMatches {
+		if !a.Model.SelectedTemplates[match.Template] {
+			a.Model.SelectedTemplates[match.Template] = true
+			count++
+		}
+	}
+	a.closeContentSearch()
+	a.Model.CurrentSection = models.TemplatesSection
+	a.logInfo("Selected %d templates matching %q, %d selected in total (Enter for actions)", count, a.Model.ContentSearchQuery, len(a.Model.SelectedTemplates))
+}
@@ -0,0 +1,90 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/rufex/sftui/internal/models"
+)
+
+func TestContentSearch(t *testing.T) {
+	root := t.TempDir()
+	write := func(path, content string) {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rtDir := filepath.Join(root, "reconciliation_texts", "rt_1")
+	write(filepath.Join(rtDir, "main.liquid"), "{% include 'parts/notes' %}\n")
+	write(filepath.Join(rtDir, "text_parts", "notes.liquid"), "line 1\n{{ custom.period.foo }}\nline 3\n")
+	atDir := filepath.Join(root, "account_templates", "at_1")
+	write(filepath.Join(atDir, "main.liquid"), "{% if custom.period.foo %}\n")
+	otherDir := filepath.Join(root, "account_templates", "at_2")
+	write(filepath.Join(otherDir, "main.liquid"), "nothing\n")
+
+	app := New()
+	m := app.InitialModel()
+	m.Templates = []models.Template{
+		{Name: "rt_1", Category: "reconciliation_texts", Path: rtDir, Config: map[string]interface{}{
+			"text_parts": map[string]interface{}{"notes": "text_parts/notes.liquid"},
+		}},
+		{Name: "at_1", Category: "account_templates", Path: atDir},
+		{Name: "at_2", Category: "account_templates", Path: otherDir},
+	}
+	m.SelectedTemplates = make(map[models.TemplateID]bool)
+	m.Width = 120
+	m.Height = 30
+	app.Model = m
+	app.applyFilter()
+
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'F'}})
+	if !app.Model.ShowContentSearch || !app.Model.ContentSearchEditing {
+		t.Fatalf("Expected 'F' to open the content search")
+	}
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("custom.period.foo")})
+	_, cmd := app.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if !app.Model.ContentSearchRunning {
+		t.Errorf("Expected the search to run in the background")
+	}
+	runCommands(app, cmd)
+
+	if app.Model.ContentSearchRunning || len(app.Model.ContentMatches) != 2 {
+		t.Fatalf("Expected 2 matches once done, got %+v", app.Model.ContentMatches)
+	}
+	view := app.View()
+	for _, expected := range []string{"2 matches in 2 templates", "[AT] at_1", "text_parts/notes.liquid", "   1  line 1"} {
+		if !strings.Contains(view, expected) {
+			t.Errorf("Expected the results to show %q", expected)
+		}
+	}
+
+	// The selection follows the match it was on while results streamed in, so start from the top
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyHome})
+	if match, _ := app.selectedContentMatch(); match.Template.Name != "at_1" {
+		t.Errorf("Expected home to move to the match in at_1, got %+v", match)
+	}
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyDown})
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if !app.Model.ShowPreview || app.Model.PreviewTitle != "rt_1/text_parts/notes.liquid" || app.Model.PreviewMatchLine != 1 {
+		t.Errorf("Expected the preview at line 2 of the text part, got %q line %d", app.Model.PreviewTitle, app.Model.PreviewMatchLine)
+	}
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if app.Model.ShowPreview || !app.Model.ShowContentSearch {
+		t.Errorf("Expected closing the preview to return to the search results")
+	}
+
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}})
+	if app.Model.ShowContentSearch {
+		t.Errorf("Expected 's' to close the search")
+	}
+	if len(app.Model.SelectedTemplates) != 2 || !app.Model.SelectedTemplates[m.Templates[0].ID()] || !app.Model.SelectedTemplates[m.Templates[1].ID()] {
+		t.Errorf("Expected rt_1 and at_1 to be selected, got %v", app.Model.SelectedTemplates)
+	}
+}
@@ -0,0 +1,114 @@
+package app
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/rufex/sftui/internal/models"
+)
+
+// editorFinishedMsg is sent when the external editor exits and the TUI resumes.
+type editorFinishedMsg struct {
+	template models.Template // template owning the edited file
+	path     string
+	err      error
+}
+
+// editorCommand builds the command opening path in $VISUAL or $EDITOR, falling back to vi.
+func editorCommand(path string) *exec.Cmd {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+
+	// The editor variable may contain arguments, e.g. "code --wait"
+	args := strings.Fields(editor)
+	if len(args) == 0 {
+		args = []string{"vi"}
+	}
+
+	return exec.Command(args[0], append(args[1:], path)...)
+}
+
+// handleEditKey opens the file behind the current selection in the editor: the highlighted
+// Liquid file or config.json in the Details section, the previewed file, or main.liquid.
+func (a *App) handleEditKey(configOnly bool) (tea.Model, tea.Cmd) {
+	template, ok := a.currentTemplate()
+	if !ok {
+		return a, nil
+	}
+	path := a.templateManager.GetMainLiquidPath(template)
+	owner := template
+
+	switch {
+	case configOnly:
+		path = filepath.Join(template.Path, "config.json")
+	case a.Model.ShowPreview:
+		path = a.Model.PreviewPath
+		owner = a.templateOwningFile(path, template)
+	case a.Model.CurrentSection == models.DetailsSection:
+		configFieldCount := a.GetConfigFieldCount(template)
+		if a.Model.SelectedDetailField < configFieldCount {
+			path = filepath.Join(template.Path, "config.json")
+		} else if liquidPath, _ := a.detailLiquidFile(template, configFieldCount); liquidPath != "" {
+			path = liquidPath
+			owner = a.templateOwningFile(path, template)
+		}
+	}
+
+	return a, a.openEditor(owner, path)
+}
+
+func (a *App) openEditor(owner models.Template, path string) tea.Cmd {
+	a.logInfo("Opening %s in editor", path)
+	return tea.ExecProcess(editorCommand(path), func(err error) tea.Msg {
+		return editorFinishedMsg{template: owner, path: path, err: err}
+	})
+}
+
+// templateOwningFile returns the template whose directory contains path, defaulting to fallback.
+func (a *App) templateOwningFile(path string, fallback models.Template) models.Template {
+	for _, template := range a.Model.Templates {
+		if strings.HasPrefix(path, template.Path+string(filepath.Separator)) {
+			return template
+		}
+	}
+	return fallback
+}
+
+func (a *App) handleEditorFinished(msg editorFinishedMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		a.logError("Error running editor: %v", msg.err)
+	}
+
+	a.reloadTemplate(msg.template.Path, msg.template.Category)
+
+	if a.Model.ShowPreview && a.Model.PreviewPath == msg.path {
+		if data, err := os.ReadFile(msg.path); err == nil {
+			a.Model.PreviewContent = string(data)
+			a.scrollPreview(0)
+		}
+	}
+
+	if msg.err == nil {
+		a.logInfo("Reloaded %s after editing %s", msg.template.Name, filepath.Base(msg.path))
+	}
+	return a, nil
+}
+
+// reloadTemplate re-reads a template from disk and refreshes everything derived from it.
+func (a *App) reloadTemplate(templatePath, category string) {
+	reloaded := a.templateManager.LoadTemplate(templatePath, category)
+	for i, template := range a.Model.Templates {
+		if template.Path == templatePath {
+			a.Model.Templates[i] = reloaded
+			break
+		}
+	}
+	a.buildSharedPartsMapping()
+	a.refreshLoadProblems(reloaded)
+}
@@ -0,0 +1,84 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestEditorCommand(t *testing.T) {
+	tests := []struct {
+		visual   string
+		editor   string
+		expected string
+	}{
+		{"", "", "vi /tmp/main.liquid"},
+		{"", "nano", "nano /tmp/main.liquid"},
+		{"code --wait", "nano", "code --wait /tmp/main.liquid"},
+	}
+
+	for _, test := range tests {
+		t.Setenv("VISUAL", test.visual)
+		t.Setenv("EDITOR", test.editor)
+
+		cmd := editorCommand("/tmp/main.liquid")
+		if result := strings.Join(cmd.Args, " "); result != test.expected {
+			t.Errorf("editorCommand with VISUAL=%q EDITOR=%q = %q, expected %q", test.visual, test.editor, result, test.expected)
+		}
+	}
+}
+
+func TestEditKeyReturnsCommand(t *testing.T) {
+	app := newPreviewTestApp(t)
+	app.Model.SelectedDetailField = 1
+
+	_, cmd := app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'e'}})
+	if cmd == nil {
+		t.Errorf("Expected 'e' to return an editor command")
+	}
+
+	if !strings.Contains(app.Model.Output, "main.liquid") {
+		t.Errorf("Expected the highlighted main.liquid to be opened, got: %s", app.Model.Output)
+	}
+}
+
+func TestEditorFinishedReloadsTemplate(t *testing.T) {
+	app := newPreviewTestApp(t)
+	template := app.Model.Templates[0]
+
+	config := `{"reconciliation_type": "reconciliation_not_necessary", "text_parts": {}}`
+	configPath := filepath.Join(template.Path, "config.json")
+	if err := os.WriteFile(configPath, []byte(config), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	_, _ = app.Update(editorFinishedMsg{template: template, path: configPath})
+
+	reloaded := app.Model.Templates[0]
+	if reloaded.Config["reconciliation_type"] != "reconciliation_not_necessary" {
+		t.Errorf("Expected reloaded reconciliation_type, got %v", reloaded.Config["reconciliation_type"])
+	}
+	if strings.Contains(app.uiRenderer.DetailsView(app.Model), "part_1") {
+		t.Errorf("Expected details to reflect the removed text part")
+	}
+}
+
+func TestEditorFinishedRefreshesPreview(t *testing.T) {
+	app := newPreviewTestApp(t)
+	template := app.Model.Templates[0]
+	mainPath := filepath.Join(template.Path, "main.liquid")
+	app.openPreview(mainPath, "rt_1/main.liquid")
+
+	if err := os.WriteFile(mainPath, []byte("edited"), 0644); err != nil {
+		t.Fatalf("Failed to write main.liquid: %v", err)
+	}
+
+	_, _ = app.Update(editorFinishedMsg{template: template, path: mainPath})
+
+	if app.Model.PreviewContent != "edited" {
+		t.Errorf("Expected preview to show edited content, got %q", app.Model.PreviewContent)
+	}
+}
@@ -0,0 +1,71 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/rufex/sftui/internal/models"
+)
+
+// SetFirm makes the firm with the given ID the one this session works with, as chosen with the
+// --firm flag. The defaultFirmIDs of the Silverfin config are left untouched.
+func (a *App) SetFirm(id string) error {
+	return a.setFirmOption(id, "firm")
+}
+
+// SetPartner switches the session to partner mode for the partner with the given ID, as chosen
+// with the --partner flag.
+func (a *App) SetPartner(id string) error {
+	return a.setFirmOption(id, "partner")
+}
+
+func (a *App) setFirmOption(id, kind string) error {
+	index := a.firmOptionIndex(id, kind)
+	if index < 0 {
+		return fmt.Errorf("%s %s not found in the Silverfin config", kind, id)
+	}
+	a.useFirm(a.Model.FirmOptions[index])
+	return nil
+}
+
+// firm returns the firm or partner of this session, the target of CLI invocations.
+func (a *App) firm() models.FirmOption {
+	return models.FirmOption{ID: a.Model.FirmID, Type: a.Model.FirmType}
+}
+
+// useFirm switches the firm or partner used by the views and CLI invocations for this session.
+// Choosing a partner turns on partner mode: templates are looked up in their partner_id maps and
+// actions run the CLI with --partner.
+func (a *App) useFirm(option models.FirmOption) {
+	a.Model.FirmID = option.ID
+	a.Model.FirmType = option.Type
+	a.Model.Firm = fmt.Sprintf("%s (%s)", option.Name, option.ID)
+}
+
+// saveDefaultFirm stores a firm in defaultFirmIDs, so later sessions in this repository start
+// with it. Partners have no default in the Silverfin config.
+func (a *App) saveDefaultFirm(option models.FirmOption) {
+	if option.Type == "partner" {
+		a.logWarn("Partners cannot be saved as default firm, using %s for this session only", option.Name)
+		return
+	}
+	if err := a.configManager.SetDefaultFirm(option.ID); err != nil {
+		a.logError("Error setting default firm: %v", err)
+		return
+	}
+	a.Model.DefaultFirmID = option.ID
+	a.logInfo("Default firm set to %s", option.Name)
+}
+
+// firmOptionIndex returns the position of a firm or partner in FirmOptions, or -1. Firms and
+// partners can share IDs, so kind tells them apart.
+func (a *App) firmOptionIndex(id, kind string) int {
+	if id == "" {
+		return -1
+	}
+	for i, option := range a.Model.FirmOptions {
+		if option.ID == id && option.Type == kind {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,133 @@
+package app
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/rufex/sftui/internal/models"
+	"github.com/rufex/sftui/internal/template"
+)
+
+// openFirmMatrix shows every template against the firms and partners of the Silverfin config,
+// starting on the column of the current firm.
+func (a *App) openFirmMatrix() {
+	ids := make([]models.TemplateID, len(a.Model.Templates))
+	for i, t := range a.Model.Templates {
+		ids[i] = t.ID()
+	}
+	template.SortTemplates(a.templates(), ids, models.SortByCategory, nil)
+
+	a.Model.ShowFirmMatrix = true
+	a.Model.FirmMatrixTemplates = ids
+	a.Model.FirmMatrixSelected = max(0, indexOf(ids, a.cursorTemplateID()))
+	a.Model.FirmMatrixOffset = 0
+	a.Model.FirmMatrixColumn = max(0, a.firmOptionIndex(a.Model.FirmID, a.Model.FirmType))
+	a.Model.FirmMatrixColumnOffset = 0
+	a.moveFirmMatrixCursor(0, 0)
+	a.logInfo("%s", a.uiRenderer.FirmMatrixTitle(a.Model))
+}
+
+func (a *App) closeFirmMatrix() {
+	a.Model.ShowFirmMatrix = false
+}
+
+func (a *App) handleFirmMatrixKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	pageSize := a.Model.FullScreenContentHeight()
+
+	switch msg.String() {
+	case "ctrl+c":
+		return a, tea.Quit
+	case "esc", "q", "M":
+		a.closeFirmMatrix()
+	case "up", "k":
+		a.moveFirmMatrixCursor(-1, 0)
+	case "down", "j":
+		a.moveFirmMatrixCursor(1, 0)
+	case "left", "h":
+		a.moveFirmMatrixCursor(0, -1)
+	case "right", "l":
+		a.moveFirmMatrixCursor(0, 1)
+	case "pgup", "ctrl+u":
+		a.moveFirmMatrixCursor(-pageSize/2, 0)
+	case "pgdown", "ctrl+d":
+		a.moveFirmMatrixCursor(pageSize/2, 0)
+	case "home":
+		a.moveFirmMatrixCursor(-len(a.Model.FirmMatrixTemplates), 0)
+	case "G", "end":
+		a.moveFirmMatrixCursor(len(a.Model.FirmMatrixTemplates), 0)
+	case "enter":
+		a.jumpToFirmMatrixTemplate()
+	case "m":
+		if a.Model.FirmMatrixColumn < len(a.Model.FirmOptions) {
+			a.closeFirmMatrix()
+			a.filterMissingIn(a.Model.FirmOptions[a.Model.FirmMatrixColumn])
+		}
+	}
+	return a, nil
+}
+
+// moveFirmMatrixCursor moves the highlighted cell and scrolls it into view.
+func (a *App) moveFirmMatrixCursor(rows, columns int) {
+	if n := len(a.Model.FirmMatrixTemplates); n > 0 {
+		a.Model.FirmMatrixSelected = min(max(0, a.Model.FirmMatrixSelected+rows), n-1)
+	}
+	if n := len(a.Model.FirmOptions); n > 0 {
+		a.Model.FirmMatrixColumn = min(max(0, a.Model.FirmMatrixColumn+columns), n-1)
+	}
+
+	// The two header lines take part of the screen
+	pageSize := max(1, a.Model.FullScreenContentHeight()-2)
+	if a.Model.FirmMatrixSelected < a.Model.FirmMatrixOffset {
+		a.Model.FirmMatrixOffset = a.Model.FirmMatrixSelected
+	} else if a.Model.FirmMatrixSelected >= a.Model.FirmMatrixOffset+pageSize {
+		a.Model.FirmMatrixOffset = a.Model.FirmMatrixSelected - pageSize + 1
+	}
+
+	visible := a.uiRenderer.FirmMatrixColumns(a.Model, a.Model.Width-4)
+	if a.Model.FirmMatrixColumn < a.Model.FirmMatrixColumnOffset {
+		a.Model.FirmMatrixColumnOffset = a.Model.FirmMatrixColumn
+	} else if a.Model.FirmMatrixColumn >= a.Model.FirmMatrixColumnOffset+visible {
+		a.Model.FirmMatrixColumnOffset = a.Model.FirmMatrixColumn - visible + 1
+	}
+}
+
+// jumpToFirmMatrixTemplate closes the matrix and selects the highlighted template.
+func (a *App) jumpToFirmMatrixTemplate() {
+	if a.Model.FirmMatrixSelected >= len(a.Model.FirmMatrixTemplates) {
+		return
+	}
+	id := a.Model.FirmMatrixTemplates[a.Model.FirmMatrixSelected]
+	a.closeFirmMatrix()
+	a.selectTemplate(id)
+	a.logInfo("Showing %s", id.Name)
+}
+
+// handleMissingKey filters the Templates section to the templates missing in the current firm,
+// or clears that filter when it is active.
+func (a *App) handleMissingKey() (tea.Model, tea.Cmd) {
+	index := a.firmOptionIndex(a.Model.FirmID, a.Model.FirmType)
+	if index < 0 {
+		a.logWarn("No firm set (select one in the Firm section)")
+		return a, nil
+	}
+
+	option := a.Model.FirmOptions[index]
+	if a.Model.SearchQuery == template.MissingQuery(option) {
+		cursor := a.cursorTemplateID()
+		a.Model.SearchQuery = ""
+		a.relist(cursor)
+		a.logInfo("Showing all templates")
+		return a, nil
+	}
+	a.filterMissingIn(option)
+	return a, nil
+}
+
+// filterMissingIn filters the Templates section to the templates never imported in a firm or
+// partner. The filter is a search query, so it can be refined or cleared with /.
+func (a *App) filterMissingIn(option models.FirmOption) {
+	cursor := a.cursorTemplateID()
+	a.Model.SearchQuery = template.MissingQuery(option)
+	a.relist(cursor)
+	a.Model.CurrentSection = models.TemplatesSection
+	a.logInfo("%d templates missing in %s %s (%s)", len(a.Model.FilteredTemplates), option.Type, option.Name, a.Model.SearchQuery)
+}
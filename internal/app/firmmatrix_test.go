@@ -0,0 +1,109 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/rufex/sftui/internal/models"
+)
+
+func newFirmMatrixTestApp() *App {
+	app := New()
+	m := app.InitialModel()
+	m.Templates = []models.Template{
+		{Name: "rt_both", Category: "reconciliation_texts", Config: map[string]interface{}{
+			"id":         map[string]interface{}{"1001": float64(11), "1002": float64(12)},
+			"partner_id": map[string]interface{}{"25": float64(5)},
+		}},
+		{Name: "rt_demo", Category: "reconciliation_texts", Config: map[string]interface{}{
+			"id": map[string]interface{}{"1001": float64(21)},
+		}},
+		{Name: "at_none", Category: "account_templates", Config: map[string]interface{}{}},
+	}
+	m.FirmOptions = []models.FirmOption{
+		{ID: "1001", Name: "The Demo Firm", Type: "firm"},
+		{ID: "1002", Name: "The Production Firm", Type: "firm"},
+		{ID: "25", Name: "Partner 1", Type: "partner"},
+	}
+	m.FirmID = "1002"
+	m.FirmType = "firm"
+	m.Width = 120
+	m.Height = 30
+	app.Model = m
+	app.applyFilter()
+	return app
+}
+
+func TestFirmMatrix(t *testing.T) {
+	app := newFirmMatrixTestApp()
+
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'M'}})
+	if !app.Model.ShowFirmMatrix {
+		t.Fatalf("Expected 'M' to open the firm matrix")
+	}
+	if app.Model.FirmMatrixColumn != 1 {
+		t.Errorf("Expected the matrix to start on the default firm, got column %d", app.Model.FirmMatrixColumn)
+	}
+
+	view := app.View()
+	for _, expected := range []string{"2 missing in The Production Firm", "firm 1002 *", "partner 25", "[RT] rt_both", "11", "21"} {
+		if !strings.Contains(view, expected) {
+			t.Errorf("Expected the matrix to show %q", expected)
+		}
+	}
+
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'l'}})
+	if !strings.Contains(app.View(), "2 missing in Partner 1") {
+		t.Errorf("Expected the title to count the templates missing in the partner")
+	}
+
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'m'}})
+	if app.Model.ShowFirmMatrix {
+		t.Errorf("Expected 'm' to close the matrix")
+	}
+	if app.Model.SearchQuery != "-partner:25" {
+		t.Errorf("Expected the list to be filtered with -partner:25, got %q", app.Model.SearchQuery)
+	}
+	if len(app.Model.FilteredTemplates) != 2 || strings.Contains(app.View(), "rt_both") {
+		t.Errorf("Expected only the templates missing in the partner, got %v", app.Model.FilteredTemplates)
+	}
+}
+
+func TestFirmMatrixEnterSelectsTemplate(t *testing.T) {
+	app := newFirmMatrixTestApp()
+
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'M'}})
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'G'}})
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if app.Model.ShowFirmMatrix {
+		t.Errorf("Expected Enter to close the matrix")
+	}
+	if name := cursorName(app); name != "rt_demo" {
+		t.Errorf("Expected the last row rt_demo to be selected, got %q", name)
+	}
+}
+
+func TestMissingKeyTogglesFilter(t *testing.T) {
+	app := newFirmMatrixTestApp()
+
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'m'}})
+	if app.Model.SearchQuery != "-firm:1002" || len(app.Model.FilteredTemplates) != 2 {
+		t.Errorf("Expected 'm' to show the 2 templates missing in the default firm, got %q %v", app.Model.SearchQuery, app.Model.FilteredTemplates)
+	}
+	if !strings.Contains(app.View(), "Templates (2/3)") {
+		t.Errorf("Expected the title to show the filtered count")
+	}
+
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'m'}})
+	if app.Model.SearchQuery != "" || len(app.Model.FilteredTemplates) != 3 {
+		t.Errorf("Expected a second 'm' to clear the filter, got %q", app.Model.SearchQuery)
+	}
+
+	app.Model.FirmID = ""
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'m'}})
+	if app.Model.SearchQuery != "" || !strings.Contains(app.Model.Output, "No firm set") {
+		t.Errorf("Expected a warning without a default firm, got %q", app.Model.Output)
+	}
+}
@@ -0,0 +1,83 @@
+package app
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/rufex/sftui/internal/models"
+)
+
+// openGraph builds the dependency graph of every template and shows it full screen.
+func (a *App) openGraph() {
+	graph := a.templateManager.BuildDependencyGraph(a.Model.Templates, a.Model.SharedPartsUsage)
+	a.Model.GraphRows = a.uiRenderer.GraphRows(graph)
+	a.Model.ShowGraph = true
+	a.Model.GraphSelected = 0
+	a.Model.GraphOffset = 0
+	a.logInfo("%s", a.uiRenderer.GraphTitle(a.Model))
+}
+
+func (a *App) closeGraph() {
+	a.Model.ShowGraph = false
+	a.Model.GraphRows = nil
+}
+
+func (a *App) handleGraphKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	pageSize := a.Model.FullScreenContentHeight()
+
+	switch msg.String() {
+	case "ctrl+c":
+		return a, tea.Quit
+	case "esc", "q", "g":
+		a.closeGraph()
+	case "up", "k":
+		a.moveGraphCursor(-1)
+	case "down", "j":
+		a.moveGraphCursor(1)
+	case "pgup", "ctrl+u":
+		a.moveGraphCursor(-pageSize / 2)
+	case "pgdown", "ctrl+d":
+		a.moveGraphCursor(pageSize / 2)
+	case "home":
+		a.moveGraphCursor(-len(a.Model.GraphRows))
+	case "G", "end":
+		a.moveGraphCursor(len(a.Model.GraphRows))
+	case "enter":
+		a.jumpToGraphRow()
+	}
+	return a, nil
+}
+
+func (a *App) moveGraphCursor(delta int) {
+	if len(a.Model.GraphRows) == 0 {
+		return
+	}
+
+	a.Model.GraphSelected = min(max(0, a.Model.GraphSelected+delta), len(a.Model.GraphRows)-1)
+
+	pageSize := a.Model.FullScreenContentHeight()
+	if a.Model.GraphSelected < a.Model.GraphOffset {
+		a.Model.GraphOffset = a.Model.GraphSelected
+	} else if a.Model.GraphSelected >= a.Model.GraphOffset+pageSize {
+		a.Model.GraphOffset = a.Model.GraphSelected - pageSize + 1
+	}
+}
+
+// jumpToGraphRow closes the graph and selects the template or shared part of the highlighted row.
+func (a *App) jumpToGraphRow() {
+	if a.Model.GraphSelected >= len(a.Model.GraphRows) {
+		return
+	}
+
+	row := a.Model.GraphRows[a.Model.GraphSelected]
+	if row.Category == "" {
+		return
+	}
+
+	if candidate, ok := a.templates().Get(models.TemplateID{Category: row.Category, Name: row.Name}); ok {
+		a.closeGraph()
+		a.selectTemplate(candidate.ID())
+		a.logInfo("Showing %s", candidate.Name)
+		return
+	}
+	a.logWarn("Template %s not found", row.Name)
+}
@@ -2,10 +2,15 @@ package app
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 
+	"github.com/rufex/sftui/internal/jobs"
 	"github.com/rufex/sftui/internal/models"
+	"github.com/rufex/sftui/internal/search"
+	"github.com/rufex/sftui/internal/watcher"
 )
 
 func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -14,6 +19,18 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return a.handleWindowSize(msg)
 	case tea.KeyMsg:
 		return a.handleKeyMsg(msg)
+	case jobs.StatusMsg:
+		return a.handleJobStatus(msg)
+	case jobs.DoneMsg:
+		return a.handleJobsDone()
+	case editorFinishedMsg:
+		return a.handleEditorFinished(msg)
+	case watcher.ChangesMsg:
+		return a.handleTemplateChanges(msg)
+	case search.ResultMsg:
+		return a.handleContentSearchResult(msg)
+	case search.DoneMsg:
+		return a.handleContentSearchDone(msg)
 	}
 	return a, nil
 }
@@ -50,14 +67,58 @@ func (a *App) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return a.handleTextPartPopup(msg)
 	}
 
+	if a.Model.ShowConfirmPopup {
+		return a.handleConfirmPopup(msg)
+	}
+
+	if a.Model.ShowNewTemplateWizard {
+		return a.handleNewTemplateWizard(msg)
+	}
+
+	if a.Model.ShowSharedPartPopup {
+		return a.handleSharedPartPopup(msg)
+	}
+
 	if a.Model.ShowInPlaceEdit {
 		return a.handleInPlaceEdit(msg)
 	}
 
+	if a.Model.ShowPreview {
+		return a.handlePreviewKeys(msg)
+	}
+
+	if a.Model.ShowGraph {
+		return a.handleGraphKeys(msg)
+	}
+
+	if a.Model.ShowConsistency {
+		return a.handleConsistencyKeys(msg)
+	}
+
+	if a.Model.ShowProblems {
+		return a.handleProblemsKeys(msg)
+	}
+
+	if a.Model.ShowContentSearch {
+		return a.handleContentSearchKeys(msg)
+	}
+
+	if a.Model.ShowFirmMatrix {
+		return a.handleFirmMatrixKeys(msg)
+	}
+
+	if a.Model.ShowHistory {
+		return a.handleHistoryKeys(msg)
+	}
+
 	if msg.Alt {
 		return a, nil
 	}
 
+	if a.Model.LogSearchMode {
+		return a.handleLogSearchMode(msg)
+	}
+
 	if a.Model.SearchMode {
 		return a.handleSearchMode(msg)
 	}
@@ -70,7 +131,7 @@ func (a *App) handleActionPopup(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "esc":
 		a.Model.ShowActionPopup = false
 		a.Model.SelectedAction = 0
-		a.Model.Output = "Action cancelled"
+		a.logInfo("Action cancelled")
 		return a, nil
 	case "up", "k":
 		a.Model.SelectedAction = (a.Model.SelectedAction - 1 + 4) % 4
@@ -85,24 +146,112 @@ func (a *App) handleActionPopup(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		if selectedActionName == "cancel" {
 			a.Model.ShowActionPopup = false
 			a.Model.SelectedAction = 0
-			a.Model.Output = "Action cancelled"
+			a.logInfo("Action cancelled")
 		} else {
-			selectedCount := len(a.Model.SelectedTemplates)
-			a.Model.Output = fmt.Sprintf("Action '%s' selected for %d templates (not implemented yet)", selectedActionName, selectedCount)
 			a.Model.ShowActionPopup = false
 			a.Model.SelectedAction = 0
+			return a, a.runAction(selectedActionName)
 		}
 		return a, nil
 	}
 	return a, nil
 }
 
+func (a *App) runAction(action string) tea.Cmd {
+	target := a.firm()
+	return a.startJobs(action, a.selectedTemplatesList(), func(template models.Template) (string, error) {
+		result := a.cliRunner.RunTemplate(action, template, target)
+		return result.Output, result.Err
+	})
+}
+
+// startJobs runs one background job per template and shows their progress in the Output section.
+func (a *App) startJobs(action string, templates []models.Template, run func(models.Template) (string, error)) tea.Cmd {
+	if a.Model.JobsRunning {
+		a.logWarn("Another action is still running")
+		return nil
+	}
+
+	a.Model.Jobs = make([]models.Job, len(templates))
+	queuedJobs := make([]jobs.Job, len(templates))
+	for i, template := range templates {
+		a.Model.Jobs[i] = models.Job{
+			ID:       i,
+			Label:    template.Name,
+			Category: template.Category,
+			Status:   models.JobPending,
+		}
+		queuedJobs[i] = jobs.Job{
+			ID: i,
+			Run: func() (string, error) {
+				return run(template)
+			},
+		}
+	}
+
+	a.Model.JobsRunning = true
+	a.Model.JobsAction = action
+	a.logInfo("Running %s for %d templates (Esc to cancel)", action, len(templates))
+
+	a.jobQueue = jobs.NewQueue(jobs.DefaultConcurrency)
+	return a.jobQueue.Start(queuedJobs)
+}
+
+func (a *App) handleJobStatus(msg jobs.StatusMsg) (tea.Model, tea.Cmd) {
+	if msg.JobID >= 0 && msg.JobID < len(a.Model.Jobs) {
+		job := &a.Model.Jobs[msg.JobID]
+		job.Status = msg.Status
+		job.Output = msg.Output
+		if msg.Err != nil {
+			job.Error = msg.Err.Error()
+		}
+		a.logJob(*job)
+	}
+
+	if a.jobQueue == nil {
+		return a, nil
+	}
+	return a, a.jobQueue.Next()
+}
+
+func (a *App) handleJobsDone() (tea.Model, tea.Cmd) {
+	a.Model.JobsRunning = false
+	a.jobQueue = nil
+
+	counts := make(map[models.JobStatus]int)
+	var failed []string
+	for _, job := range a.Model.Jobs {
+		counts[job.Status]++
+		if job.Status == models.JobFailed {
+			failed = append(failed, job.Label)
+		}
+	}
+
+	action := a.Model.JobsAction
+	switch {
+	case counts[models.JobCancelled] > 0:
+		a.logWarn("%s cancelled: %d succeeded, %d failed, %d cancelled", action, counts[models.JobSucceeded], counts[models.JobFailed], counts[models.JobCancelled])
+	case len(failed) > 0:
+		a.logError("%s succeeded for %d/%d templates, failed: %s", action, counts[models.JobSucceeded], len(a.Model.Jobs), strings.Join(failed, ", "))
+	default:
+		a.logInfo("%s succeeded for %d templates", action, counts[models.JobSucceeded])
+	}
+	return a, nil
+}
+
+func (a *App) cancelJobs() {
+	if a.jobQueue != nil {
+		a.jobQueue.Cancel()
+		a.logInfo("Cancelling pending %s jobs...", a.Model.JobsAction)
+	}
+}
+
 func (a *App) handleFirmPopup(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "esc":
 		a.Model.ShowFirmPopup = false
 		a.Model.SelectedFirm = 0
-		a.Model.Output = "Firm selection cancelled"
+		a.logInfo("Firm selection cancelled")
 		return a, nil
 	case "up", "k":
 		if len(a.Model.FirmOptions) > 0 {
@@ -114,16 +263,14 @@ func (a *App) handleFirmPopup(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			a.Model.SelectedFirm = (a.Model.SelectedFirm + 1) % len(a.Model.FirmOptions)
 		}
 		return a, nil
-	case "enter":
+	case "enter", "d":
 		if len(a.Model.FirmOptions) > 0 && a.Model.SelectedFirm < len(a.Model.FirmOptions) {
 			selectedOption := a.Model.FirmOptions[a.Model.SelectedFirm]
-
-			err := a.configManager.SetDefaultFirm(selectedOption.ID)
-			if err != nil {
-				a.Model.Output = fmt.Sprintf("Error setting default firm: %v", err)
+			a.useFirm(selectedOption)
+			if msg.String() == "d" {
+				a.saveDefaultFirm(selectedOption)
 			} else {
-				a.Model.Firm = fmt.Sprintf("%s (%s)", selectedOption.Name, selectedOption.ID)
-				a.Model.Output = fmt.Sprintf("Default firm set to %s", selectedOption.Name)
+				a.logInfo("Using %s %s for this session", selectedOption.Type, selectedOption.Name)
 			}
 
 			a.Model.ShowFirmPopup = false
@@ -139,16 +286,16 @@ func (a *App) handleHostPopup(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "esc":
 		a.Model.ShowHostPopup = false
 		a.Model.HostTextInput.Blur()
-		a.Model.Output = "Host edit cancelled"
+		a.logInfo("Host edit cancelled")
 		return a, nil
 	case "enter":
 		newHost := a.Model.HostTextInput.Value()
 		err := a.configManager.SetHost(newHost)
 		if err != nil {
-			a.Model.Output = fmt.Sprintf("Error setting host: %v", err)
+			a.logError("Error setting host: %v", err)
 		} else {
 			a.Model.Host = newHost
-			a.Model.Output = "Host updated successfully"
+			a.logInfo("Host updated successfully")
 		}
 
 		a.Model.ShowHostPopup = false
@@ -166,7 +313,7 @@ func (a *App) handleReconciliationTypePopup(msg tea.KeyMsg) (tea.Model, tea.Cmd)
 	case "esc":
 		a.Model.ShowReconciliationTypePopup = false
 		a.Model.SelectedReconciliationType = 0
-		a.Model.Output = "Reconciliation type edit cancelled"
+		a.logInfo("Reconciliation type edit cancelled")
 		return a, nil
 	case "up", "k":
 		a.Model.SelectedReconciliationType = (a.Model.SelectedReconciliationType - 1 + 3) % 3
@@ -178,16 +325,13 @@ func (a *App) handleReconciliationTypePopup(msg tea.KeyMsg) (tea.Model, tea.Cmd)
 		reconciliationTypes := []string{"can_be_reconciled_without_data", "reconciliation_not_necessary", "only_reconciled_with_data"}
 		selectedType := reconciliationTypes[a.Model.SelectedReconciliationType]
 
-		if len(a.Model.FilteredTemplates) > 0 && a.Model.SelectedTemplate < len(a.Model.FilteredTemplates) {
-			actualIndex := a.Model.FilteredTemplates[a.Model.SelectedTemplate]
-			template := a.Model.Templates[actualIndex]
-
-			err := a.configManager.UpdateReconciliationType(template.Path, selectedType)
+		if template, ok := a.currentTemplate(); ok {
+			err := a.configManager.UpdateReconciliationType(template, selectedType)
 			if err != nil {
-				a.Model.Output = fmt.Sprintf("Error updating reconciliation type: %v", err)
+				a.logConfigEditError(template, "reconciliation type", err)
 			} else {
-				a.Model.Templates[actualIndex].Config["reconciliation_type"] = selectedType
-				a.Model.Output = fmt.Sprintf("Reconciliation type set to: %s", selectedType)
+				a.reloadTemplate(template.Path, template.Category)
+				a.logInfo("Reconciliation type set to: %s", selectedType)
 			}
 		}
 
@@ -203,7 +347,7 @@ func (a *App) handleTextPartPopup(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "esc":
 		a.Model.ShowTextPartPopup = false
 		a.Model.TextPartEditMode = ""
-		a.Model.Output = "Text part edit cancelled"
+		a.logInfo("Text part edit cancelled")
 		return a, nil
 	case "tab":
 		if a.Model.TextPartEditMode == "name" {
@@ -217,12 +361,14 @@ func (a *App) handleTextPartPopup(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return a, nil
 	case "enter":
-		newName := a.Model.TextPartNameInput.Value()
-		newPath := a.Model.TextPartPathInput.Value()
+		newName := strings.TrimSpace(a.Model.TextPartNameInput.Value())
+		newPath := strings.TrimSpace(a.Model.TextPartPathInput.Value())
 
 		a.Model.ShowTextPartPopup = false
 		a.Model.TextPartEditMode = ""
-		a.Model.Output = fmt.Sprintf("Text part updated: %s -> %s", newName, newPath)
+		a.Model.TextPartNameInput.Blur()
+		a.Model.TextPartPathInput.Blur()
+		a.submitTextPartPopup(newName, newPath)
 		return a, nil
 	default:
 		var cmd tea.Cmd
@@ -238,47 +384,40 @@ func (a *App) handleTextPartPopup(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 func (a *App) handleInPlaceEdit(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "esc":
-		if len(a.Model.FilteredTemplates) > 0 && a.Model.SelectedTemplate < len(a.Model.FilteredTemplates) {
-			actualIndex := a.Model.FilteredTemplates[a.Model.SelectedTemplate]
-			a.Model.Templates[actualIndex].Config[a.Model.InPlaceEditField] = a.Model.InPlaceEditOriginalValue
+		if template, ok := a.currentTemplate(); ok {
+			template.Config[a.Model.InPlaceEditField] = a.Model.InPlaceEditOriginalValue
 		}
 		a.Model.ShowInPlaceEdit = false
 		a.Model.InPlaceEditField = ""
 		a.Model.InPlaceEditOptions = nil
 		a.Model.InPlaceEditOriginalValue = nil
 		a.Model.InPlaceEditSelectedIndex = 0
-		a.Model.Output = "Edit cancelled"
+		a.logInfo("Edit cancelled")
 		return a, nil
 	case "up", "k", "left", "h":
 		if len(a.Model.InPlaceEditOptions) > 0 {
 			a.Model.InPlaceEditSelectedIndex = (a.Model.InPlaceEditSelectedIndex - 1 + len(a.Model.InPlaceEditOptions)) % len(a.Model.InPlaceEditOptions)
 			selectedValue := a.Model.InPlaceEditOptions[a.Model.InPlaceEditSelectedIndex]
-			a.Model.Output = fmt.Sprintf("%s: %s (↑/↓ to change, Enter to save, Esc to cancel)", a.Model.InPlaceEditField, selectedValue)
+			a.logInfo("%s: %s (↑/↓ to change, Enter to save, Esc to cancel)", a.Model.InPlaceEditField, selectedValue)
 		}
 		return a, nil
 	case "down", "j", "right", "l":
 		if len(a.Model.InPlaceEditOptions) > 0 {
 			a.Model.InPlaceEditSelectedIndex = (a.Model.InPlaceEditSelectedIndex + 1) % len(a.Model.InPlaceEditOptions)
 			selectedValue := a.Model.InPlaceEditOptions[a.Model.InPlaceEditSelectedIndex]
-			a.Model.Output = fmt.Sprintf("%s: %s (↑/↓ to change, Enter to save, Esc to cancel)", a.Model.InPlaceEditField, selectedValue)
+			a.logInfo("%s: %s (↑/↓ to change, Enter to save, Esc to cancel)", a.Model.InPlaceEditField, selectedValue)
 		}
 		return a, nil
 	case "enter":
-		if len(a.Model.FilteredTemplates) > 0 && a.Model.SelectedTemplate < len(a.Model.FilteredTemplates) && len(a.Model.InPlaceEditOptions) > 0 {
-			actualIndex := a.Model.FilteredTemplates[a.Model.SelectedTemplate]
-			template := a.Model.Templates[actualIndex]
+		if template, ok := a.currentTemplate(); ok && len(a.Model.InPlaceEditOptions) > 0 {
 			newValue := a.Model.InPlaceEditOptions[a.Model.InPlaceEditSelectedIndex]
 
-			err := a.updateConfigField(template.Path, a.Model.InPlaceEditField, newValue)
+			err := a.updateConfigField(template, a.Model.InPlaceEditField, newValue)
 			if err != nil {
-				a.Model.Output = fmt.Sprintf("Error updating %s: %v", a.Model.InPlaceEditField, err)
+				a.logConfigEditError(template, a.Model.InPlaceEditField, err)
 			} else {
-				if a.Model.InPlaceEditField == "reconciliation_type" || a.Model.InPlaceEditField == "encoding" {
-					a.Model.Templates[actualIndex].Config[a.Model.InPlaceEditField] = newValue
-				} else {
-					a.Model.Templates[actualIndex].Config[a.Model.InPlaceEditField] = newValue == "true"
-				}
-				a.Model.Output = fmt.Sprintf("%s updated to: %s", a.Model.InPlaceEditField, newValue)
+				a.reloadTemplate(template.Path, template.Category)
+				a.logInfo("%s updated to: %s", a.Model.InPlaceEditField, newValue)
 			}
 		}
 
@@ -297,23 +436,23 @@ func (a *App) handleSearchMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "esc":
 		a.Model.SearchMode = false
 		a.Model.SearchQuery = ""
-		a.Model.FilteredTemplates = a.templateManager.FilterTemplates(a.Model.Templates, a.Model.SearchQuery)
+		a.applyFilter()
 		a.Model.SelectedTemplate = 0
 		a.Model.TemplatesOffset = 0
-		a.Model.Output = "Search cancelled"
+		a.logInfo("Search cancelled")
 		return a, nil
 	case "enter":
 		a.Model.SearchMode = false
 		if len(a.Model.FilteredTemplates) > 0 {
-			a.Model.Output = fmt.Sprintf("Found %d templates", len(a.Model.FilteredTemplates))
+			a.logInfo("Found %d templates", len(a.Model.FilteredTemplates))
 		} else {
-			a.Model.Output = "No templates found"
+			a.logInfo("No templates found")
 		}
 		return a, nil
 	case "backspace":
 		if len(a.Model.SearchQuery) > 0 {
 			a.Model.SearchQuery = a.Model.SearchQuery[:len(a.Model.SearchQuery)-1]
-			a.Model.FilteredTemplates = a.templateManager.FilterTemplates(a.Model.Templates, a.Model.SearchQuery)
+			a.applyFilter()
 			a.Model.SelectedTemplate = 0
 			a.Model.TemplatesOffset = 0
 		}
@@ -323,7 +462,7 @@ func (a *App) handleSearchMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			char := msg.Runes[0]
 			if char >= 32 && char < 127 {
 				a.Model.SearchQuery += string(char)
-				a.Model.FilteredTemplates = a.templateManager.FilterTemplates(a.Model.Templates, a.Model.SearchQuery)
+				a.applyFilter()
 				a.Model.SelectedTemplate = 0
 				a.Model.TemplatesOffset = 0
 			}
@@ -332,10 +471,48 @@ func (a *App) handleSearchMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	}
 }
 
+func (a *App) handleLogSearchMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		a.Model.LogSearchMode = false
+		a.Model.LogSearchQuery = ""
+		a.Model.LogOffset = 0
+	case "enter":
+		a.Model.LogSearchMode = false
+	case "backspace":
+		if len(a.Model.LogSearchQuery) > 0 {
+			a.Model.LogSearchQuery = a.Model.LogSearchQuery[:len(a.Model.LogSearchQuery)-1]
+			a.Model.LogOffset = 0
+		}
+	default:
+		if msg.Type == tea.KeyRunes && len(msg.Runes) == 1 {
+			char := msg.Runes[0]
+			if char >= 32 && char < 127 {
+				a.Model.LogSearchQuery += string(char)
+				a.Model.LogOffset = 0
+			}
+		}
+	}
+	return a, nil
+}
+
 func (a *App) handleMainKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "ctrl+c", "q":
 		return a, tea.Quit
+	case "esc":
+		if a.Model.LogExpanded {
+			a.Model.LogExpanded = false
+		} else if a.Model.JobsRunning {
+			a.cancelJobs()
+		}
+		return a, nil
+	case "f":
+		if a.Model.LogExpanded || a.Model.CurrentSection == models.OutputSection {
+			a.Model.LogExpanded = !a.Model.LogExpanded
+			a.Model.CurrentSection = models.OutputSection
+		}
+		return a, nil
 	case "?":
 		a.Model.ShowHelp = true
 		return a, nil
@@ -362,6 +539,8 @@ func (a *App) handleMainKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			a.navHandler.HandleTemplateNavigation(a.Model, "up")
 		} else if a.Model.CurrentSection == models.DetailsSection {
 			a.navHandler.HandleDetailsNavigation(a.Model, "up")
+		} else if a.Model.CurrentSection == models.OutputSection {
+			a.navHandler.HandleOutputNavigation(a.Model, "up")
 		}
 		return a, nil
 	case "down", "j":
@@ -369,6 +548,8 @@ func (a *App) handleMainKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			a.navHandler.HandleTemplateNavigation(a.Model, "down")
 		} else if a.Model.CurrentSection == models.DetailsSection {
 			a.navHandler.HandleDetailsNavigation(a.Model, "down")
+		} else if a.Model.CurrentSection == models.OutputSection {
+			a.navHandler.HandleOutputNavigation(a.Model, "down")
 		}
 		return a, nil
 	case "left", "h", "right", "l":
@@ -381,46 +562,119 @@ func (a *App) handleMainKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return a.handleBackspaceKey()
 	case "enter":
 		return a.handleEnterKey()
+	case "r":
+		return a.handleRenameKey()
+	case "a":
+		return a.handleAddTextPartKey()
+	case "n":
+		if a.Model.CurrentSection == models.TemplatesSection {
+			return a.openNewTemplateWizard()
+		}
+	case "g":
+		a.openGraph()
+		return a, nil
+	case "C":
+		a.openConsistency()
+		return a, nil
+	case "P":
+		a.openProblems()
+		return a, nil
+	case "F":
+		a.openContentSearch()
+		return a, nil
+	case "M":
+		a.openFirmMatrix()
+		return a, nil
+	case "m":
+		return a.handleMissingKey()
+	case "R":
+		return a.handleRefreshKey()
+	case "u":
+		return a.handleUndoKey()
+	case "ctrl+r":
+		return a.handleRedoKey()
+	case "U":
+		a.openHistory()
+		return a, nil
+	case "o":
+		return a.handleSortKey()
+	case "G":
+		return a.handleGroupKey()
+	case "z":
+		if a.Model.CurrentSection == models.TemplatesSection {
+			return a.handleCollapseKey()
+		}
+	case "Z":
+		if a.Model.CurrentSection == models.TemplatesSection {
+			return a.handleCollapseAllKey()
+		}
+	case "s":
+		if a.Model.CurrentSection == models.TemplatesSection || a.Model.CurrentSection == models.DetailsSection {
+			return a.openSharedPartPopup()
+		}
+	case "c":
+		return a.handleDuplicateTextPartKey()
+	case "d":
+		return a.handleDeleteTextPartKey()
+	case "[":
+		return a.handleMoveTextPartKey(-1)
+	case "]":
+		return a.handleMoveTextPartKey(1)
+	case "e":
+		if a.Model.CurrentSection == models.TemplatesSection || a.Model.CurrentSection == models.DetailsSection {
+			return a.handleEditKey(false)
+		}
+	case "E":
+		if a.Model.CurrentSection == models.TemplatesSection || a.Model.CurrentSection == models.DetailsSection {
+			return a.handleEditKey(true)
+		}
 	}
 	return a, nil
 }
 
 func (a *App) handleSpaceKey() (tea.Model, tea.Cmd) {
-	if a.Model.CurrentSection == models.TemplatesSection && len(a.Model.FilteredTemplates) > 0 && a.Model.SelectedTemplate < len(a.Model.FilteredTemplates) {
-		actualIndex := a.Model.FilteredTemplates[a.Model.SelectedTemplate]
+	if a.Model.CurrentSection == models.TemplatesSection && a.Model.SelectedTemplate < len(a.Model.FilteredTemplates) {
+		id := a.Model.FilteredTemplates[a.Model.SelectedTemplate]
 
-		if a.Model.SelectedTemplates[actualIndex] {
-			delete(a.Model.SelectedTemplates, actualIndex)
+		if a.Model.SelectedTemplates[id] {
+			delete(a.Model.SelectedTemplates, id)
 		} else {
-			a.Model.SelectedTemplates[actualIndex] = true
+			a.Model.SelectedTemplates[id] = true
 		}
 
 		selectedCount := len(a.Model.SelectedTemplates)
 		switch selectedCount {
 		case 0:
-			a.Model.Output = "No templates selected"
+			a.logInfo("No templates selected")
 		case 1:
-			a.Model.Output = "1 template selected"
+			a.logInfo("1 template selected")
 		default:
-			a.Model.Output = fmt.Sprintf("%d templates selected", selectedCount)
+			a.logInfo("%d templates selected", selectedCount)
 		}
 	}
 	return a, nil
 }
 
 func (a *App) handleSearchKey() (tea.Model, tea.Cmd) {
+	if a.Model.CurrentSection == models.OutputSection {
+		a.Model.LogSearchMode = true
+		a.Model.LogSearchQuery = ""
+		a.Model.LogOffset = 0
+		return a, nil
+	}
+
 	if a.Model.CurrentSection == models.TemplatesSection {
 		a.Model.SearchMode = true
 		a.Model.SearchQuery = ""
-		a.Model.Output = "Search mode - type to filter templates"
+		a.logInfo("Search mode - type to filter templates")
 	}
 	return a, nil
 }
 
 func (a *App) handleBackspaceKey() (tea.Model, tea.Cmd) {
 	if a.Model.CurrentSection == models.TemplatesSection && len(a.Model.SelectedTemplates) > 0 {
-		a.Model.SelectedTemplates = make(map[int]bool)
-		a.Model.Output = "All templates deselected"
+		a.Model.SelectedTemplates = make(map[models.TemplateID]bool)
+		a.logInfo("All templates deselected")
 	}
 	return a, nil
 }
@@ -429,14 +683,14 @@ func (a *App) handleEnterKey() (tea.Model, tea.Cmd) {
 	switch a.Model.CurrentSection {
 	case models.FirmSection:
 		a.Model.ShowFirmPopup = true
-		a.Model.SelectedFirm = 0
-		a.Model.Output = "Select a firm or partner"
+		a.Model.SelectedFirm = max(0, a.firmOptionIndex(a.Model.FirmID, a.Model.FirmType))
+		a.logInfo("Select a firm or partner")
 	case models.HostSection:
 		a.Model.ShowHostPopup = true
 		a.Model.HostTextInput.SetValue(a.Model.Host)
 		a.Model.HostTextInput.Focus()
 		a.Model.HostTextInput.CursorEnd()
-		a.Model.Output = "Edit host URL"
+		a.logInfo("Edit host URL")
 	case models.DetailsSection:
 		return a.handleDetailsEnter()
 	case models.TemplatesSection:
@@ -445,9 +699,9 @@ func (a *App) handleEnterKey() (tea.Model, tea.Cmd) {
 			a.Model.SelectedAction = 0
 			selectedCount := len(a.Model.SelectedTemplates)
 			if selectedCount == 1 {
-				a.Model.Output = "1 template selected - choose action"
+				a.logInfo("1 template selected - choose action")
 			} else {
-				a.Model.Output = fmt.Sprintf("%d templates selected - choose action", selectedCount)
+				a.logInfo("%d templates selected - choose action", selectedCount)
 			}
 		}
 	}
@@ -455,10 +709,7 @@ func (a *App) handleEnterKey() (tea.Model, tea.Cmd) {
 }
 
 func (a *App) handleDetailsEnter() (tea.Model, tea.Cmd) {
-	if len(a.Model.FilteredTemplates) > 0 && a.Model.SelectedTemplate < len(a.Model.FilteredTemplates) {
-		actualIndex := a.Model.FilteredTemplates[a.Model.SelectedTemplate]
-		template := a.Model.Templates[actualIndex]
-
+	if template, ok := a.currentTemplate(); ok {
 		configFieldCount := a.GetConfigFieldCount(template)
 
 		if a.Model.SelectedDetailField < configFieldCount {
@@ -481,52 +732,78 @@ func (a *App) handleDetailsEnter() (tea.Model, tea.Cmd) {
 				a.Model.InPlaceEditOptions = options
 				a.Model.InPlaceEditOriginalValue = currentValue
 				a.Model.InPlaceEditSelectedIndex = currentIndex
-				a.Model.Output = fmt.Sprintf("Select %s value (↑/↓ to change, Enter to save, Esc to cancel)", selectedConfigField)
+				a.logInfo("Select %s value (↑/↓ to change, Enter to save, Esc to cancel)", selectedConfigField)
 			}
-		} else if template.Category != "shared_parts" {
-			return a.handleTextPartEdit(template, configFieldCount)
+		} else if path, title := a.detailLiquidFile(template, configFieldCount); path != "" {
+			a.openPreview(path, title)
+		} else if template.Category == "shared_parts" {
+			a.jumpToSharedPartUser(template, a.Model.SelectedDetailField-configFieldCount-1)
 		}
 	}
 	return a, nil
 }
 
-func (a *App) handleTextPartEdit(template models.Template, configFieldCount int) (tea.Model, tea.Cmd) {
-	if textPartsInterface, exists := template.Config["text_parts"]; exists {
-		if textParts, ok := textPartsInterface.(map[string]interface{}); ok {
-			textPartIndex := a.Model.SelectedDetailField - configFieldCount
+// handleRenameKey opens the text part popup for the text part highlighted in the Details section.
+func (a *App) handleRenameKey() (tea.Model, tea.Cmd) {
+	if a.Model.CurrentSection != models.DetailsSection {
+		return a, nil
+	}
 
-			type textPart struct {
-				name string
-				path string
-			}
-			var partsList []textPart
-			for name, pathInterface := range textParts {
-				if pathStr, ok := pathInterface.(string); ok {
-					partsList = append(partsList, textPart{name: name, path: pathStr})
-				}
-			}
+	if template, ok := a.currentTemplate(); ok {
+		if template.Category != "shared_parts" {
+			return a.handleTextPartEdit(template, a.GetConfigFieldCount(template))
+		}
+	}
+	return a, nil
+}
 
-			for i := 0; i < len(partsList); i++ {
-				for j := i + 1; j < len(partsList); j++ {
-					if partsList[i].name > partsList[j].name {
-						partsList[i], partsList[j] = partsList[j], partsList[i]
-					}
-				}
-			}
+// detailLiquidFile returns the Liquid file behind the highlighted Details field: the main file,
+// a text part or a shared part used by the template. It returns an empty path for other fields.
+func (a *App) detailLiquidFile(template models.Template, configFieldCount int) (string, string) {
+	fileIndex := a.Model.SelectedDetailField - configFieldCount
+	if fileIndex == 0 {
+		return a.templateManager.GetMainLiquidPath(template), fmt.Sprintf("%s/%s", template.Name, a.templateManager.GetMainLiquidFile(template))
+	}
 
-			if textPartIndex < len(partsList) {
-				selectedPart := partsList[textPartIndex]
-				a.Model.ShowTextPartPopup = true
-				a.Model.SelectedTextPart = textPartIndex
+	if template.Category == "shared_parts" {
+		return "", ""
+	}
 
-				a.Model.TextPartNameInput.SetValue(selectedPart.name)
-				a.Model.TextPartPathInput.SetValue(selectedPart.path)
-				a.Model.TextPartNameInput.Focus()
-				a.Model.TextPartEditMode = "name"
+	partsList := a.templateManager.GetTextParts(template)
+	textPartIndex := fileIndex - 1
+	if textPartIndex < len(partsList) {
+		part := partsList[textPartIndex]
+		return a.templateManager.GetTextPartPath(template, part), fmt.Sprintf("%s/%s", template.Name, part.Path)
+	}
 
-				a.Model.Output = "Edit text part name and path"
-			}
+	sharedParts := append([]string(nil), a.Model.SharedPartsUsage[template.ID()]...)
+	sort.Strings(sharedParts)
+	sharedPartIndex := textPartIndex - len(partsList)
+	if sharedPartIndex < len(sharedParts) {
+		if candidate, ok := a.templates().Get(models.TemplateID{Category: "shared_parts", Name: sharedParts[sharedPartIndex]}); ok {
+			return a.templateManager.GetMainLiquidPath(candidate), fmt.Sprintf("%s/%s", candidate.Name, a.templateManager.GetMainLiquidFile(candidate))
 		}
 	}
+
+	return "", ""
+}
+
+func (a *App) handleTextPartEdit(template models.Template, configFieldCount int) (tea.Model, tea.Cmd) {
+	textPartIndex := a.Model.SelectedDetailField - configFieldCount - 1
+	partsList := a.templateManager.GetTextParts(template)
+
+	if textPartIndex >= 0 && textPartIndex < len(partsList) {
+		selectedPart := partsList[textPartIndex]
+		a.Model.ShowTextPartPopup = true
+		a.Model.SelectedTextPart = textPartIndex
+
+		a.Model.TextPartNameInput.SetValue(selectedPart.Name)
+		a.Model.TextPartPathInput.SetValue(selectedPart.Path)
+		a.Model.TextPartNameInput.Focus()
+		a.Model.TextPartEditMode = "name"
+		a.Model.TextPartPopupAction = "rename"
+
+		a.logInfo("Edit text part name and path")
+	}
 	return a, nil
 }
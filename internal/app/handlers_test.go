@@ -1,10 +1,14 @@
 package app
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rufex/sftui/internal/cli"
 	"github.com/rufex/sftui/internal/models"
 )
 
@@ -101,19 +105,44 @@ func TestFirmPopupEscape(t *testing.T) {
 	}
 }
 
+// useSilverfinConfig points the config manager at a Silverfin config in a temporary home, with
+// 1001 as default firm of the market-repo working directory, and returns the config path.
+func useSilverfinConfig(t *testing.T) string {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	if err := os.MkdirAll(filepath.Join(home, ".silverfin"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	config := `{"defaultFirmIDs": {"market-repo": "1001"}, "1001": {"firmName": "Demo"}, "1002": {"firmName": "Production"}}`
+	configPath := filepath.Join(home, ".silverfin", "config.json")
+	if err := os.WriteFile(configPath, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	repo := filepath.Join(t.TempDir(), "market-repo")
+	if err := os.Mkdir(repo, 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(repo)
+	return configPath
+}
+
 func TestFirmPopupSelection(t *testing.T) {
+	configPath := useSilverfinConfig(t)
+	configBefore, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
 	app := New()
 	m := app.InitialModel()
-
-	// Ensure we have firm options loaded
-	if len(m.FirmOptions) == 0 {
-		t.Skip("No firm options available for testing")
+	if m.FirmID != "1001" || m.DefaultFirmID != "1001" {
+		t.Fatalf("Expected the repository default 1001, got %q/%q", m.FirmID, m.DefaultFirmID)
 	}
 
 	// Set popup state
 	m.ShowFirmPopup = true
-	m.SelectedFirm = 0
-	selectedOption := m.FirmOptions[0]
+	m.SelectedFirm = 1
+	selectedOption := m.FirmOptions[1]
 
 	// Simulate Enter key for selection
 	key := tea.KeyMsg{Type: tea.KeyEnter}
@@ -129,12 +158,71 @@ func TestFirmPopupSelection(t *testing.T) {
 		t.Errorf("Expected SelectedFirm to be reset to 0, got %d", app.Model.SelectedFirm)
 	}
 
-	if !strings.Contains(app.Model.Firm, selectedOption.Name) {
+	if !strings.Contains(app.Model.Firm, selectedOption.Name) || app.Model.FirmID != selectedOption.ID {
 		t.Errorf("Expected firm to contain %s, got %s", selectedOption.Name, app.Model.Firm)
 	}
 
-	if !strings.Contains(app.Model.Output, "Default firm set to") {
-		t.Errorf("Expected success message in output, got %s", app.Model.Output)
+	if !strings.Contains(app.Model.Output, "for this session") {
+		t.Errorf("Expected session message in output, got %s", app.Model.Output)
+	}
+
+	// Switching firms for the session must not touch the Silverfin config
+	configAfter, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(configAfter) != string(configBefore) || app.Model.DefaultFirmID != "1001" {
+		t.Errorf("Expected the default firm to stay unchanged")
+	}
+	if !strings.Contains(app.uiRenderer.FirmView(app.Model), "this session only") {
+		t.Errorf("Expected the Firm section to mark a firm other than the default")
+	}
+}
+
+func TestFirmPopupSaveDefault(t *testing.T) {
+	configPath := useSilverfinConfig(t)
+
+	app := New()
+	app.InitialModel()
+
+	app.Model.CurrentSection = models.FirmSection
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if app.Model.SelectedFirm != 0 {
+		t.Errorf("Expected the popup to open on the current firm, got %d", app.Model.SelectedFirm)
+	}
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyDown})
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}})
+
+	if app.Model.FirmID != "1002" || app.Model.DefaultFirmID != "1002" {
+		t.Errorf("Expected 'd' to use and save 1002, got %q/%q", app.Model.FirmID, app.Model.DefaultFirmID)
+	}
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"market-repo": "1002"`) {
+		t.Errorf("Expected defaultFirmIDs to be saved, got %s", data)
+	}
+}
+
+func TestSetFirm(t *testing.T) {
+	app := New()
+	app.Model.FirmOptions = []models.FirmOption{
+		{ID: "1001", Name: "Demo", Type: "firm"},
+		{ID: "25", Name: "Partner 1", Type: "partner"},
+	}
+
+	if err := app.SetFirm("1001"); err != nil || app.Model.FirmID != "1001" || app.Model.FirmType != "firm" {
+		t.Errorf("Expected SetFirm to use firm 1001, got %q, %v", app.Model.Firm, err)
+	}
+	if err := app.SetPartner("25"); err != nil || app.Model.FirmID != "25" || app.Model.FirmType != "partner" || app.Model.Firm != "Partner 1 (25)" {
+		t.Errorf("Expected SetPartner to use partner 25, got %q, %v", app.Model.Firm, err)
+	}
+	if err := app.SetFirm("25"); err == nil || app.Model.FirmType != "partner" {
+		t.Errorf("Expected a partner ID to be rejected as firm, got %v", err)
+	}
+	if err := app.SetFirm("9999"); err == nil || app.Model.FirmID != "25" {
+		t.Errorf("Expected an unknown firm to be rejected, got %v", err)
 	}
 }
 
@@ -370,7 +458,7 @@ func TestReconciliationTypePopupTrigger(t *testing.T) {
 
 	// Setup for testing Enter key in Details section
 	m.CurrentSection = models.DetailsSection
-	m.FilteredTemplates = []int{reconciliationIndex}
+	m.FilteredTemplates = []models.TemplateID{m.Templates[reconciliationIndex].ID()}
 	m.SelectedTemplate = 0
 	m.SelectedDetailField = 0
 
@@ -470,7 +558,7 @@ func TestInPlaceEditingCancelRestore(t *testing.T) {
 	}
 
 	model.Templates = []models.Template{template}
-	model.FilteredTemplates = []int{0}
+	model.FilteredTemplates = []models.TemplateID{template.ID()}
 	model.SelectedTemplate = 0
 	model.CurrentSection = models.DetailsSection
 	model.SelectedDetailField = 0
@@ -492,8 +580,8 @@ func TestInPlaceEditingCancelRestore(t *testing.T) {
 
 	// Simulate Escape key to cancel
 	// This mimics the escape handling logic from handlers.go
-	actualIndex := model.FilteredTemplates[model.SelectedTemplate]
-	model.Templates[actualIndex].Config[model.InPlaceEditField] = model.InPlaceEditOriginalValue
+	current, _ := model.CurrentTemplate()
+	current.Config[model.InPlaceEditField] = model.InPlaceEditOriginalValue
 	model.ShowInPlaceEdit = false
 	model.InPlaceEditField = ""
 	model.InPlaceEditOptions = nil
@@ -511,6 +599,70 @@ func TestInPlaceEditingCancelRestore(t *testing.T) {
 	}
 }
 
+func TestInPlaceEditSavesOnlyUnchangedConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		external string // written to config.json while the edit is open, "" for none
+		expected string // config.json afterwards
+		public   bool   // public of the loaded template afterwards
+	}{
+		{
+			name:     "unchanged config is saved",
+			expected: "{\n  \"public\": true\n}\n",
+			public:   true,
+		},
+		{
+			name:     "config changed on disk is kept",
+			external: "{\n  \"public\": false,\n  \"hide_code\": true\n}\n",
+			expected: "{\n  \"public\": false,\n  \"hide_code\": true\n}\n",
+			public:   false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			useSilverfinConfig(t)
+			templateDir := filepath.Join("reconciliation_texts", "rt_1")
+			if err := os.MkdirAll(templateDir, 0755); err != nil {
+				t.Fatal(err)
+			}
+			configPath := filepath.Join(templateDir, "config.json")
+			if err := os.WriteFile(configPath, []byte("{\n  \"public\": false\n}\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			app := New()
+			m := app.InitialModel()
+			m.CurrentSection = models.DetailsSection
+			m.SelectedDetailField = 0
+
+			_, _ = app.Update(tea.KeyMsg{Type: tea.KeyEnter})
+			if !m.ShowInPlaceEdit || m.InPlaceEditField != "public" {
+				t.Fatalf("Expected Enter to edit public, got %v/%q", m.ShowInPlaceEdit, m.InPlaceEditField)
+			}
+			for m.InPlaceEditOptions[m.InPlaceEditSelectedIndex] != "true" {
+				_, _ = app.Update(tea.KeyMsg{Type: tea.KeyDown})
+			}
+			if test.external != "" {
+				if err := os.WriteFile(configPath, []byte(test.external), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			_, _ = app.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+			if data, _ := os.ReadFile(configPath); string(data) != test.expected {
+				t.Errorf("config.json = %q, want %q", data, test.expected)
+			}
+			if public := app.Model.Templates[0].Config["public"]; public != test.public {
+				t.Errorf("Expected the loaded template to have public %v, got %v", test.public, public)
+			}
+			if test.external != "" && !strings.Contains(app.Model.Output, "changed on disk") {
+				t.Errorf("Expected the conflict to be reported, got %q", app.Model.Output)
+			}
+		})
+	}
+}
+
 func TestSpaceKeyTemplateSelection(t *testing.T) {
 	app := New()
 	m := app.InitialModel()
@@ -530,9 +682,9 @@ func TestSpaceKeyTemplateSelection(t *testing.T) {
 	_, _ = app.Update(key)
 
 	// Check that template was selected
-	actualIndex := m.FilteredTemplates[0]
-	if !app.Model.SelectedTemplates[actualIndex] {
-		t.Errorf("Expected template at index %d to be selected", actualIndex)
+	id := m.FilteredTemplates[0]
+	if !app.Model.SelectedTemplates[id] {
+		t.Errorf("Expected template %s to be selected", id)
 	}
 
 	if !strings.Contains(app.Model.Output, "1 template selected") {
@@ -572,8 +724,8 @@ func TestBackspaceDeselectAll(t *testing.T) {
 
 	// Set current section to Templates and select some templates
 	m.CurrentSection = models.TemplatesSection
-	m.SelectedTemplates[0] = true
-	m.SelectedTemplates[1] = true
+	m.SelectedTemplates[m.FilteredTemplates[0]] = true
+	m.SelectedTemplates[m.FilteredTemplates[1]] = true
 
 	// Simulate backspace key press
 	key := tea.KeyMsg{Type: tea.KeyBackspace}
@@ -589,3 +741,309 @@ func TestBackspaceDeselectAll(t *testing.T) {
 		t.Errorf("Expected deselect message, got: %s", app.Model.Output)
 	}
 }
+
+func TestActionPopupRunsCLI(t *testing.T) {
+	app := New()
+	m := app.InitialModel()
+
+	logPath := filepath.Join(t.TempDir(), "calls.log")
+	script := filepath.Join(t.TempDir(), "silverfin")
+	body := "#!/bin/sh\necho \"$@\" >> " + logPath + "\n"
+	if err := os.WriteFile(script, []byte(body), 0755); err != nil {
+		t.Fatalf("Failed to write fake CLI: %v", err)
+	}
+	app.cliRunner = &cli.Runner{Command: script}
+
+	m.Templates = []models.Template{
+		{Name: "account_1", Category: "account_templates"},
+		{Name: "rt_dir", Category: "reconciliation_texts", Config: map[string]interface{}{"handle": "rt_1"}},
+	}
+	m.FilteredTemplates = []models.TemplateID{m.Templates[0].ID(), m.Templates[1].ID()}
+	m.SelectedTemplates = map[models.TemplateID]bool{m.Templates[0].ID(): true, m.Templates[1].ID(): true}
+	m.FirmID = "1001"
+	m.ShowActionPopup = true
+	m.SelectedAction = 2 // update
+
+	app.Model = m
+	_, cmd := app.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	if app.Model.ShowActionPopup {
+		t.Errorf("Expected action popup to close after running action")
+	}
+
+	if !app.Model.JobsRunning || len(app.Model.Jobs) != 2 {
+		t.Fatalf("Expected 2 running jobs, got running=%v jobs=%d", app.Model.JobsRunning, len(app.Model.Jobs))
+	}
+
+	runCommands(app, cmd)
+
+	if app.Model.JobsRunning {
+		t.Errorf("Expected jobs to be finished")
+	}
+
+	for _, job := range app.Model.Jobs {
+		if job.Status != models.JobSucceeded {
+			t.Errorf("Expected job %s to succeed, got %s", job.Label, job.Status)
+		}
+	}
+
+	if !strings.Contains(app.Model.Output, "update succeeded for 2 templates") {
+		t.Errorf("Expected success message, got: %s", app.Model.Output)
+	}
+
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("Expected fake CLI to be invoked: %v", err)
+	}
+	calls := string(data)
+	if !strings.Contains(calls, "update-account-template --name account_1 --firm 1001") {
+		t.Errorf("Expected account template update call, got: %s", calls)
+	}
+	if !strings.Contains(calls, "update-reconciliation --handle rt_1 --firm 1001") {
+		t.Errorf("Expected reconciliation update call, got: %s", calls)
+	}
+}
+
+func TestActionPopupRunsCLIInPartnerMode(t *testing.T) {
+	app := New()
+	m := app.InitialModel()
+
+	logPath := filepath.Join(t.TempDir(), "calls.log")
+	script := filepath.Join(t.TempDir(), "silverfin")
+	body := "#!/bin/sh\necho \"$@\" >> " + logPath + "\n"
+	if err := os.WriteFile(script, []byte(body), 0755); err != nil {
+		t.Fatalf("Failed to write fake CLI: %v", err)
+	}
+	app.cliRunner = &cli.Runner{Command: script}
+
+	m.Templates = []models.Template{
+		{Name: "rt_dir", Category: "reconciliation_texts", Config: map[string]interface{}{"handle": "rt_1"}},
+		{Name: "export_1", Category: "export_files"},
+	}
+	m.FilteredTemplates = []models.TemplateID{m.Templates[0].ID(), m.Templates[1].ID()}
+	m.SelectedTemplates = map[models.TemplateID]bool{m.Templates[0].ID(): true, m.Templates[1].ID(): true}
+	m.FirmOptions = []models.FirmOption{
+		{ID: "25", Name: "Firm 25", Type: "firm"},
+		{ID: "25", Name: "Partner 1", Type: "partner"},
+	}
+	m.Width = 120
+	m.Height = 30
+
+	app.Model = m
+	if err := app.SetPartner("25"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(app.View(), "Partner 1 (25) - partner mode") {
+		t.Errorf("Expected the header to show the partner")
+	}
+	app.Model.ShowActionPopup = true
+	app.Model.SelectedAction = 2 // update
+	_, cmd := app.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	runCommands(app, cmd)
+
+	if app.Model.Jobs[0].Status != models.JobSucceeded || app.Model.Jobs[1].Status != models.JobFailed {
+		t.Errorf("Expected the reconciliation to succeed and the export file to fail, got %v", app.Model.Jobs)
+	}
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("Expected fake CLI to be invoked: %v", err)
+	}
+	if calls := string(data); calls != "update-reconciliation --handle rt_1 --partner 25\n" {
+		t.Errorf("Expected only a partner-scoped reconciliation update, got: %s", calls)
+	}
+}
+
+func TestActionPopupWithoutFirm(t *testing.T) {
+	app := New()
+	m := app.InitialModel()
+
+	m.Templates = []models.Template{{Name: "account_1", Category: "account_templates"}}
+	m.FilteredTemplates = []models.TemplateID{m.Templates[0].ID()}
+	m.SelectedTemplates = map[models.TemplateID]bool{m.Templates[0].ID(): true}
+	m.FirmID = ""
+	m.ShowActionPopup = true
+
+	app.Model = m
+	_, cmd := app.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	runCommands(app, cmd)
+
+	if len(app.Model.Jobs) != 1 || app.Model.Jobs[0].Status != models.JobFailed {
+		t.Fatalf("Expected the job to fail, got %+v", app.Model.Jobs)
+	}
+
+	if !strings.Contains(app.Model.Jobs[0].Error, "no firm set") {
+		t.Errorf("Expected failure mentioning missing firm, got: %s", app.Model.Jobs[0].Error)
+	}
+}
+
+func TestEscCancelsPendingJobs(t *testing.T) {
+	app := New()
+	m := app.InitialModel()
+
+	release := filepath.Join(t.TempDir(), "release")
+	script := filepath.Join(t.TempDir(), "silverfin")
+	body := "#!/bin/sh\nwhile [ ! -f " + release + " ]; do sleep 0.01; done\n"
+	if err := os.WriteFile(script, []byte(body), 0755); err != nil {
+		t.Fatalf("Failed to write fake CLI: %v", err)
+	}
+	app.cliRunner = &cli.Runner{Command: script}
+
+	m.Templates = nil
+	m.SelectedTemplates = make(map[models.TemplateID]bool)
+	for i := 0; i < 6; i++ {
+		tmpl := models.Template{Name: fmt.Sprintf("account_%d", i), Category: "account_templates"}
+		m.Templates = append(m.Templates, tmpl)
+		m.SelectedTemplates[tmpl.ID()] = true
+	}
+	m.FirmID = "1001"
+	app.Model = m
+
+	cmd := app.runAction("update")
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyEscape})
+	if err := os.WriteFile(release, nil, 0644); err != nil {
+		t.Fatalf("Failed to release fake CLI: %v", err)
+	}
+	runCommands(app, cmd)
+
+	cancelled := 0
+	for _, job := range app.Model.Jobs {
+		if job.Status == models.JobCancelled {
+			cancelled++
+		}
+	}
+	if cancelled == 0 {
+		t.Errorf("Expected pending jobs to be cancelled, got %+v", app.Model.Jobs)
+	}
+
+	if !strings.Contains(app.Model.Output, "cancelled") {
+		t.Errorf("Expected cancellation summary, got: %s", app.Model.Output)
+	}
+}
+
+// runCommands feeds the messages produced by cmd back into the app until no command remains.
+func runCommands(app *App, cmd tea.Cmd) {
+	for cmd != nil {
+		msg := cmd()
+		if msg == nil {
+			return
+		}
+		_, cmd = app.Update(msg)
+	}
+}
+
+func TestLogAppendsAndKeepsHistory(t *testing.T) {
+	app := New()
+	m := app.InitialModel()
+	app.Model = m
+
+	initial := len(m.Log)
+	m.CurrentSection = models.FirmSection
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyEscape})
+
+	if len(app.Model.Log) != initial+2 {
+		t.Fatalf("Expected 2 new log entries, got %d", len(app.Model.Log)-initial)
+	}
+
+	if app.Model.Log[len(app.Model.Log)-2].Message != "Select a firm or partner" {
+		t.Errorf("Expected earlier message to be kept, got %s", app.Model.Log[len(app.Model.Log)-2].Message)
+	}
+}
+
+func TestLogScrollSearchAndExpand(t *testing.T) {
+	app := New()
+	m := app.InitialModel()
+	app.Model = m
+	m.Width = 80
+	m.Height = 24
+
+	for i := 0; i < 10; i++ {
+		app.logInfo("message %d", i)
+	}
+	app.logError("Error something broke")
+
+	m.CurrentSection = models.OutputSection
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyUp})
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyUp})
+	if app.Model.LogOffset != 2 {
+		t.Errorf("Expected log offset 2 after scrolling up twice, got %d", app.Model.LogOffset)
+	}
+
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyDown})
+	if app.Model.LogOffset != 1 {
+		t.Errorf("Expected log offset 1 after scrolling down, got %d", app.Model.LogOffset)
+	}
+
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'/'}})
+	if !app.Model.LogSearchMode {
+		t.Fatalf("Expected log search mode after '/' in Output section")
+	}
+	for _, r := range "broke" {
+		_, _ = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	if app.Model.LogSearchMode || app.Model.LogSearchQuery != "broke" {
+		t.Errorf("Expected search to be applied, got mode=%v query=%q", app.Model.LogSearchMode, app.Model.LogSearchQuery)
+	}
+
+	logView := app.uiRenderer.LogView(app.Model, 5, 80)
+	if !strings.Contains(logView, "something broke") || strings.Contains(logView, "message 3") {
+		t.Errorf("Expected log view to only show matching entries, got: %s", logView)
+	}
+
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'f'}})
+	if !app.Model.LogExpanded {
+		t.Fatalf("Expected log to expand after 'f'")
+	}
+	if view := app.View(); !strings.Contains(view, "something broke") {
+		t.Errorf("Expected expanded view to show the log, got: %s", view)
+	}
+
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyEscape})
+	if app.Model.LogExpanded {
+		t.Errorf("Expected Esc to restore the normal layout")
+	}
+}
+
+func TestLogWrittenToFile(t *testing.T) {
+	app := New()
+	path := filepath.Join(t.TempDir(), "sftui.log")
+	if err := app.OpenLogFile(path); err != nil {
+		t.Fatalf("Expected log file to open: %v", err)
+	}
+	app.InitialModel()
+	app.logError("Error writing config")
+	app.CloseLogFile()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Expected log file to exist: %v", err)
+	}
+	if !strings.Contains(string(data), "ERROR Error writing config") {
+		t.Errorf("Expected error entry in log file, got: %s", string(data))
+	}
+}
+
+func TestLogFileErrorReportedOnce(t *testing.T) {
+	app := New()
+	if err := app.OpenLogFile(filepath.Join(t.TempDir(), "sftui.log")); err != nil {
+		t.Fatalf("Expected log file to open: %v", err)
+	}
+	app.InitialModel()
+	app.Model.Width = 200
+	app.logSink.Close() // later writes fail
+
+	app.logInfo("first")
+	first := app.Model.LogFileError
+	if first == "" {
+		t.Fatalf("Expected the failed write to be reported")
+	}
+	app.logInfo("second")
+	if app.Model.LogFileError != first {
+		t.Errorf("Expected only the first failure to be kept, got %q", app.Model.LogFileError)
+	}
+	if status := app.uiRenderer.StatusBarView(app.Model); !strings.Contains(status, "Log file not written") {
+		t.Errorf("Expected the status bar to show the failure, got %q", status)
+	}
+}
@@ -1,7 +1,10 @@
 package app
 
 import (
+	"errors"
+
 	"github.com/rufex/sftui/internal/models"
+	"github.com/rufex/sftui/internal/template"
 )
 
 func (a *App) GetConfigFieldCount(template models.Template) int {
@@ -77,7 +80,7 @@ func (a *App) IsFieldEditable(fieldName string) bool {
 	return a.GetFieldEditOptions(fieldName, nil) != nil
 }
 
-func (a *App) updateConfigField(templatePath, fieldName, newValue string) error {
+func (a *App) updateConfigField(tmpl models.Template, fieldName, newValue string) error {
 	var value interface{}
 	switch fieldName {
 	case "public", "is_active", "use_full_width", "downloadable_as_docx",
@@ -87,5 +90,16 @@ func (a *App) updateConfigField(templatePath, fieldName, newValue string) error
 		value = newValue
 	}
 
-	return a.configManager.UpdateConfigField(templatePath, fieldName, value)
+	return a.configManager.UpdateConfigField(tmpl, fieldName, value)
+}
+
+// logConfigEditError reports a failed config edit. When config.json changed on disk since the
+// template was loaded, the template is reloaded so the next edit starts from the current file.
+func (a *App) logConfigEditError(tmpl models.Template, field string, err error) {
+	if errors.Is(err, template.ErrConfigModified) {
+		a.reloadTemplate(tmpl.Path, tmpl.Category)
+		a.logError("%s not updated: config.json of %s changed on disk, reloaded it", field, tmpl.Name)
+		return
+	}
+	a.logError("Error updating %s: %v", field, err)
 }
@@ -207,29 +207,31 @@ func TestUpdateConfigField(t *testing.T) {
 
 	// Test with a valid template path from fixtures
 	manager := app.templateManager
-	templates := manager.LoadTemplates()
+	templates, _ := manager.LoadTemplates()
 
 	if len(templates) == 0 {
 		t.Skip("No templates available for testing")
 	}
 
-	// Use the first template's path for testing
-	templatePath := templates[0].Path
+	// Every edit is based on the template as loaded, so reload it after each one
+	tmpl := templates[0]
 
 	// Test boolean field conversion
-	err := app.updateConfigField(templatePath, "public", "true")
+	err := app.updateConfigField(tmpl, "public", "true")
 	if err != nil {
 		t.Errorf("Expected no error updating boolean field, got %v", err)
 	}
 
 	// Test string field (no conversion)
-	err = app.updateConfigField(templatePath, "reconciliation_type", "can_be_reconciled_without_data")
+	tmpl = manager.LoadTemplate(tmpl.Path, tmpl.Category)
+	err = app.updateConfigField(tmpl, "reconciliation_type", "can_be_reconciled_without_data")
 	if err != nil {
 		t.Errorf("Expected no error updating string field, got %v", err)
 	}
 
 	// Test encoding field (string, no conversion)
-	err = app.updateConfigField(templatePath, "encoding", "UTF-8")
+	tmpl = manager.LoadTemplate(tmpl.Path, tmpl.Category)
+	err = app.updateConfigField(tmpl, "encoding", "UTF-8")
 	if err != nil {
 		t.Errorf("Expected no error updating encoding field, got %v", err)
 	}
@@ -0,0 +1,220 @@
+package app
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/rufex/sftui/internal/history"
+	"github.com/rufex/sftui/internal/models"
+)
+
+// OpenRepoHistory loads the edit history of the repository, kept in the user's config directory,
+// so edits can be undone in a later session in the same repository.
+func (a *App) OpenRepoHistory() error {
+	path, err := history.PathFor(a.templateManager.RootPath())
+	if err != nil {
+		return err
+	}
+	return a.OpenHistory(path)
+}
+
+// OpenHistory loads the edit history saved at path and keeps it there.
+func (a *App) OpenHistory(path string) error {
+	loaded, err := history.Load(path)
+	if err != nil {
+		return err
+	}
+	a.history = loaded
+	a.syncHistory()
+	return nil
+}
+
+// recordEdit stores an edit made through the ConfigManager.
+func (a *App) recordEdit(entry models.HistoryEntry) {
+	a.history.Record(entry)
+	a.saveHistory()
+}
+
+func (a *App) saveHistory() {
+	if err := a.history.Save(); err != nil {
+		a.logError("Error saving history: %v", err)
+	}
+	a.syncHistory()
+}
+
+// syncHistory copies the history to the model for rendering.
+func (a *App) syncHistory() {
+	a.Model.History = a.history.Entries
+	a.Model.HistoryPosition = a.history.Position
+}
+
+func (a *App) handleUndoKey() (tea.Model, tea.Cmd) {
+	a.undo()
+	return a, nil
+}
+
+func (a *App) handleRedoKey() (tea.Model, tea.Cmd) {
+	a.redo()
+	return a, nil
+}
+
+// undo restores the files of the last edit and reloads what they belong to.
+func (a *App) undo() bool {
+	entry, err := a.history.Undo()
+	return a.afterHistoryChange("undo", "Undid", entry, err)
+}
+
+func (a *App) redo() bool {
+	entry, err := a.history.Redo()
+	return a.afterHistoryChange("redo", "Redid", entry, err)
+}
+
+func (a *App) afterHistoryChange(action, done string, entry models.HistoryEntry, err error) bool {
+	switch {
+	case errors.Is(err, history.ErrNothingToUndo), errors.Is(err, history.ErrNothingToRedo):
+		a.logInfo("Nothing to %s", action)
+		return false
+	case err != nil:
+		a.logError("Could not %s %q: %v", action, entry.Description, err)
+		return false
+	}
+
+	a.saveHistory()
+	a.reloadEditedFiles(entry)
+	a.logInfo("%s %q", done, entry.Description)
+	return true
+}
+
+// reloadEditedFiles reloads the templates owning the files of an edit, and the host and default
+// firm when the Silverfin config was among them. Files of templates no longer listed, such as a
+// text part of a template removed since, make the whole repository rescan.
+func (a *App) reloadEditedFiles(entry models.HistoryEntry) {
+	silverfinConfigPath, _ := a.configManager.SilverfinConfigPath()
+
+	var dirs []string
+	seen := make(map[string]bool)
+	silverfinConfig, unknown := false, false
+	for _, file := range entry.Files {
+		if silverfinConfigPath != "" && filepath.Clean(file.Path) == filepath.Clean(silverfinConfigPath) {
+			silverfinConfig = true
+			continue
+		}
+		dir := a.templateDirOf(file.Path)
+		if dir == "" {
+			unknown = true
+			continue
+		}
+		if !seen[dir] {
+			seen[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+
+	if unknown {
+		a.refreshTemplates()
+	} else if len(dirs) > 0 {
+		a.applyTemplateChanges(dirs)
+	}
+	if silverfinConfig {
+		_, host, _ := a.configManager.LoadSilverfinConfig()
+		a.Model.Host = host
+		if firmID, err := a.configManager.LoadDefaultFirmID(); err == nil {
+			a.Model.DefaultFirmID = firmID
+		}
+	}
+}
+
+// templateDirOf returns the directory of the loaded template holding path, or "" when the file
+// is not part of a template.
+func (a *App) templateDirOf(path string) string {
+	path = filepath.Clean(path)
+	for _, tmpl := range a.Model.Templates {
+		dir := filepath.Clean(tmpl.Path)
+		if strings.HasPrefix(path, dir+string(filepath.Separator)) {
+			return dir
+		}
+	}
+	return ""
+}
+
+// openHistory shows the edits made through the ConfigManager, starting on the last applied one.
+func (a *App) openHistory() {
+	a.Model.ShowHistory = true
+	a.Model.SelectedHistory = max(0, a.history.Position-1)
+	a.Model.HistoryOffset = 0
+	a.moveHistoryCursor(0)
+	a.logInfo("%s", a.uiRenderer.HistoryTitle(a.Model))
+}
+
+func (a *App) closeHistory() {
+	a.Model.ShowHistory = false
+}
+
+func (a *App) handleHistoryKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	pageSize := a.uiRenderer.HistoryListHeight(a.Model.FullScreenContentHeight())
+
+	// Entries are listed newest first, so moving down goes back in time
+	switch msg.String() {
+	case "ctrl+c":
+		return a, tea.Quit
+	case "esc", "q", "U":
+		a.closeHistory()
+	case "up", "k":
+		a.moveHistoryCursor(1)
+	case "down", "j":
+		a.moveHistoryCursor(-1)
+	case "pgup", "ctrl+u":
+		a.moveHistoryCursor(pageSize / 2)
+	case "pgdown", "ctrl+d":
+		a.moveHistoryCursor(-pageSize / 2)
+	case "home":
+		a.moveHistoryCursor(len(a.Model.History))
+	case "G", "end":
+		a.moveHistoryCursor(-len(a.Model.History))
+	case "enter":
+		a.restoreHistoryEntry()
+	case "u":
+		a.undo()
+		a.moveHistoryCursor(0)
+	case "ctrl+r":
+		a.redo()
+		a.moveHistoryCursor(0)
+	}
+	return a, nil
+}
+
+// moveHistoryCursor moves the highlighted edit by delta entries (positive is newer) and scrolls
+// it into view.
+func (a *App) moveHistoryCursor(delta int) {
+	n := len(a.Model.History)
+	if n == 0 {
+		return
+	}
+	a.Model.SelectedHistory = min(max(0, a.Model.SelectedHistory+delta), n-1)
+
+	row := n - 1 - a.Model.SelectedHistory
+	pageSize := a.uiRenderer.HistoryListHeight(a.Model.FullScreenContentHeight())
+	if row < a.Model.HistoryOffset {
+		a.Model.HistoryOffset = row
+	} else if row >= a.Model.HistoryOffset+pageSize {
+		a.Model.HistoryOffset = row - pageSize + 1
+	}
+}
+
+// restoreHistoryEntry undoes or redoes edits until the highlighted one is the last applied.
+func (a *App) restoreHistoryEntry() {
+	target := a.Model.SelectedHistory + 1
+	for a.history.Position > target {
+		if !a.undo() {
+			return
+		}
+	}
+	for a.history.Position < target {
+		if !a.redo() {
+			return
+		}
+	}
+}
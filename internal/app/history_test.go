@@ -0,0 +1,144 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/rufex/sftui/internal/models"
+)
+
+func TestUndoRedoConfigEdits(t *testing.T) {
+	useSilverfinConfig(t)
+	templateDir := filepath.Join("reconciliation_texts", "rt_1")
+	if err := os.MkdirAll(templateDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	configPath := filepath.Join(templateDir, "config.json")
+	original := "{\n  \"handle\": \"rt_1\",\n  \"public\": false\n}\n"
+	if err := os.WriteFile(configPath, []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	historyPath := filepath.Join(t.TempDir(), "history.json")
+	app := New()
+	if err := app.OpenHistory(historyPath); err != nil {
+		t.Fatalf("OpenHistory() error = %v", err)
+	}
+	m := app.InitialModel()
+	m.Width = 100
+	m.Height = 30
+	if len(m.Templates) != 1 {
+		t.Fatalf("Expected rt_1 to be loaded, got %d templates", len(m.Templates))
+	}
+
+	if err := app.configManager.UpdateConfigField(m.Templates[0], "public", true); err != nil {
+		t.Fatal(err)
+	}
+	reloaded := app.templateManager.LoadTemplate(templateDir, "reconciliation_texts")
+	if err := app.configManager.UpdateConfigField(reloaded, "reconciliation_type", "can_be_reconciled_without_data"); err != nil {
+		t.Fatal(err)
+	}
+	edited, _ := os.ReadFile(configPath)
+	if len(m.History) != 2 || m.HistoryPosition != 2 {
+		t.Fatalf("Expected 2 recorded edits, got %d at %d", len(m.History), m.HistoryPosition)
+	}
+	if field := m.History[0].Fields; len(field) != 1 || field[0].Field != "public" || field[0].Old != false || field[0].New != true {
+		t.Errorf("Expected public false → true to be recorded, got %+v", field)
+	}
+
+	// Both edits undone with u, restoring the file byte for byte and the loaded template
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'u'}})
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'u'}})
+	if data, _ := os.ReadFile(configPath); string(data) != original {
+		t.Errorf("Expected config.json restored after undo, got %s", data)
+	}
+	if public := app.Model.Templates[0].Config["public"]; public != false {
+		t.Errorf("Expected the template to be reloaded with public false, got %v", public)
+	}
+	if app.Model.HistoryPosition != 0 {
+		t.Errorf("Expected HistoryPosition 0, got %d", app.Model.HistoryPosition)
+	}
+
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	if public := app.Model.Templates[0].Config["public"]; public != true {
+		t.Errorf("Expected redo to set public back to true, got %v", public)
+	}
+
+	// From the history panel, Enter on the newest edit redoes up to it
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'U'}})
+	if !app.Model.ShowHistory || app.Model.SelectedHistory != 0 {
+		t.Fatalf("Expected the panel to open on the last applied edit, got %v/%d", app.Model.ShowHistory, app.Model.SelectedHistory)
+	}
+	if view := app.View(); view == "" {
+		t.Errorf("Expected the history panel to render")
+	}
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyUp})
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if data, _ := os.ReadFile(configPath); string(data) != string(edited) {
+		t.Errorf("Expected both edits applied again, got %s", data)
+	}
+
+	// The history is kept for the next session in the repository
+	next := New()
+	if err := next.OpenHistory(historyPath); err != nil {
+		t.Fatalf("OpenHistory() error = %v", err)
+	}
+	if m := next.InitialModel(); len(m.History) != 2 || m.HistoryPosition != 2 {
+		t.Errorf("Expected the saved history to be loaded, got %d at %d", len(m.History), m.HistoryPosition)
+	}
+}
+
+func TestUndoHostChange(t *testing.T) {
+	useSilverfinConfig(t)
+
+	app := New()
+	m := app.InitialModel()
+	if err := app.configManager.SetHost("https://example.com"); err != nil {
+		t.Fatal(err)
+	}
+	m.Host = "https://example.com"
+
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'u'}})
+	if m.Host == "https://example.com" {
+		t.Errorf("Expected undo to restore the host, got %s", m.Host)
+	}
+
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'u'}})
+	if last := m.Log[len(m.Log)-1]; last.Level != models.LogInfo || last.Message != "Nothing to undo" {
+		t.Errorf("Expected 'Nothing to undo', got %+v", last)
+	}
+}
+
+func TestUndoEditOfUnlistedTemplate(t *testing.T) {
+	useSilverfinConfig(t)
+	templateDir := filepath.Join("reconciliation_texts", "rt_1")
+	if err := os.MkdirAll(templateDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(templateDir, "config.json"), []byte(`{"handle": "rt_1"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	app := New()
+	m := app.InitialModel()
+	if err := app.configManager.AddTextPart(m.Templates[0], "notes", "text_parts/notes.liquid"); err != nil {
+		t.Fatal(err)
+	}
+	m.Templates = nil
+	m.Host = "unchanged"
+
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'u'}})
+
+	if _, err := os.Stat(filepath.Join(templateDir, "text_parts", "notes.liquid")); !os.IsNotExist(err) {
+		t.Errorf("Expected undo to remove the text part, got %v", err)
+	}
+	if len(app.Model.Templates) != 1 || app.Model.Templates[0].Name != "rt_1" {
+		t.Errorf("Expected the repository to be rescanned, got %v", app.Model.Templates)
+	}
+	if app.Model.Host != "unchanged" {
+		t.Errorf("Expected the Silverfin config not to be reloaded, got host %q", app.Model.Host)
+	}
+}
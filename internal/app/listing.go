@@ -0,0 +1,146 @@
+package app
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/rufex/sftui/internal/models"
+	"github.com/rufex/sftui/internal/template"
+)
+
+// applyFilter rebuilds the Templates section from the search query, sort order, grouping and
+// collapsed categories. The cursor position is left to the caller.
+func (a *App) applyFilter() {
+	a.Model.SearchError = ""
+	if _, err := template.ParseQuery(a.Model.SearchQuery); err != nil {
+		a.Model.SearchError = err.Error()
+	}
+
+	// Search results stay ranked by relevance unless a sort order was chosen with o
+	ids := a.templateManager.FilterTemplates(a.Model.Templates, a.Model.SearchQuery)
+	if a.Model.SearchQuery == "" || a.Model.TemplateSort != models.SortByCategory {
+		template.SortTemplates(a.templates(), ids, a.Model.TemplateSort, a.Model.TemplateGitStatus)
+	}
+
+	if !a.Model.GroupTemplates {
+		a.Model.FilteredTemplates = ids
+		a.Model.TemplateRows = nil
+		return
+	}
+	a.Model.FilteredTemplates, a.Model.TemplateRows = template.GroupTemplates(ids, a.Model.CollapsedCategories)
+}
+
+// relist applies the filter and keeps the cursor on the template with the given ID, or on the
+// closest visible template of its category group when it was hidden.
+func (a *App) relist(cursor models.TemplateID) {
+	a.applyFilter()
+	if position := indexOf(a.Model.FilteredTemplates, cursor); position >= 0 {
+		a.Model.SelectedTemplate = position
+	} else {
+		a.Model.SelectedTemplate = a.positionNearCategory(cursor.Category)
+	}
+	a.clampDetailField()
+	a.navHandler.AdjustScrolling(a.Model)
+}
+
+// positionNearCategory returns the first visible template after the heading of a category, or
+// the last one before it.
+func (a *App) positionNearCategory(category string) int {
+	heading := -1
+	for i, row := range a.Model.TemplateRows {
+		if row.Category == category {
+			heading = i
+			break
+		}
+	}
+	if heading < 0 {
+		return 0
+	}
+	for _, row := range a.Model.TemplateRows[heading:] {
+		if row.Category == "" {
+			return row.Template
+		}
+	}
+	return max(0, len(a.Model.FilteredTemplates)-1)
+}
+
+// loadGitStatus reads the git status of the templates used by the git status sort order.
+func (a *App) loadGitStatus() {
+	status, err := a.templateManager.GitStatus(a.Model.Templates)
+	if err != nil {
+		a.logWarn("Git status unavailable: %v", err)
+		status = nil
+	}
+	a.Model.TemplateGitStatus = status
+}
+
+// handleSortKey switches to the next sort order of the Templates section.
+func (a *App) handleSortKey() (tea.Model, tea.Cmd) {
+	cursor := a.cursorTemplateID()
+	a.Model.TemplateSort = (a.Model.TemplateSort + 1) % (models.SortByGitStatus + 1)
+	if a.Model.TemplateSort == models.SortByGitStatus {
+		a.loadGitStatus()
+	}
+	a.relist(cursor)
+	a.logInfo("Templates sorted by %s", a.Model.TemplateSort)
+	return a, nil
+}
+
+// handleGroupKey toggles listing templates under category headings.
+func (a *App) handleGroupKey() (tea.Model, tea.Cmd) {
+	cursor := a.cursorTemplateID()
+	a.Model.GroupTemplates = !a.Model.GroupTemplates
+	a.relist(cursor)
+	if a.Model.GroupTemplates {
+		a.logInfo("Templates grouped by category (z to collapse a group)")
+	} else {
+		a.logInfo("Templates no longer grouped")
+	}
+	return a, nil
+}
+
+// handleCollapseKey collapses or expands the category group of the template under the cursor.
+func (a *App) handleCollapseKey() (tea.Model, tea.Cmd) {
+	if !a.Model.GroupTemplates {
+		a.logInfo("Press G to group templates by category first")
+		return a, nil
+	}
+	cursor, ok := a.currentTemplate()
+	if !ok {
+		return a, nil
+	}
+
+	if a.Model.CollapsedCategories == nil {
+		a.Model.CollapsedCategories = make(map[string]bool)
+	}
+	a.Model.CollapsedCategories[cursor.Category] = true
+	a.relist(cursor.ID())
+	a.logInfo("Collapsed %s", a.templateManager.GetCategoryGroupName(cursor.Category))
+	return a, nil
+}
+
+// handleCollapseAllKey expands every collapsed group, or collapses all groups except the one
+// under the cursor when none is collapsed.
+func (a *App) handleCollapseAllKey() (tea.Model, tea.Cmd) {
+	if !a.Model.GroupTemplates {
+		a.logInfo("Press G to group templates by category first")
+		return a, nil
+	}
+	cursor := a.cursorTemplateID()
+
+	if len(a.Model.CollapsedCategories) > 0 {
+		a.Model.CollapsedCategories = nil
+		a.relist(cursor)
+		a.logInfo("Expanded all groups")
+		return a, nil
+	}
+
+	a.Model.CollapsedCategories = make(map[string]bool)
+	for _, category := range template.Categories {
+		if category != cursor.Category {
+			a.Model.CollapsedCategories[category] = true
+		}
+	}
+	a.relist(cursor)
+	a.logInfo("Collapsed all groups except %s", a.templateManager.GetCategoryGroupName(cursor.Category))
+	return a, nil
+}
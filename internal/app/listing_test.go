@@ -0,0 +1,139 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/rufex/sftui/internal/models"
+)
+
+func newListingTestApp() *App {
+	app := New()
+	m := app.InitialModel()
+	m.Templates = []models.Template{
+		{Name: "b_at", Category: "account_templates"},
+		{Name: "a_rt", Category: "reconciliation_texts"},
+		{Name: "c_rt", Category: "reconciliation_texts"},
+		{Name: "a_sp", Category: "shared_parts"},
+	}
+	m.Width = 120
+	m.Height = 30
+	app.Model = m
+	app.buildSharedPartsMapping()
+	app.applyFilter()
+	return app
+}
+
+func cursorName(app *App) string {
+	template, _ := app.Model.CurrentTemplate()
+	return template.Name
+}
+
+func TestSortKeyKeepsCursor(t *testing.T) {
+	app := newListingTestApp()
+	app.Model.SelectedTemplate = 2 // c_rt
+
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'o'}})
+	if app.Model.TemplateSort != models.SortByName {
+		t.Fatalf("Expected 'o' to sort by name, got %s", app.Model.TemplateSort)
+	}
+
+	var names []string
+	for _, id := range app.Model.FilteredTemplates {
+		names = append(names, id.Name)
+	}
+	if strings.Join(names, ",") != "a_rt,a_sp,b_at,c_rt" {
+		t.Errorf("Expected templates sorted by name, got %v", names)
+	}
+	if cursorName(app) != "c_rt" {
+		t.Errorf("Expected the cursor to stay on c_rt, got %s", cursorName(app))
+	}
+	if !strings.Contains(app.View(), "Templates (4) by name") {
+		t.Errorf("Expected the title to show the sort order")
+	}
+}
+
+func TestGroupAndCollapse(t *testing.T) {
+	app := newListingTestApp()
+	app.Model.SelectedTemplate = 1 // a_rt
+
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'G'}})
+	if !app.Model.GroupTemplates || len(app.Model.TemplateRows) != 7 {
+		t.Fatalf("Expected 3 headings and 4 templates, got %+v", app.Model.TemplateRows)
+	}
+	if view := app.View(); !strings.Contains(view, "▾ Reconciliation Texts (2)") {
+		t.Errorf("Expected a heading with the group count")
+	}
+	if line := app.Model.TemplateLine(); line != 3 {
+		t.Errorf("Expected a_rt on line 3 below its heading, got %d", line)
+	}
+
+	// Collapsing the group moves the cursor to the next group
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'z'}})
+	if len(app.Model.FilteredTemplates) != 2 || cursorName(app) != "a_sp" {
+		t.Errorf("Expected the reconciliation texts hidden and the cursor on a_sp, got %v on %s", app.Model.FilteredTemplates, cursorName(app))
+	}
+	if view := app.View(); !strings.Contains(view, "▸ Reconciliation Texts (2)") {
+		t.Errorf("Expected the collapsed heading to keep its count")
+	}
+
+	// Z expands everything, a second Z keeps only the current group open
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'Z'}})
+	if len(app.Model.FilteredTemplates) != 4 || cursorName(app) != "a_sp" {
+		t.Errorf("Expected all groups expanded with the cursor on a_sp, got %v", app.Model.FilteredTemplates)
+	}
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'Z'}})
+	if len(app.Model.FilteredTemplates) != 1 || cursorName(app) != "a_sp" {
+		t.Errorf("Expected only the shared parts expanded, got %v", app.Model.FilteredTemplates)
+	}
+
+	// Jumping to a template of a collapsed group expands it
+	app.selectTemplate(models.TemplateID{Category: "reconciliation_texts", Name: "c_rt"})
+	if cursorName(app) != "c_rt" || app.Model.CollapsedCategories["reconciliation_texts"] {
+		t.Errorf("Expected the jump to expand the reconciliation texts, got cursor %s", cursorName(app))
+	}
+}
+
+func TestSearchQuery(t *testing.T) {
+	app := newListingTestApp()
+	app.Model.Templates[2].Config = map[string]interface{}{"public": true}
+	app.Model.CurrentSection = models.TemplatesSection
+
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'/'}})
+	for _, r := range "type:rt public:true" {
+		_, _ = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	if len(app.Model.FilteredTemplates) != 1 || app.Model.FilteredTemplates[0].Name != "c_rt" {
+		t.Errorf("Expected only c_rt to match, got %v", app.Model.FilteredTemplates)
+	}
+
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{')'}})
+	if app.Model.SearchError == "" || !strings.Contains(app.View(), "unexpected )") {
+		t.Errorf("Expected the search bar to explain the invalid query, got %q", app.Model.SearchError)
+	}
+
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	if app.Model.SearchError != "" {
+		t.Errorf("Expected the error to clear, got %q", app.Model.SearchError)
+	}
+}
+
+func TestSearchRanksByRelevance(t *testing.T) {
+	app := newListingTestApp()
+	app.Model.SearchQuery = "rt"
+	app.applyFilter()
+
+	// c_rt and a_rt end in "rt", a word start; the others only match in their path
+	if len(app.Model.FilteredTemplates) < 2 || app.Model.FilteredTemplates[0].Name != "a_rt" || app.Model.FilteredTemplates[1].Name != "c_rt" {
+		t.Errorf("Expected the reconciliation texts first, got %v", app.Model.FilteredTemplates)
+	}
+
+	// A chosen sort order overrides relevance
+	app.Model.TemplateSort = models.SortByName
+	app.applyFilter()
+	if app.Model.FilteredTemplates[0].Name != "a_rt" || app.Model.FilteredTemplates[len(app.Model.FilteredTemplates)-1].Name != "c_rt" {
+		t.Errorf("Expected templates sorted by name, got %v", app.Model.FilteredTemplates)
+	}
+}
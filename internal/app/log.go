@@ -0,0 +1,89 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rufex/sftui/internal/logging"
+	"github.com/rufex/sftui/internal/models"
+)
+
+// OpenLogFile mirrors every log entry to the file at path.
+func (a *App) OpenLogFile(path string) error {
+	sink, err := logging.OpenFile(path)
+	if err != nil {
+		return err
+	}
+	a.logSink = sink
+	return nil
+}
+
+// CloseLogFile closes the log file opened by OpenLogFile, if any.
+func (a *App) CloseLogFile() error {
+	if a.logSink == nil {
+		return nil
+	}
+	err := a.logSink.Close()
+	a.logSink = nil
+	return err
+}
+
+func (a *App) logInfo(format string, args ...interface{}) {
+	a.appendLog(models.LogInfo, fmt.Sprintf(format, args...))
+}
+
+func (a *App) logWarn(format string, args ...interface{}) {
+	a.appendLog(models.LogWarn, fmt.Sprintf(format, args...))
+}
+
+func (a *App) logError(format string, args ...interface{}) {
+	a.appendLog(models.LogError, fmt.Sprintf(format, args...))
+}
+
+// logJob records a finished job together with its CLI output, grouped under the job label.
+func (a *App) logJob(job models.Job) {
+	switch job.Status {
+	case models.JobSucceeded:
+		a.appendLogEntry(models.LogInfo, job.Label, fmt.Sprintf("%s succeeded", a.Model.JobsAction))
+	case models.JobFailed:
+		a.appendLogEntry(models.LogError, job.Label, fmt.Sprintf("%s failed: %s", a.Model.JobsAction, job.Error))
+	case models.JobCancelled:
+		a.appendLogEntry(models.LogWarn, job.Label, fmt.Sprintf("%s cancelled", a.Model.JobsAction))
+	default:
+		return
+	}
+
+	for _, line := range strings.Split(job.Output, "\n") {
+		if strings.TrimSpace(line) != "" {
+			a.appendLogEntry(models.LogInfo, job.Label, line)
+		}
+	}
+}
+
+// appendLog records a general message and makes it the current output.
+func (a *App) appendLog(level models.LogLevel, message string) {
+	a.Model.Output = message
+	a.appendLogEntry(level, "", message)
+}
+
+func (a *App) appendLogEntry(level models.LogLevel, group, message string) {
+	entry := models.LogEntry{
+		Time:    time.Now(),
+		Level:   level,
+		Group:   group,
+		Message: message,
+	}
+	a.Model.Log = append(a.Model.Log, entry)
+
+	// Keep the view anchored on the same lines while the user is scrolled up
+	if a.Model.LogOffset > 0 {
+		a.Model.LogOffset++
+	}
+
+	if a.logSink != nil {
+		if err := a.logSink.Write(entry); err != nil && a.Model.LogFileError == "" {
+			a.Model.LogFileError = err.Error()
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package app
+
+import (
+	"os"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func (a *App) openPreview(path, title string) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		a.logError("Error opening %s: %v", title, err)
+		return
+	}
+
+	a.Model.ShowPreview = true
+	a.Model.PreviewTitle = title
+	a.Model.PreviewPath = path
+	a.Model.PreviewContent = string(data)
+	a.Model.PreviewOffset = 0
+	a.Model.PreviewSearchMode = false
+	a.Model.PreviewSearchQuery = ""
+	a.Model.PreviewMatchLine = -1
+	a.logInfo("Viewing %s", title)
+}
+
+func (a *App) closePreview() {
+	a.Model.ShowPreview = false
+	a.Model.PreviewContent = ""
+	a.Model.PreviewSearchMode = false
+	a.Model.PreviewSearchQuery = ""
+	a.Model.PreviewMatchLine = -1
+}
+
+func (a *App) handlePreviewKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if a.Model.PreviewSearchMode {
+		return a.handlePreviewSearch(msg)
+	}
+
+	pageSize := a.Model.FullScreenContentHeight()
+
+	switch msg.String() {
+	case "ctrl+c":
+		return a, tea.Quit
+	case "esc", "q":
+		a.closePreview()
+	case "up", "k":
+		a.scrollPreview(-1)
+	case "down", "j":
+		a.scrollPreview(1)
+	case "pgup", "ctrl+u":
+		a.scrollPreview(-pageSize / 2)
+	case "pgdown", "ctrl+d", " ":
+		a.scrollPreview(pageSize / 2)
+	case "g", "home":
+		a.Model.PreviewOffset = 0
+	case "G", "end":
+		a.scrollPreview(len(a.previewLines()))
+	case "/":
+		a.Model.PreviewSearchMode = true
+		a.Model.PreviewSearchQuery = ""
+	case "e":
+		return a.handleEditKey(false)
+	case "n":
+		a.jumpToPreviewMatch(a.Model.PreviewMatchLine+1, 1)
+	case "N":
+		a.jumpToPreviewMatch(a.Model.PreviewMatchLine-1, -1)
+	}
+	return a, nil
+}
+
+func (a *App) handlePreviewSearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		a.Model.PreviewSearchMode = false
+		a.Model.PreviewSearchQuery = ""
+		a.Model.PreviewMatchLine = -1
+	case "enter":
+		a.Model.PreviewSearchMode = false
+		a.jumpToPreviewMatch(a.Model.PreviewOffset, 1)
+	case "backspace":
+		if len(a.Model.PreviewSearchQuery) > 0 {
+			a.Model.PreviewSearchQuery = a.Model.PreviewSearchQuery[:len(a.Model.PreviewSearchQuery)-1]
+		}
+	default:
+		if msg.Type == tea.KeyRunes && len(msg.Runes) == 1 {
+			char := msg.Runes[0]
+			if char >= 32 && char < 127 {
+				a.Model.PreviewSearchQuery += string(char)
+			}
+		}
+	}
+	return a, nil
+}
+
+func (a *App) previewLines() []string {
+	return strings.Split(a.Model.PreviewContent, "\n")
+}
+
+func (a *App) scrollPreview(delta int) {
+	maxOffset := max(0, len(a.previewLines())-a.Model.FullScreenContentHeight())
+	a.Model.PreviewOffset = min(max(0, a.Model.PreviewOffset+delta), maxOffset)
+}
+
+// jumpToPreviewMatch moves to the next line containing the search query, starting at from
+// and searching in direction (1 forward, -1 backward), wrapping around the file.
+func (a *App) jumpToPreviewMatch(from, direction int) {
+	query := strings.ToLower(a.Model.PreviewSearchQuery)
+	if query == "" {
+		return
+	}
+
+	lines := a.previewLines()
+	for i := 0; i < len(lines); i++ {
+		lineIdx := ((from+i*direction)%len(lines) + len(lines)) % len(lines)
+		if strings.Contains(strings.ToLower(lines[lineIdx]), query) {
+			a.Model.PreviewMatchLine = lineIdx
+			// Keep some context above the match
+			a.Model.PreviewOffset = 0
+			a.scrollPreview(lineIdx - a.Model.FullScreenContentHeight()/3)
+			return
+		}
+	}
+
+	a.Model.PreviewMatchLine = -1
+	a.logWarn("No match for %q in %s", a.Model.PreviewSearchQuery, a.Model.PreviewTitle)
+}
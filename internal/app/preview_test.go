@@ -0,0 +1,104 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/rufex/sftui/internal/models"
+)
+
+func newPreviewTestApp(t *testing.T) *App {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "reconciliation_texts", "rt_1")
+	if err := os.MkdirAll(filepath.Join(dir, "text_parts"), 0755); err != nil {
+		t.Fatalf("Failed to create template dir: %v", err)
+	}
+	mainSource := "{% comment %}header{% endcomment %}\n{% include \"parts/part_1\" %}\nTotal: {{ total }}\n"
+	if err := os.WriteFile(filepath.Join(dir, "main.liquid"), []byte(mainSource), 0644); err != nil {
+		t.Fatalf("Failed to write main.liquid: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "text_parts", "part_1.liquid"), []byte("part one\n"), 0644); err != nil {
+		t.Fatalf("Failed to write text part: %v", err)
+	}
+
+	app := New()
+	m := app.InitialModel()
+	m.Templates = []models.Template{{
+		Name:     "rt_1",
+		Path:     dir,
+		Category: "reconciliation_texts",
+		Config: map[string]interface{}{
+			"reconciliation_type": "only_reconciled_with_data",
+			"text":                "main.liquid",
+			"text_parts":          map[string]interface{}{"part_1": "text_parts/part_1.liquid"},
+		},
+	}}
+	m.FilteredTemplates = app.templateManager.FilterTemplates(m.Templates, "")
+	m.SelectedTemplate = 0
+	m.CurrentSection = models.DetailsSection
+	m.Width = 80
+	m.Height = 24
+	app.Model = m
+	return app
+}
+
+func TestPreviewMainLiquid(t *testing.T) {
+	app := newPreviewTestApp(t)
+
+	// reconciliation_type is the only config field, so the main file is field 1
+	app.Model.SelectedDetailField = 1
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	if !app.Model.ShowPreview {
+		t.Fatalf("Expected preview to open, output: %s", app.Model.Output)
+	}
+	if !strings.Contains(app.Model.PreviewContent, "Total: {{ total }}") {
+		t.Errorf("Expected main.liquid content, got: %s", app.Model.PreviewContent)
+	}
+
+	view := app.View()
+	if !strings.Contains(view, "rt_1/main.liquid") || !strings.Contains(view, "3 │ Total:") {
+		t.Errorf("Expected preview with title and line numbers, got: %s", view)
+	}
+
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyEscape})
+	if app.Model.ShowPreview {
+		t.Errorf("Expected Esc to close the preview")
+	}
+}
+
+func TestPreviewTextPart(t *testing.T) {
+	app := newPreviewTestApp(t)
+
+	app.Model.SelectedDetailField = 2
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	if !app.Model.ShowPreview || app.Model.PreviewContent != "part one\n" {
+		t.Errorf("Expected text part preview, got show=%v content=%q", app.Model.ShowPreview, app.Model.PreviewContent)
+	}
+}
+
+func TestPreviewSearch(t *testing.T) {
+	app := newPreviewTestApp(t)
+	app.openPreview(filepath.Join(app.Model.Templates[0].Path, "main.liquid"), "rt_1/main.liquid")
+
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'/'}})
+	for _, r := range "total" {
+		_, _ = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	if app.Model.PreviewMatchLine != 2 {
+		t.Errorf("Expected match on line index 2, got %d", app.Model.PreviewMatchLine)
+	}
+
+	// The only match wraps around to itself
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+	if app.Model.PreviewMatchLine != 2 {
+		t.Errorf("Expected next match to wrap to line index 2, got %d", app.Model.PreviewMatchLine)
+	}
+}
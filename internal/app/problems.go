@@ -0,0 +1,92 @@
+package app
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/rufex/sftui/internal/models"
+)
+
+// openProblems shows the problems found while loading templates.
+func (a *App) openProblems() {
+	a.Model.ShowProblems = true
+	a.Model.SelectedProblem = 0
+	a.Model.ProblemsOffset = 0
+	a.logInfo("%s", a.uiRenderer.ProblemsTitle(a.Model))
+}
+
+func (a *App) closeProblems() {
+	a.Model.ShowProblems = false
+}
+
+func (a *App) handleProblemsKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		return a, tea.Quit
+	case "esc", "q", "P":
+		a.closeProblems()
+	case "up", "k":
+		a.moveProblemCursor(-1)
+	case "down", "j":
+		a.moveProblemCursor(1)
+	case "enter":
+		a.jumpToProblem()
+	}
+	return a, nil
+}
+
+func (a *App) moveProblemCursor(delta int) {
+	if len(a.Model.LoadProblems) == 0 {
+		return
+	}
+
+	a.Model.SelectedProblem = min(max(0, a.Model.SelectedProblem+delta), len(a.Model.LoadProblems)-1)
+
+	pageSize := a.Model.FullScreenContentHeight()
+	if a.Model.SelectedProblem < a.Model.ProblemsOffset {
+		a.Model.ProblemsOffset = a.Model.SelectedProblem
+	} else if a.Model.SelectedProblem >= a.Model.ProblemsOffset+pageSize {
+		a.Model.ProblemsOffset = a.Model.SelectedProblem - pageSize + 1
+	}
+}
+
+// jumpToProblem closes the panel and selects the template of the highlighted problem.
+func (a *App) jumpToProblem() {
+	if a.Model.SelectedProblem >= len(a.Model.LoadProblems) {
+		return
+	}
+
+	problem := a.Model.LoadProblems[a.Model.SelectedProblem]
+	if problem.Template == "" {
+		a.logWarn("%s is not part of a loaded template", problem.Path)
+		return
+	}
+
+	if index := a.templateIndexByPath(problem.Template); index >= 0 {
+		candidate := a.Model.Templates[index]
+		a.closeProblems()
+		a.selectTemplate(candidate.ID())
+		a.logInfo("Showing %s", candidate.Name)
+		return
+	}
+	a.logWarn("Template %s not found", problem.Template)
+}
+
+// refreshLoadProblems replaces the load problems of a reloaded template with its current ones.
+func (a *App) refreshLoadProblems(reloaded models.Template) {
+	a.dropLoadProblems(reloaded.Path)
+	a.Model.LoadProblems = append(a.Model.LoadProblems, a.templateManager.TemplateProblems(reloaded)...)
+}
+
+// dropLoadProblems removes the load problems of a template.
+func (a *App) dropLoadProblems(templatePath string) {
+	problems := a.Model.LoadProblems[:0]
+	for _, problem := range a.Model.LoadProblems {
+		if problem.Template != templatePath {
+			problems = append(problems, problem)
+		}
+	}
+	a.Model.LoadProblems = problems
+
+	if a.Model.SelectedProblem >= len(a.Model.LoadProblems) {
+		a.Model.SelectedProblem = max(0, len(a.Model.LoadProblems)-1)
+	}
+}
@@ -0,0 +1,70 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/rufex/sftui/internal/models"
+)
+
+func TestProblemsPanelJumpsToTemplate(t *testing.T) {
+	root := t.TempDir()
+	goodDir := filepath.Join(root, "account_templates", "good")
+	brokenDir := filepath.Join(root, "account_templates", "broken")
+	files := map[string]string{
+		filepath.Join(goodDir, "config.json"):   `{"text_parts": {}}`,
+		filepath.Join(goodDir, "main.liquid"):   ``,
+		filepath.Join(brokenDir, "config.json"): `{"text_parts": {}}`,
+	}
+	for path, content := range files {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	app := New()
+	m := app.InitialModel()
+	m.Templates = []models.Template{
+		app.templateManager.LoadTemplate(goodDir, "account_templates"),
+		app.templateManager.LoadTemplate(brokenDir, "account_templates"),
+	}
+	m.LoadProblems = nil
+	for _, template := range m.Templates {
+		m.LoadProblems = append(m.LoadProblems, app.templateManager.TemplateProblems(template)...)
+	}
+	m.FilteredTemplates = app.templateManager.FilterTemplates(m.Templates, "")
+	m.Width = 140
+	m.Height = 30
+	app.Model = m
+
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'P'}})
+	if !app.Model.ShowProblems || len(app.Model.LoadProblems) != 1 {
+		t.Fatalf("Expected one load problem, got %+v", app.Model.LoadProblems)
+	}
+	if !strings.Contains(app.View(), "main Liquid file main.liquid does not exist") {
+		t.Errorf("Expected the panel to describe the missing Liquid file")
+	}
+
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if app.Model.ShowProblems {
+		t.Errorf("Expected Enter to close the panel")
+	}
+	if actual, _ := app.Model.CurrentTemplate(); actual.ID() != m.Templates[1].ID() {
+		t.Errorf("Expected the broken template to be selected, got %s", actual.ID())
+	}
+
+	if err := os.WriteFile(filepath.Join(brokenDir, "main.liquid"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	app.reloadTemplate(brokenDir, "account_templates")
+	if len(app.Model.LoadProblems) != 0 {
+		t.Errorf("Expected the problem to disappear after reloading, got %+v", app.Model.LoadProblems)
+	}
+}
@@ -0,0 +1,62 @@
+package app
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/rufex/sftui/internal/models"
+)
+
+// restoreSelection reapplies the search filter after templates were reloaded, added or removed,
+// keeps the cursor on the template with the given ID and drops selected templates that no
+// longer exist.
+func (a *App) restoreSelection(cursor models.TemplateID) {
+	registry := a.templates()
+	for id := range a.Model.SelectedTemplates {
+		if registry.Index(id) < 0 {
+			delete(a.Model.SelectedTemplates, id)
+		}
+	}
+
+	if a.Model.TemplateSort == models.SortByGitStatus {
+		a.loadGitStatus()
+	}
+	a.applyFilter()
+	if position := indexOf(a.Model.FilteredTemplates, cursor); position >= 0 {
+		a.Model.SelectedTemplate = position
+	} else if a.Model.SelectedTemplate >= len(a.Model.FilteredTemplates) {
+		a.Model.SelectedTemplate = max(0, len(a.Model.FilteredTemplates)-1)
+	}
+	a.clampDetailField()
+	a.navHandler.AdjustScrolling(a.Model)
+}
+
+// cursorTemplateID returns the ID of the template under the cursor, or the zero ID.
+func (a *App) cursorTemplateID() models.TemplateID {
+	if a.Model.SelectedTemplate < len(a.Model.FilteredTemplates) {
+		return a.Model.FilteredTemplates[a.Model.SelectedTemplate]
+	}
+	return models.TemplateID{}
+}
+
+// handleRefreshKey rescans the repository for templates.
+func (a *App) handleRefreshKey() (tea.Model, tea.Cmd) {
+	a.refreshTemplates()
+	return a, nil
+}
+
+// refreshTemplates reloads every template from disk, keeping the cursor, filter and selection.
+func (a *App) refreshTemplates() {
+	cursor := a.cursorTemplateID()
+	before := len(a.Model.Templates)
+
+	a.Model.Templates, a.Model.LoadProblems = a.templateManager.LoadTemplates()
+	a.buildSharedPartsMapping()
+	a.restoreSelection(cursor)
+
+	message := "Rescanned %d templates (%+d)"
+	if len(a.Model.LoadProblems) > 0 {
+		a.logWarn(message+", %d problems (P to show)", len(a.Model.Templates), len(a.Model.Templates)-before, len(a.Model.LoadProblems))
+		return
+	}
+	a.logInfo(message, len(a.Model.Templates), len(a.Model.Templates)-before)
+}
@@ -0,0 +1,72 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestRefreshKeepsSelectionByName(t *testing.T) {
+	root := t.TempDir()
+	writeTemplate := func(category, name string) {
+		dir := filepath.Join(root, category, name)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(`{"text_parts": {}}`), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeTemplate("account_templates", "at_1")
+	writeTemplate("export_files", "ef_1")
+	writeTemplate("export_files", "ef_2")
+	t.Chdir(root)
+
+	app := New()
+	m := app.InitialModel()
+	m.Width = 120
+	m.Height = 30
+	if len(m.Templates) != 3 {
+		t.Fatalf("Expected 3 templates, got %d", len(m.Templates))
+	}
+
+	// Filter on ef_, select both export files and put the cursor on ef_2
+	m.SearchQuery = "ef_"
+	m.FilteredTemplates = app.templateManager.FilterTemplates(m.Templates, m.SearchQuery)
+	for _, tmpl := range m.Templates {
+		if tmpl.Category == "export_files" {
+			m.SelectedTemplates[tmpl.ID()] = true
+		}
+		if tmpl.Name == "ef_2" {
+			m.SelectedTemplate = indexOf(m.FilteredTemplates, tmpl.ID())
+		}
+	}
+
+	// A template sorting before the others is added and ef_1 is removed
+	writeTemplate("account_templates", "at_0")
+	if err := os.RemoveAll(filepath.Join(root, "export_files", "ef_1")); err != nil {
+		t.Fatal(err)
+	}
+
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'R'}})
+
+	if len(app.Model.Templates) != 3 {
+		t.Fatalf("Expected 3 templates after refresh, got %d", len(app.Model.Templates))
+	}
+	if app.Model.SearchQuery != "ef_" || len(app.Model.FilteredTemplates) != 1 {
+		t.Errorf("Expected the filter to keep only ef_2, got %v", app.Model.FilteredTemplates)
+	}
+	if cursor, _ := app.Model.CurrentTemplate(); cursor.Name != "ef_2" {
+		t.Errorf("Expected the cursor on ef_2, got %s", cursor.Name)
+	}
+	if len(app.Model.SelectedTemplates) != 1 {
+		t.Fatalf("Expected only ef_2 to stay selected, got %v", app.Model.SelectedTemplates)
+	}
+	for id := range app.Model.SelectedTemplates {
+		if id.Name != "ef_2" {
+			t.Errorf("Expected ef_2 to be selected, got %s", id)
+		}
+	}
+}
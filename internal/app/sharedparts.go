@@ -0,0 +1,186 @@
+package app
+
+import (
+	"errors"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/rufex/sftui/internal/models"
+	"github.com/rufex/sftui/internal/template"
+	"github.com/rufex/sftui/internal/ui"
+)
+
+// openSharedPartPopup lists the templates that can use the highlighted shared part.
+func (a *App) openSharedPartPopup() (tea.Model, tea.Cmd) {
+	sharedPart, ok := a.currentTemplate()
+	if !ok {
+		return a, nil
+	}
+	if sharedPart.Category != "shared_parts" {
+		a.logInfo("Select a shared part to link it to templates")
+		return a, nil
+	}
+
+	a.Model.SharedPartTemplate = sharedPart.ID()
+	a.Model.SharedPartCandidates = nil
+	a.Model.SharedPartLinks = make(map[models.TemplateID]bool)
+	for _, candidate := range a.Model.Templates {
+		if template.UsedInType(candidate.Category) == "" {
+			continue
+		}
+		a.Model.SharedPartCandidates = append(a.Model.SharedPartCandidates, candidate.ID())
+		if a.usesSharedPart(candidate, sharedPart.Name) {
+			a.Model.SharedPartLinks[candidate.ID()] = true
+		}
+	}
+
+	a.Model.ShowSharedPartPopup = true
+	a.Model.SelectedSharedPartCandidate = 0
+	a.Model.SharedPartPopupOffset = 0
+	a.logInfo("Toggle the templates using %s", sharedPart.Name)
+	return a, nil
+}
+
+func (a *App) usesSharedPart(candidate models.Template, sharedPartName string) bool {
+	for _, name := range a.Model.SharedPartsUsage[candidate.ID()] {
+		if name == sharedPartName {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *App) closeSharedPartPopup() {
+	a.Model.ShowSharedPartPopup = false
+	a.Model.SharedPartCandidates = nil
+	a.Model.SharedPartLinks = nil
+}
+
+func (a *App) handleSharedPartPopup(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		a.closeSharedPartPopup()
+		a.logInfo("Shared part links unchanged")
+	case "up", "k":
+		a.moveSharedPartCursor(-1)
+	case "down", "j":
+		a.moveSharedPartCursor(1)
+	case " ":
+		if a.Model.SelectedSharedPartCandidate < len(a.Model.SharedPartCandidates) {
+			id := a.Model.SharedPartCandidates[a.Model.SelectedSharedPartCandidate]
+			a.Model.SharedPartLinks[id] = !a.Model.SharedPartLinks[id]
+		}
+	case "c":
+		a.Model.SharedPartSyncCLI = !a.Model.SharedPartSyncCLI
+	case "enter":
+		return a, a.saveSharedPartLinks()
+	}
+	return a, nil
+}
+
+func (a *App) moveSharedPartCursor(delta int) {
+	count := len(a.Model.SharedPartCandidates)
+	if count == 0 {
+		return
+	}
+
+	a.Model.SelectedSharedPartCandidate = min(max(0, a.Model.SelectedSharedPartCandidate+delta), count-1)
+
+	visibleRows := ui.SharedPartPopupVisibleRows(a.Model)
+	if a.Model.SelectedSharedPartCandidate < a.Model.SharedPartPopupOffset {
+		a.Model.SharedPartPopupOffset = a.Model.SelectedSharedPartCandidate
+	} else if a.Model.SelectedSharedPartCandidate >= a.Model.SharedPartPopupOffset+visibleRows {
+		a.Model.SharedPartPopupOffset = a.Model.SelectedSharedPartCandidate - visibleRows + 1
+	}
+}
+
+// saveSharedPartLinks writes the changed links to the shared part's used_in array and, when CLI
+// sync is on, links or unlinks them in the firm as background jobs.
+func (a *App) saveSharedPartLinks() tea.Cmd {
+	sharedPart, ok := a.templates().Get(a.Model.SharedPartTemplate)
+	links := a.Model.SharedPartLinks
+	candidates := a.Model.SharedPartCandidates
+	a.closeSharedPartPopup()
+	if !ok {
+		a.logWarn("Shared part %s no longer exists", a.Model.SharedPartTemplate)
+		return nil
+	}
+
+	var changed []models.Template
+	linked := make(map[models.TemplateID]bool)
+	err := a.configManager.Batch("Update used_in of "+sharedPart.Name, func() error {
+		for _, id := range candidates {
+			candidate, ok := a.templates().Get(id)
+			if !ok {
+				continue
+			}
+			link := links[id]
+			if link == a.usesSharedPart(candidate, sharedPart.Name) {
+				continue
+			}
+
+			var updated bool
+			var err error
+			if link {
+				updated, err = a.configManager.LinkSharedPart(sharedPart, candidate)
+			} else {
+				updated, err = a.configManager.UnlinkSharedPart(sharedPart, candidate)
+			}
+			if errors.Is(err, template.ErrConfigModified) {
+				// Every other link would be based on the same outdated used_in
+				a.logConfigEditError(sharedPart, "used_in of "+sharedPart.Name, err)
+				return err
+			}
+			if err != nil {
+				a.logError("Error updating %s for %s: %v", sharedPart.Name, candidate.Name, err)
+				continue
+			}
+			if !updated {
+				continue
+			}
+
+			changed = append(changed, candidate)
+			linked[id] = link
+		}
+		return nil
+	})
+
+	if len(changed) == 0 {
+		if err == nil {
+			a.logInfo("Shared part links unchanged")
+		}
+		return nil
+	}
+
+	a.reloadTemplate(sharedPart.Path, sharedPart.Category)
+	a.logInfo("Updated used_in of %s: %d templates changed", sharedPart.Name, len(changed))
+
+	if !a.Model.SharedPartSyncCLI {
+		return nil
+	}
+	if a.Model.FirmID == "" {
+		a.logWarn("No firm set, shared part links were not synced with the CLI")
+		return nil
+	}
+
+	target := a.firm()
+	return a.startJobs("link "+sharedPart.Name, changed, func(candidate models.Template) (string, error) {
+		result := a.cliRunner.RunSharedPart(linked[candidate.ID()], sharedPart, candidate, target)
+		return result.Output, result.Err
+	})
+}
+
+// jumpToSharedPartUser selects the template at the given position of the shared part's Used In list.
+func (a *App) jumpToSharedPartUser(sharedPart models.Template, userIndex int) {
+	users := template.SharedPartUsers(a.Model.SharedPartsUsage, sharedPart.Name)
+	if userIndex < 0 || userIndex >= len(users) {
+		return
+	}
+
+	if candidate, ok := a.templates().Get(users[userIndex]); ok {
+		a.selectTemplate(candidate.ID())
+		a.logInfo("Showing %s, which uses %s", candidate.Name, sharedPart.Name)
+		return
+	}
+	a.logWarn("%s lists %s in used_in, but no such template exists", sharedPart.Name, users[userIndex])
+}
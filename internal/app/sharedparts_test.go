@@ -0,0 +1,151 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/rufex/sftui/internal/cli"
+	"github.com/rufex/sftui/internal/models"
+)
+
+func TestSharedPartPopupLinksTemplates(t *testing.T) {
+	sharedPartDir := filepath.Join(t.TempDir(), "shared_parts", "shared_part_1")
+	if err := os.MkdirAll(sharedPartDir, 0755); err != nil {
+		t.Fatalf("Failed to create shared part dir: %v", err)
+	}
+	config := `{"name": "shared_part_1", "used_in": [{"type": "reconciliationText", "handle": "rt_1", "id": {"1001": 5}}]}`
+	if err := os.WriteFile(filepath.Join(sharedPartDir, "config.json"), []byte(config), 0644); err != nil {
+		t.Fatalf("Failed to write config.json: %v", err)
+	}
+
+	logPath := filepath.Join(t.TempDir(), "calls.log")
+	script := filepath.Join(t.TempDir(), "silverfin")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\necho \"$@\" >> "+logPath+"\n"), 0755); err != nil {
+		t.Fatalf("Failed to write fake CLI: %v", err)
+	}
+
+	app := New()
+	m := app.InitialModel()
+	app.cliRunner = &cli.Runner{Command: script}
+	m.Templates = []models.Template{
+		{Name: "rt_1", Category: "reconciliation_texts"},
+		{Name: "account_1", Category: "account_templates"},
+		app.templateManager.LoadTemplate(sharedPartDir, "shared_parts"),
+	}
+	m.FilteredTemplates = app.templateManager.FilterTemplates(m.Templates, "")
+	m.SelectedTemplate = 2
+	m.FirmID = "1001"
+	m.Width = 80
+	m.Height = 24
+	app.Model = m
+	app.buildSharedPartsMapping()
+
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}})
+	if !app.Model.ShowSharedPartPopup {
+		t.Fatalf("Expected 's' to open the shared part popup")
+	}
+	if len(app.Model.SharedPartCandidates) != 2 || !app.Model.SharedPartLinks[m.Templates[0].ID()] || app.Model.SharedPartLinks[m.Templates[1].ID()] {
+		t.Fatalf("Expected rt_1 linked and account_1 unlinked, got %v", app.Model.SharedPartLinks)
+	}
+
+	// Unlink rt_1, link account_1 and sync with the CLI
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyDown})
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'c'}})
+	_, cmd := app.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	runCommands(app, cmd)
+
+	if app.Model.ShowSharedPartPopup {
+		t.Errorf("Expected popup to close after saving")
+	}
+	if usage := app.Model.SharedPartsUsage[m.Templates[1].ID()]; len(usage) != 1 || usage[0] != "shared_part_1" {
+		t.Errorf("Expected account_1 to use shared_part_1, got %v", usage)
+	}
+	if usage := app.Model.SharedPartsUsage[m.Templates[0].ID()]; len(usage) != 0 {
+		t.Errorf("Expected rt_1 to no longer use shared_part_1, got %v", usage)
+	}
+
+	data, _ := os.ReadFile(filepath.Join(sharedPartDir, "config.json"))
+	if !strings.Contains(string(data), `"type": "accountTemplate"`) || strings.Contains(string(data), `"rt_1"`) {
+		t.Errorf("Expected used_in to be rewritten, got %s", data)
+	}
+
+	calls, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("Expected CLI to be called: %v", err)
+	}
+	for _, expected := range []string{
+		"remove-shared-part --shared-part shared_part_1 --handle rt_1 --firm 1001",
+		"add-shared-part --shared-part shared_part_1 --account-template account_1 --firm 1001",
+	} {
+		if !strings.Contains(string(calls), expected) {
+			t.Errorf("Expected CLI call %q, got %s", expected, calls)
+		}
+	}
+}
+
+func newGraphTestApp(t *testing.T) *App {
+	t.Helper()
+	app := New()
+	m := app.InitialModel()
+	m.Templates = []models.Template{
+		{Name: "rt_1", Category: "reconciliation_texts", Path: t.TempDir()},
+		{Name: "shared_part_1", Category: "shared_parts", Path: t.TempDir(), Config: map[string]interface{}{
+			"used_in": []interface{}{map[string]interface{}{"type": "reconciliationText", "handle": "rt_1"}},
+		}},
+	}
+	m.FilteredTemplates = app.templateManager.FilterTemplates(m.Templates, "")
+	m.Width = 100
+	m.Height = 40
+	app.Model = m
+	app.buildSharedPartsMapping()
+	return app
+}
+
+func TestSharedPartUsedInJump(t *testing.T) {
+	app := newGraphTestApp(t)
+	app.Model.SelectedTemplate = 1
+	app.Model.CurrentSection = models.DetailsSection
+
+	// The shared part has no config fields: main file, then rt_1 in Used In
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyDown})
+	if app.Model.SelectedDetailField != 1 {
+		t.Fatalf("Expected the Used In entry to be selectable, got field %d", app.Model.SelectedDetailField)
+	}
+	if view := app.View(); !strings.Contains(view, "Used In:") {
+		t.Errorf("Expected the Details pane to list Used In")
+	}
+
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if selected, _ := app.Model.CurrentTemplate(); selected.Name != "rt_1" {
+		t.Errorf("Expected Enter to jump to rt_1, got %s", selected.ID())
+	}
+}
+
+func TestGraphScreen(t *testing.T) {
+	app := newGraphTestApp(t)
+	app.Model.CurrentSection = models.TemplatesSection
+
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'g'}})
+	if !app.Model.ShowGraph {
+		t.Fatalf("Expected 'g' to open the dependency graph")
+	}
+	if !strings.Contains(app.View(), "in used_in, not included in Liquid") {
+		t.Errorf("Expected the graph to flag the used_in-only link")
+	}
+
+	// Row 0 is rt_1, row 1 its shared part
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyDown})
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if app.Model.ShowGraph {
+		t.Errorf("Expected Enter to close the graph")
+	}
+	if selected, _ := app.Model.CurrentTemplate(); selected.Name != "shared_part_1" {
+		t.Errorf("Expected Enter to select shared_part_1, got %s", selected.ID())
+	}
+}
@@ -0,0 +1,222 @@
+package app
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/rufex/sftui/internal/models"
+	"github.com/rufex/sftui/internal/template"
+)
+
+// textPartTemplate returns the template shown in the Details section when it can have text parts.
+func (a *App) textPartTemplate() (models.Template, bool) {
+	if a.Model.CurrentSection != models.DetailsSection {
+		return models.Template{}, false
+	}
+	template, ok := a.currentTemplate()
+	if !ok || template.Category == "shared_parts" {
+		return models.Template{}, false
+	}
+	return template, true
+}
+
+// selectedTextPart returns the text part highlighted in the Details section.
+func (a *App) selectedTextPart(tmpl models.Template) (template.TextPart, bool) {
+	textPartIndex := a.Model.SelectedDetailField - a.GetConfigFieldCount(tmpl) - 1
+	partsList := a.templateManager.GetTextParts(tmpl)
+	if textPartIndex < 0 || textPartIndex >= len(partsList) {
+		return template.TextPart{}, false
+	}
+	a.Model.SelectedTextPart = textPartIndex
+	return partsList[textPartIndex], true
+}
+
+func (a *App) openTextPartPopup(action, name, partPath string) {
+	a.Model.ShowTextPartPopup = true
+	a.Model.TextPartPopupAction = action
+	a.Model.TextPartNameInput.SetValue(name)
+	a.Model.TextPartPathInput.SetValue(partPath)
+	a.Model.TextPartPathInput.Blur()
+	a.Model.TextPartNameInput.Focus()
+	a.Model.TextPartNameInput.CursorEnd()
+	a.Model.TextPartEditMode = "name"
+}
+
+func (a *App) handleAddTextPartKey() (tea.Model, tea.Cmd) {
+	if _, ok := a.textPartTemplate(); !ok {
+		return a, nil
+	}
+
+	a.openTextPartPopup("add", "", "text_parts/")
+	a.logInfo("Enter the name and path of the new text part")
+	return a, nil
+}
+
+func (a *App) handleDuplicateTextPartKey() (tea.Model, tea.Cmd) {
+	tmpl, ok := a.textPartTemplate()
+	if !ok {
+		return a, nil
+	}
+	part, ok := a.selectedTextPart(tmpl)
+	if !ok {
+		return a, nil
+	}
+
+	name := part.Name + "_copy"
+	a.openTextPartPopup("duplicate", name, path.Join(path.Dir(part.Path), name+".liquid"))
+	a.logInfo("Enter the name and path of the copy of %s", part.Name)
+	return a, nil
+}
+
+func (a *App) handleDeleteTextPartKey() (tea.Model, tea.Cmd) {
+	tmpl, ok := a.textPartTemplate()
+	if !ok {
+		return a, nil
+	}
+	part, ok := a.selectedTextPart(tmpl)
+	if !ok {
+		return a, nil
+	}
+
+	message := fmt.Sprintf("Delete text part %s and %s?", part.Name, part.Path)
+	includedFrom, err := a.configManager.TextPartIncludedFrom(tmpl.Path, part.Name)
+	if err != nil {
+		a.logWarn("Could not check includes of %s: %v", part.Name, err)
+	} else if len(includedFrom) > 0 {
+		message += fmt.Sprintf("\nWarning: still included from %s", strings.Join(includedFrom, ", "))
+	}
+
+	a.confirm(message, func() {
+		if err := a.configManager.DeleteTextPart(tmpl, part.Name); err != nil {
+			a.logConfigEditError(tmpl, "text part "+part.Name, err)
+			return
+		}
+		a.reloadTemplate(tmpl.Path, tmpl.Category)
+		a.clampDetailField()
+		if len(includedFrom) > 0 {
+			a.logWarn("Text part deleted: %s (still included from %s)", part.Name, strings.Join(includedFrom, ", "))
+		} else {
+			a.logInfo("Text part deleted: %s", part.Name)
+		}
+	})
+	return a, nil
+}
+
+// handleMoveTextPartKey moves the highlighted text part up (negative delta) or down in the
+// text_parts of config.json, keeping it highlighted.
+func (a *App) handleMoveTextPartKey(delta int) (tea.Model, tea.Cmd) {
+	tmpl, ok := a.textPartTemplate()
+	if !ok {
+		return a, nil
+	}
+	part, ok := a.selectedTextPart(tmpl)
+	if !ok {
+		return a, nil
+	}
+
+	if err := a.configManager.MoveTextPart(tmpl, part.Name, delta); err != nil {
+		a.logConfigEditError(tmpl, "text part "+part.Name, err)
+		return a, nil
+	}
+	a.reloadTemplate(tmpl.Path, tmpl.Category)
+
+	if reloaded, ok := a.currentTemplate(); ok {
+		for i, moved := range a.templateManager.GetTextParts(reloaded) {
+			if moved.Name == part.Name {
+				a.Model.SelectedDetailField = a.GetConfigFieldCount(reloaded) + 1 + i
+				a.Model.SelectedTextPart = i
+			}
+		}
+	}
+	a.logInfo("Text part moved: %s", part.Name)
+	return a, nil
+}
+
+// submitTextPartPopup applies the name and path entered in the text part popup.
+func (a *App) submitTextPartPopup(name, partPath string) {
+	tmpl, ok := a.textPartTemplate()
+	if !ok {
+		return
+	}
+
+	switch a.Model.TextPartPopupAction {
+	case "add":
+		if err := a.configManager.AddTextPart(tmpl, name, partPath); err != nil {
+			a.logConfigEditError(tmpl, "text part "+name, err)
+			return
+		}
+		a.reloadTemplate(tmpl.Path, tmpl.Category)
+		a.logInfo("Text part added: %s (%s)", name, partPath)
+	case "duplicate":
+		part, ok := a.selectedTextPart(tmpl)
+		if !ok {
+			return
+		}
+		if err := a.configManager.DuplicateTextPart(tmpl, part.Name, name, partPath); err != nil {
+			a.logConfigEditError(tmpl, "text part "+name, err)
+			return
+		}
+		a.reloadTemplate(tmpl.Path, tmpl.Category)
+		a.logInfo("Text part duplicated: %s -> %s (%s)", part.Name, name, partPath)
+	default:
+		a.renameTextPart(tmpl, name, partPath)
+	}
+}
+
+func (a *App) renameTextPart(tmpl models.Template, newName, newPath string) {
+	partsList := a.templateManager.GetTextParts(tmpl)
+	if a.Model.SelectedTextPart < 0 || a.Model.SelectedTextPart >= len(partsList) {
+		return
+	}
+	oldPart := partsList[a.Model.SelectedTextPart]
+
+	updatedFiles, err := a.configManager.RenameTextPart(tmpl, oldPart.Name, newName, newPath)
+	if err != nil {
+		a.logConfigEditError(tmpl, "text part "+oldPart.Name, err)
+		return
+	}
+
+	a.reloadTemplate(tmpl.Path, tmpl.Category)
+	a.logInfo("Text part updated: %s (%s) -> %s (%s), %d files with updated includes", oldPart.Name, oldPart.Path, newName, newPath, updatedFiles)
+}
+
+// clampDetailField keeps the Details selection inside the fields of the selected template.
+func (a *App) clampDetailField() {
+	tmpl, ok := a.currentTemplate()
+	if !ok {
+		return
+	}
+	total := a.navHandler.GetConfigFieldCount(tmpl, a.Model.SharedPartsUsage)
+	if a.Model.SelectedDetailField >= total {
+		a.Model.SelectedDetailField = max(0, total-1)
+	}
+}
+
+// confirm shows the confirmation popup and runs action when the user accepts.
+func (a *App) confirm(message string, action func()) {
+	a.Model.ShowConfirmPopup = true
+	a.Model.ConfirmMessage = message
+	a.confirmAction = action
+}
+
+func (a *App) handleConfirmPopup(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "y", "Y", "enter":
+		action := a.confirmAction
+		a.Model.ShowConfirmPopup = false
+		a.Model.ConfirmMessage = ""
+		a.confirmAction = nil
+		if action != nil {
+			action()
+		}
+	case "n", "N", "esc":
+		a.Model.ShowConfirmPopup = false
+		a.Model.ConfirmMessage = ""
+		a.confirmAction = nil
+		a.logInfo("Cancelled")
+	}
+	return a, nil
+}
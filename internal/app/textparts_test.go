@@ -0,0 +1,183 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestRenameKeyOpensTextPartPopup(t *testing.T) {
+	app := newPreviewTestApp(t)
+
+	app.Model.SelectedDetailField = 2
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'r'}})
+
+	if !app.Model.ShowTextPartPopup {
+		t.Fatalf("Expected 'r' to open the text part popup")
+	}
+	if app.Model.TextPartNameInput.Value() != "part_1" {
+		t.Errorf("Expected text part name part_1, got %s", app.Model.TextPartNameInput.Value())
+	}
+}
+
+func TestTextPartPopupRenamesOnDisk(t *testing.T) {
+	app := newPreviewTestApp(t)
+	dir := writeTextPartTestConfig(t, app)
+
+	app.Model.SelectedDetailField = 2
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'r'}})
+	app.Model.TextPartNameInput.SetValue("intro")
+	app.Model.TextPartPathInput.SetValue("text_parts/intro.liquid")
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	if app.Model.ShowTextPartPopup {
+		t.Errorf("Expected popup to close after Enter")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "text_parts", "intro.liquid")); err != nil {
+		t.Fatalf("Expected text part to be moved, output: %s", app.Model.Output)
+	}
+	parts := app.templateManager.GetTextParts(app.Model.Templates[0])
+	if len(parts) != 1 || parts[0].Name != "intro" {
+		t.Errorf("Expected reloaded template to have text part intro, got %v", parts)
+	}
+	mainSource, _ := os.ReadFile(filepath.Join(dir, "main.liquid"))
+	if !strings.Contains(string(mainSource), `{% include "parts/intro" %}`) {
+		t.Errorf("Expected include to be rewritten, got %s", mainSource)
+	}
+}
+
+func writeTextPartTestConfig(t *testing.T, app *App) string {
+	t.Helper()
+	dir := app.Model.Templates[0].Path
+	config := `{"reconciliation_type": "only_reconciled_with_data", "text": "main.liquid", "text_parts": {"part_1": "text_parts/part_1.liquid"}}`
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(config), 0644); err != nil {
+		t.Fatalf("Failed to write config.json: %v", err)
+	}
+	app.reloadTemplate(dir, app.Model.Templates[0].Category)
+	return dir
+}
+
+func TestAddTextPart(t *testing.T) {
+	app := newPreviewTestApp(t)
+	dir := writeTextPartTestConfig(t, app)
+
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'a'}})
+	if !app.Model.ShowTextPartPopup || app.Model.TextPartPopupAction != "add" {
+		t.Fatalf("Expected 'a' to open the text part popup in add mode")
+	}
+	app.Model.TextPartNameInput.SetValue("footer")
+	app.Model.TextPartPathInput.SetValue("text_parts/footer.liquid")
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	if _, err := os.Stat(filepath.Join(dir, "text_parts", "footer.liquid")); err != nil {
+		t.Fatalf("Expected new text part file, output: %s", app.Model.Output)
+	}
+	if parts := app.templateManager.GetTextParts(app.Model.Templates[0]); len(parts) != 2 {
+		t.Errorf("Expected 2 text parts after adding, got %v", parts)
+	}
+}
+
+func TestAddTextPartKeepsConfigModifiedOnDisk(t *testing.T) {
+	app := newPreviewTestApp(t)
+	dir := writeTextPartTestConfig(t, app)
+
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'a'}})
+	edited := `{"text": "main.liquid", "text_parts": {"part_1": "text_parts/part_1.liquid", "notes": "text_parts/notes.liquid"}}`
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(edited), 0644); err != nil {
+		t.Fatal(err)
+	}
+	app.Model.TextPartNameInput.SetValue("footer")
+	app.Model.TextPartPathInput.SetValue("text_parts/footer.liquid")
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	if data, _ := os.ReadFile(filepath.Join(dir, "config.json")); string(data) != edited {
+		t.Errorf("Expected the change made on disk to be kept, got %s", data)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "text_parts", "footer.liquid")); !os.IsNotExist(err) {
+		t.Errorf("Expected no text part file to be created")
+	}
+	if last := app.Model.Log[len(app.Model.Log)-1]; !strings.Contains(last.Message, "changed on disk") {
+		t.Errorf("Expected the conflict to be logged, got %q", last.Message)
+	}
+	if parts := app.templateManager.GetTextParts(app.Model.Templates[0]); len(parts) != 2 {
+		t.Errorf("Expected the template to be reloaded with both text parts, got %v", parts)
+	}
+}
+
+func TestMoveTextPartKeepsItHighlighted(t *testing.T) {
+	app := newPreviewTestApp(t)
+	dir := app.Model.Templates[0].Path
+	config := `{"text": "main.liquid", "text_parts": {"part_1": "text_parts/part_1.liquid", "part_2": "text_parts/part_2.liquid"}}`
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	app.reloadTemplate(dir, "reconciliation_texts")
+
+	first := app.GetConfigFieldCount(app.Model.Templates[0]) + 1
+	app.Model.SelectedDetailField = first
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{']'}})
+
+	parts := app.templateManager.GetTextParts(app.Model.Templates[0])
+	if len(parts) != 2 || parts[0].Name != "part_2" || parts[1].Name != "part_1" {
+		t.Fatalf("Expected part_1 to move below part_2, got %v (%s)", parts, app.Model.Output)
+	}
+	if app.Model.SelectedDetailField != first+1 {
+		t.Errorf("Expected part_1 to stay highlighted, got field %d", app.Model.SelectedDetailField)
+	}
+
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'['}})
+	if parts := app.templateManager.GetTextParts(app.Model.Templates[0]); parts[0].Name != "part_1" {
+		t.Errorf("Expected '[' to move part_1 back up, got %v", parts)
+	}
+}
+
+func TestDuplicateTextPart(t *testing.T) {
+	app := newPreviewTestApp(t)
+	dir := writeTextPartTestConfig(t, app)
+
+	app.Model.SelectedDetailField = 2
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'c'}})
+	if app.Model.TextPartPathInput.Value() != "text_parts/part_1_copy.liquid" {
+		t.Errorf("Expected default copy path, got %s", app.Model.TextPartPathInput.Value())
+	}
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	content, err := os.ReadFile(filepath.Join(dir, "text_parts", "part_1_copy.liquid"))
+	if err != nil || string(content) != "part one\n" {
+		t.Errorf("Expected copied content, got %q (%v)", content, err)
+	}
+}
+
+func TestDeleteTextPartWarnsAndConfirms(t *testing.T) {
+	app := newPreviewTestApp(t)
+	dir := writeTextPartTestConfig(t, app)
+
+	app.Model.SelectedDetailField = 2
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}})
+	if !app.Model.ShowConfirmPopup {
+		t.Fatalf("Expected 'd' to ask for confirmation")
+	}
+	if !strings.Contains(app.Model.ConfirmMessage, "still included from main.liquid") {
+		t.Errorf("Expected include warning, got %s", app.Model.ConfirmMessage)
+	}
+
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if _, err := os.Stat(filepath.Join(dir, "text_parts", "part_1.liquid")); err != nil {
+		t.Fatalf("Expected cancel to keep the text part")
+	}
+
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}})
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+	if _, err := os.Stat(filepath.Join(dir, "text_parts", "part_1.liquid")); !os.IsNotExist(err) {
+		t.Errorf("Expected text part file to be deleted")
+	}
+	if parts := app.templateManager.GetTextParts(app.Model.Templates[0]); len(parts) != 0 {
+		t.Errorf("Expected no text parts after deleting, got %v", parts)
+	}
+	if app.Model.SelectedDetailField != 1 {
+		t.Errorf("Expected selection to move back to the main file, got %d", app.Model.SelectedDetailField)
+	}
+}
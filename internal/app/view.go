@@ -33,10 +33,54 @@ func (a *App) View() string {
 		return a.uiRenderer.TextPartPopupView(a.Model)
 	}
 
+	if a.Model.ShowConfirmPopup {
+		return a.uiRenderer.ConfirmPopupView(a.Model)
+	}
+
+	if a.Model.ShowNewTemplateWizard {
+		return a.uiRenderer.NewTemplateWizardView(a.Model)
+	}
+
+	if a.Model.ShowSharedPartPopup {
+		return a.uiRenderer.SharedPartPopupView(a.Model)
+	}
+
 	if a.Model.Height < 10 {
 		return "Terminal too small"
 	}
 
+	if a.Model.LogExpanded {
+		return a.expandedLogView()
+	}
+
+	if a.Model.ShowPreview {
+		return a.previewView()
+	}
+
+	if a.Model.ShowGraph {
+		return a.graphView()
+	}
+
+	if a.Model.ShowConsistency {
+		return a.consistencyView()
+	}
+
+	if a.Model.ShowProblems {
+		return a.problemsView()
+	}
+
+	if a.Model.ShowContentSearch {
+		return a.contentSearchView()
+	}
+
+	if a.Model.ShowFirmMatrix {
+		return a.firmMatrixView()
+	}
+
+	if a.Model.ShowHistory {
+		return a.historyView()
+	}
+
 	var searchBar string
 	searchBarHeight := 0
 	if a.Model.SearchMode {
@@ -47,14 +91,17 @@ func (a *App) View() string {
 			Padding(0, 1)
 
 		searchContent := fmt.Sprintf("Search: %s_", a.Model.SearchQuery)
+		if a.Model.SearchError != "" {
+			searchContent += "  " + models.CategoryStyle.Render(a.Model.SearchError+", matching as text")
+		}
 		searchBar = searchStyle.Width(a.Model.Width - 4).Render(searchContent)
 	}
 
 	topContentHeight := 1
-	outputContentHeight := 1
+	outputContentHeight := a.Model.OutputContentHeight()
 	statusHeight := 1
 
-	reservedLines := (4 * 2) + 4 + statusHeight + searchBarHeight
+	reservedLines := (4 * 2) + (outputContentHeight + 3) + statusHeight + searchBarHeight
 	availableContentHeight := a.Model.Height - reservedLines
 	if availableContentHeight < 1 {
 		availableContentHeight = 1
@@ -63,7 +110,11 @@ func (a *App) View() string {
 	halfWidth := (a.Model.Width - 6) / 2
 	fullWidth := a.Model.Width - 4
 
-	firmBox := a.uiRenderer.RenderSection(a.Model, models.FirmSection, "Firm", a.uiRenderer.FirmView(a.Model), halfWidth, topContentHeight)
+	firmTitle := "Firm"
+	if a.Model.FirmType == "partner" {
+		firmTitle = "Partner"
+	}
+	firmBox := a.uiRenderer.RenderSection(a.Model, models.FirmSection, firmTitle, a.uiRenderer.FirmView(a.Model), halfWidth, topContentHeight)
 	hostBox := a.uiRenderer.RenderSection(a.Model, models.HostSection, "Host", a.uiRenderer.HostView(a.Model), halfWidth, topContentHeight)
 	topRow := lipgloss.JoinHorizontal(lipgloss.Top, firmBox, hostBox)
 
@@ -73,18 +124,18 @@ func (a *App) View() string {
 	templateCount := len(a.Model.FilteredTemplates)
 	totalCount := len(a.Model.Templates)
 
-	if a.Model.SearchMode && a.Model.SearchQuery != "" {
-		if selectedCount > 0 {
-			templatesTitle = fmt.Sprintf("Templates (%d/%d) - %d selected", templateCount, totalCount, selectedCount)
-		} else {
-			templatesTitle = fmt.Sprintf("Templates (%d/%d)", templateCount, totalCount)
-		}
+	if a.Model.SearchQuery != "" {
+		templatesTitle = fmt.Sprintf("Templates (%d/%d)", templateCount, totalCount)
 	} else {
-		if selectedCount > 0 {
-			templatesTitle = fmt.Sprintf("Templates (%d) - %d selected", totalCount, selectedCount)
-		} else {
-			templatesTitle = fmt.Sprintf("Templates (%d)", totalCount)
-		}
+		templatesTitle = fmt.Sprintf("Templates (%d)", totalCount)
+	}
+	if a.Model.TemplateSort != models.SortByCategory {
+		templatesTitle += " by " + a.Model.TemplateSort.String()
+	} else if a.Model.SearchQuery != "" {
+		templatesTitle += " by relevance"
+	}
+	if selectedCount > 0 {
+		templatesTitle += fmt.Sprintf(" - %d selected", selectedCount)
 	}
 	templatesContent := a.uiRenderer.TemplatesViewWithHeightAndWidth(a.Model, availableContentHeight, halfWidth)
 	detailsContent := a.uiRenderer.DetailsViewWithHeightAndWidth(a.Model, availableContentHeight, halfWidth)
@@ -93,7 +144,7 @@ func (a *App) View() string {
 	detailsBox := a.uiRenderer.RenderSection(a.Model, models.DetailsSection, "Details", detailsContent, halfWidth, availableContentHeight)
 	mainRow := lipgloss.JoinHorizontal(lipgloss.Top, templatesBox, detailsBox)
 
-	outputBox := a.uiRenderer.RenderSection(a.Model, models.OutputSection, "Output", a.uiRenderer.OutputView(a.Model), fullWidth, outputContentHeight)
+	outputBox := a.uiRenderer.RenderSection(a.Model, models.OutputSection, a.uiRenderer.OutputTitle(a.Model), a.uiRenderer.OutputViewWithHeightAndWidth(a.Model, outputContentHeight, fullWidth), fullWidth, outputContentHeight)
 
 	statusBar := a.uiRenderer.StatusBarView(a.Model)
 
@@ -103,3 +154,83 @@ func (a *App) View() string {
 		return lipgloss.JoinVertical(lipgloss.Left, topRow, mainRow, outputBox, statusBar)
 	}
 }
+
+func (a *App) expandedLogView() string {
+	contentHeight := a.Model.FullScreenContentHeight()
+	fullWidth := a.Model.Width - 4
+
+	logContent := a.uiRenderer.LogView(a.Model, contentHeight, fullWidth)
+	logBox := a.uiRenderer.RenderSection(a.Model, models.OutputSection, a.uiRenderer.OutputTitle(a.Model), logContent, fullWidth, contentHeight)
+
+	return lipgloss.JoinVertical(lipgloss.Left, logBox, a.uiRenderer.StatusBarView(a.Model))
+}
+
+func (a *App) graphView() string {
+	contentHeight := a.Model.FullScreenContentHeight()
+	fullWidth := a.Model.Width - 4
+
+	graphContent := a.uiRenderer.GraphView(a.Model, contentHeight, fullWidth)
+	graphBox := a.uiRenderer.RenderSection(a.Model, a.Model.CurrentSection, a.uiRenderer.GraphTitle(a.Model), graphContent, fullWidth, contentHeight)
+
+	return lipgloss.JoinVertical(lipgloss.Left, graphBox, a.uiRenderer.StatusBarView(a.Model))
+}
+
+func (a *App) consistencyView() string {
+	contentHeight := a.Model.FullScreenContentHeight()
+	fullWidth := a.Model.Width - 4
+
+	issuesContent := a.uiRenderer.ConsistencyView(a.Model, contentHeight, fullWidth)
+	issuesBox := a.uiRenderer.RenderSection(a.Model, a.Model.CurrentSection, a.uiRenderer.ConsistencyTitle(a.Model), issuesContent, fullWidth, contentHeight)
+
+	return lipgloss.JoinVertical(lipgloss.Left, issuesBox, a.uiRenderer.StatusBarView(a.Model))
+}
+
+func (a *App) problemsView() string {
+	contentHeight := a.Model.FullScreenContentHeight()
+	fullWidth := a.Model.Width - 4
+
+	problemsContent := a.uiRenderer.ProblemsView(a.Model, contentHeight, fullWidth)
+	problemsBox := a.uiRenderer.RenderSection(a.Model, a.Model.CurrentSection, a.uiRenderer.ProblemsTitle(a.Model), problemsContent, fullWidth, contentHeight)
+
+	return lipgloss.JoinVertical(lipgloss.Left, problemsBox, a.uiRenderer.StatusBarView(a.Model))
+}
+
+func (a *App) contentSearchView() string {
+	contentHeight := a.Model.FullScreenContentHeight()
+	fullWidth := a.Model.Width - 4
+
+	resultsContent := a.uiRenderer.ContentSearchView(a.Model, contentHeight, fullWidth)
+	resultsBox := a.uiRenderer.RenderSection(a.Model, a.Model.CurrentSection, a.uiRenderer.ContentSearchTitle(a.Model), resultsContent, fullWidth, contentHeight)
+
+	return lipgloss.JoinVertical(lipgloss.Left, resultsBox, a.uiRenderer.StatusBarView(a.Model))
+}
+
+func (a *App) firmMatrixView() string {
+	contentHeight := a.Model.FullScreenContentHeight()
+	fullWidth := a.Model.Width - 4
+
+	matrixContent := a.uiRenderer.FirmMatrixView(a.Model, contentHeight, fullWidth)
+	matrixBox := a.uiRenderer.RenderSection(a.Model, a.Model.CurrentSection, a.uiRenderer.FirmMatrixTitle(a.Model), matrixContent, fullWidth, contentHeight)
+
+	return lipgloss.JoinVertical(lipgloss.Left, matrixBox, a.uiRenderer.StatusBarView(a.Model))
+}
+
+func (a *App) historyView() string {
+	contentHeight := a.Model.FullScreenContentHeight()
+	fullWidth := a.Model.Width - 4
+
+	historyContent := a.uiRenderer.HistoryView(a.Model, contentHeight, fullWidth)
+	historyBox := a.uiRenderer.RenderSection(a.Model, a.Model.CurrentSection, a.uiRenderer.HistoryTitle(a.Model), historyContent, fullWidth, contentHeight)
+
+	return lipgloss.JoinVertical(lipgloss.Left, historyBox, a.uiRenderer.StatusBarView(a.Model))
+}
+
+func (a *App) previewView() string {
+	contentHeight := a.Model.FullScreenContentHeight()
+	fullWidth := a.Model.Width - 4
+
+	previewContent := a.uiRenderer.PreviewView(a.Model, contentHeight, fullWidth)
+	previewBox := a.uiRenderer.RenderSection(a.Model, a.Model.CurrentSection, a.uiRenderer.PreviewTitle(a.Model), previewContent, fullWidth, contentHeight)
+
+	return lipgloss.JoinVertical(lipgloss.Left, previewBox, a.uiRenderer.StatusBarView(a.Model))
+}
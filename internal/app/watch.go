@@ -0,0 +1,115 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/rufex/sftui/internal/models"
+	"github.com/rufex/sftui/internal/template"
+	"github.com/rufex/sftui/internal/watcher"
+)
+
+// startWatcher watches the template repository and returns a command waiting for changes.
+func (a *App) startWatcher() tea.Cmd {
+	a.watcher = watcher.NewWatcher(a.templateManager.RootPath(), template.Categories)
+	cmd := a.watcher.Start(false)
+	if a.watcher.Polling() {
+		a.logWarn("File watching unavailable, polling templates for changes")
+	}
+	return cmd
+}
+
+// StopWatcher stops watching the template repository.
+func (a *App) StopWatcher() {
+	if a.watcher != nil {
+		a.watcher.Close()
+	}
+}
+
+func (a *App) handleTemplateChanges(msg watcher.ChangesMsg) (tea.Model, tea.Cmd) {
+	a.applyTemplateChanges(msg.Dirs)
+	if a.watcher == nil {
+		return a, nil
+	}
+	return a, a.watcher.Next()
+}
+
+// applyTemplateChanges reloads, adds or removes the templates of the changed directories while
+// keeping the cursor, the search filter and the multi-selection on the same templates.
+func (a *App) applyTemplateChanges(dirs []string) {
+	cursor := a.cursorTemplateID()
+
+	reloaded, added := 0, 0
+	removed := make(map[int]bool)
+	for _, dir := range dirs {
+		index := a.templateIndexByPath(dir)
+		_, err := os.Stat(filepath.Join(dir, "config.json"))
+		exists := err == nil
+
+		switch {
+		case exists && index >= 0:
+			a.reloadTemplate(a.Model.Templates[index].Path, a.Model.Templates[index].Category)
+			reloaded++
+		case exists:
+			loaded := a.templateManager.LoadTemplate(dir, filepath.Base(filepath.Dir(dir)))
+			a.Model.Templates = append(a.Model.Templates, loaded)
+			a.Model.LoadProblems = append(a.Model.LoadProblems, a.templateManager.TemplateProblems(loaded)...)
+			added++
+		case index >= 0:
+			removed[index] = true
+		}
+	}
+	if reloaded+added+len(removed) == 0 {
+		return
+	}
+
+	if len(removed) > 0 {
+		a.removeTemplates(removed)
+	}
+	a.buildSharedPartsMapping()
+	a.restoreSelection(cursor)
+	a.refreshPreview(dirs)
+
+	a.logInfo("Templates changed on disk: %d reloaded, %d added, %d removed", reloaded, added, len(removed))
+}
+
+// removeTemplates drops the templates at the given indices and their load problems.
+func (a *App) removeTemplates(removed map[int]bool) {
+	var templates []models.Template
+	for i, tmpl := range a.Model.Templates {
+		if removed[i] {
+			a.dropLoadProblems(tmpl.Path)
+			continue
+		}
+		templates = append(templates, tmpl)
+	}
+	a.Model.Templates = templates
+}
+
+// refreshPreview re-reads the previewed file when it belongs to a changed template.
+func (a *App) refreshPreview(dirs []string) {
+	if !a.Model.ShowPreview {
+		return
+	}
+	for _, dir := range dirs {
+		if strings.HasPrefix(filepath.Clean(a.Model.PreviewPath), filepath.Clean(dir)+string(filepath.Separator)) {
+			if data, err := os.ReadFile(a.Model.PreviewPath); err == nil {
+				a.Model.PreviewContent = string(data)
+				a.scrollPreview(0)
+			}
+			return
+		}
+	}
+}
+
+func (a *App) templateIndexByPath(dir string) int {
+	for i, tmpl := range a.Model.Templates {
+		if filepath.Clean(tmpl.Path) == filepath.Clean(dir) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,76 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rufex/sftui/internal/models"
+	"github.com/rufex/sftui/internal/watcher"
+)
+
+func TestTemplateChangesKeepSelection(t *testing.T) {
+	root := t.TempDir()
+	dir := func(name string) string { return filepath.Join(root, "account_templates", name) }
+	writeTemplate := func(name, config string) {
+		if err := os.MkdirAll(dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir(name), "config.json"), []byte(config), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir(name), "main.liquid"), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, name := range []string{"at_a", "at_b", "at_c"} {
+		writeTemplate(name, `{"text_parts": {}}`)
+	}
+
+	app := New()
+	m := app.InitialModel()
+	m.Templates = nil
+	for _, name := range []string{"at_a", "at_b", "at_c"} {
+		m.Templates = append(m.Templates, app.templateManager.LoadTemplate(dir(name), "account_templates"))
+	}
+	m.SearchQuery = "at_"
+	m.FilteredTemplates = app.templateManager.FilterTemplates(m.Templates, m.SearchQuery)
+	m.SelectedTemplates = map[models.TemplateID]bool{m.Templates[1].ID(): true, m.Templates[2].ID(): true}
+	m.SelectedTemplate = 2
+	m.Width = 120
+	m.Height = 30
+	app.Model = m
+
+	// at_a changes, at_b is removed and at_d is added
+	writeTemplate("at_a", `{"text_parts": {}, "published": true}`)
+	if err := os.RemoveAll(dir("at_b")); err != nil {
+		t.Fatal(err)
+	}
+	writeTemplate("at_d", `{"text_parts": {}}`)
+
+	_, cmd := app.Update(watcher.ChangesMsg{Dirs: []string{dir("at_a"), dir("at_b"), dir("at_d")}})
+	if cmd != nil {
+		t.Errorf("Expected no follow-up command without a watcher")
+	}
+
+	var names []string
+	for _, tmpl := range app.Model.Templates {
+		names = append(names, tmpl.Name)
+	}
+	if len(names) != 3 || names[0] != "at_a" || names[1] != "at_c" || names[2] != "at_d" {
+		t.Fatalf("Expected at_a, at_c, at_d, got %v", names)
+	}
+	if app.Model.Templates[0].Config["published"] != true {
+		t.Errorf("Expected at_a to be reloaded")
+	}
+	if len(app.Model.SelectedTemplates) != 1 || !app.Model.SelectedTemplates[app.Model.Templates[1].ID()] {
+		t.Errorf("Expected only at_c to stay selected, got %v", app.Model.SelectedTemplates)
+	}
+	if app.Model.SearchQuery != "at_" {
+		t.Errorf("Expected the filter to be kept, got %q", app.Model.SearchQuery)
+	}
+	if selected, _ := app.Model.CurrentTemplate(); selected.Name != "at_c" {
+		t.Errorf("Expected the cursor to stay on at_c, got %s", selected.Name)
+	}
+
+}
@@ -0,0 +1,159 @@
+package app
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/rufex/sftui/internal/models"
+	"github.com/rufex/sftui/internal/template"
+)
+
+func (a *App) openNewTemplateWizard() (tea.Model, tea.Cmd) {
+	inputs := make([]textinput.Model, 1+len(template.NameLanguages))
+	for i := range inputs {
+		inputs[i] = textinput.New()
+		inputs[i].CharLimit = 100
+		inputs[i].Width = 40
+	}
+	inputs[0].Placeholder = "Enter handle (e.g. balance_sheet_check)"
+	inputs[1].Placeholder = "Enter English name"
+	for i := 2; i < len(inputs); i++ {
+		inputs[i].Placeholder = "Defaults to the English name"
+	}
+
+	a.Model.ShowNewTemplateWizard = true
+	a.Model.NewTemplateStep = 0
+	a.Model.NewTemplateCategory = 0
+	a.Model.NewTemplateInputs = inputs
+	a.Model.NewTemplateFocus = 0
+	a.logInfo("Choose the category of the new template")
+	return a, nil
+}
+
+func (a *App) closeNewTemplateWizard() {
+	a.Model.ShowNewTemplateWizard = false
+	a.Model.NewTemplateStep = 0
+	a.Model.NewTemplateInputs = nil
+}
+
+func (a *App) handleNewTemplateWizard(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if a.Model.NewTemplateStep == 0 {
+		return a.handleNewTemplateCategory(msg)
+	}
+	return a.handleNewTemplateForm(msg)
+}
+
+func (a *App) handleNewTemplateCategory(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		a.closeNewTemplateWizard()
+		a.logInfo("New template cancelled")
+	case "up", "k":
+		if a.Model.NewTemplateCategory > 0 {
+			a.Model.NewTemplateCategory--
+		}
+	case "down", "j":
+		if a.Model.NewTemplateCategory < len(template.Categories)-1 {
+			a.Model.NewTemplateCategory++
+		}
+	case "enter":
+		a.Model.NewTemplateStep = 1
+		a.focusNewTemplateInput(0)
+		a.logInfo("Enter the handle and names of the new %s", a.templateManager.GetCategoryDisplayName(template.Categories[a.Model.NewTemplateCategory]))
+	}
+	return a, nil
+}
+
+func (a *App) handleNewTemplateForm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// Shared parts have no translated names, only the handle is asked for
+	inputCount := len(a.Model.NewTemplateInputs)
+	if template.Categories[a.Model.NewTemplateCategory] == "shared_parts" {
+		inputCount = 1
+	}
+
+	switch msg.String() {
+	case "esc":
+		a.Model.NewTemplateStep = 0
+		return a, nil
+	case "tab", "down":
+		a.focusNewTemplateInput((a.Model.NewTemplateFocus + 1) % inputCount)
+		return a, nil
+	case "shift+tab", "up":
+		a.focusNewTemplateInput((a.Model.NewTemplateFocus + inputCount - 1) % inputCount)
+		return a, nil
+	case "enter":
+		a.createNewTemplate()
+		return a, nil
+	default:
+		var cmd tea.Cmd
+		focus := a.Model.NewTemplateFocus
+		a.Model.NewTemplateInputs[focus], cmd = a.Model.NewTemplateInputs[focus].Update(msg)
+		return a, cmd
+	}
+}
+
+func (a *App) focusNewTemplateInput(index int) {
+	for i := range a.Model.NewTemplateInputs {
+		a.Model.NewTemplateInputs[i].Blur()
+	}
+	a.Model.NewTemplateFocus = index
+	a.Model.NewTemplateInputs[index].Focus()
+}
+
+// createNewTemplate scaffolds the template entered in the wizard and selects it in the list.
+func (a *App) createNewTemplate() {
+	spec := template.NewTemplate{
+		Category: template.Categories[a.Model.NewTemplateCategory],
+		Handle:   strings.TrimSpace(a.Model.NewTemplateInputs[0].Value()),
+		Names:    make(map[string]string),
+	}
+	if spec.Category != "shared_parts" {
+		for i, lang := range template.NameLanguages {
+			spec.Names[lang] = strings.TrimSpace(a.Model.NewTemplateInputs[i+1].Value())
+		}
+	}
+
+	templateDir, err := a.configManager.ScaffoldTemplate(a.templateManager.RootPath(), spec)
+	if err != nil {
+		// Keep the wizard open so the input can be corrected
+		a.logError("Error creating template: %v", err)
+		return
+	}
+	created := a.templateManager.LoadTemplate(templateDir, spec.Category)
+
+	a.closeNewTemplateWizard()
+	a.Model.Templates = append(a.Model.Templates, created)
+	a.buildSharedPartsMapping()
+	a.selectTemplate(created.ID())
+	a.logInfo("Created %s %s in %s", a.templateManager.GetCategoryDisplayName(spec.Category), spec.Handle, created.Path)
+}
+
+// selectTemplate moves the cursor to the template with the given ID, expanding its category
+// group and clearing the search filter when they hide the template.
+func (a *App) selectTemplate(id models.TemplateID) {
+	delete(a.Model.CollapsedCategories, id.Category)
+	a.applyFilter()
+	position := indexOf(a.Model.FilteredTemplates, id)
+	if position < 0 {
+		a.Model.SearchQuery = ""
+		a.applyFilter()
+		position = indexOf(a.Model.FilteredTemplates, id)
+	}
+
+	if position >= 0 {
+		a.Model.SelectedTemplate = position
+		a.Model.SelectedDetailField = 0
+		a.navHandler.AdjustScrolling(a.Model)
+	}
+}
+
+func indexOf(values []models.TemplateID, value models.TemplateID) int {
+	for i, v := range values {
+		if v == value {
+			return i
+		}
+	}
+	return -1
+}
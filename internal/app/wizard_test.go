@@ -0,0 +1,66 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/rufex/sftui/internal/models"
+)
+
+func TestNewTemplateWizard(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	app := New()
+	app.InitialModel()
+	app.Model.CurrentSection = models.TemplatesSection
+	app.Model.Width = 80
+	app.Model.Height = 24
+
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+	if !app.Model.ShowNewTemplateWizard {
+		t.Fatalf("Expected 'n' to open the new template wizard")
+	}
+
+	// Reconciliation texts are the second category
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyDown})
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if app.Model.NewTemplateStep != 1 {
+		t.Fatalf("Expected Enter to move to the handle step")
+	}
+
+	for _, r := range "my_rt" {
+		_, _ = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyTab})
+	for _, r := range "My RT" {
+		_, _ = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	if app.Model.ShowNewTemplateWizard {
+		t.Fatalf("Expected wizard to close after creating, output: %s", app.Model.Output)
+	}
+	if _, err := os.Stat(filepath.Join("reconciliation_texts", "my_rt", "tests", "my_rt_liquid_test.yml")); err != nil {
+		t.Errorf("Expected scaffolded test file: %v", err)
+	}
+
+	selected, _ := app.Model.CurrentTemplate()
+	if selected.Name != "my_rt" || selected.Config["name_en"] != "My RT" {
+		t.Errorf("Expected the new template to be selected, got %s (%v)", selected.Name, selected.Config["name_en"])
+	}
+
+	// Creating the template is recorded, so undo removes it again
+	if len(app.Model.History) != 1 {
+		t.Fatalf("Expected the new template to be recorded in the history, got %d edits", len(app.Model.History))
+	}
+	_, _ = app.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'u'}})
+	if _, err := os.Stat(filepath.Join("reconciliation_texts", "my_rt", "config.json")); !os.IsNotExist(err) {
+		t.Errorf("Expected undo to remove config.json, got %v", err)
+	}
+	if len(app.Model.Templates) != 0 {
+		t.Errorf("Expected undo to remove the template from the list, got %v", app.Model.Templates)
+	}
+}
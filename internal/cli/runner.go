@@ -0,0 +1,167 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/rufex/sftui/internal/models"
+)
+
+// DefaultCommand is the Silverfin CLI executable used when SFTUI_CLI is not set.
+const DefaultCommand = "silverfin"
+
+// Actions lists the template actions offered in the action popup, in display order.
+var Actions = []string{"create", "import", "update"}
+
+type Runner struct {
+	Command string
+	Dir     string
+}
+
+type Result struct {
+	Template models.Template
+	Args     []string
+	Output   string
+	Err      error
+}
+
+func NewRunner() *Runner {
+	command := os.Getenv("SFTUI_CLI")
+	if command == "" {
+		command = DefaultCommand
+	}
+	return &Runner{Command: command}
+}
+
+// BuildArgs maps an action on a template to the Silverfin CLI subcommand and flags. The target
+// is the firm or partner the command runs in.
+func (r *Runner) BuildArgs(action string, template models.Template, target models.FirmOption) ([]string, error) {
+	if !isAction(action) {
+		return nil, fmt.Errorf("unknown action %q", action)
+	}
+	scope, err := scopeArgs(target, template)
+	if err != nil {
+		return nil, err
+	}
+
+	var subcommand, flag string
+	switch template.Category {
+	case "reconciliation_texts":
+		subcommand, flag = "reconciliation", "--handle"
+	case "account_templates":
+		subcommand, flag = "account-template", "--name"
+	case "export_files":
+		subcommand, flag = "export-file", "--name"
+	case "shared_parts":
+		subcommand, flag = "shared-part", "--shared-part"
+	default:
+		return nil, fmt.Errorf("unsupported template category %q", template.Category)
+	}
+
+	return append([]string{action + "-" + subcommand, flag, TemplateHandle(template)}, scope...), nil
+}
+
+// BuildSharedPartArgs maps linking (or unlinking) a shared part to a template onto the CLI's
+// add-shared-part/remove-shared-part commands.
+func (r *Runner) BuildSharedPartArgs(link bool, sharedPart, template models.Template, target models.FirmOption) ([]string, error) {
+	scope, err := scopeArgs(target, template)
+	if err != nil {
+		return nil, err
+	}
+
+	var flag string
+	switch template.Category {
+	case "reconciliation_texts":
+		flag = "--handle"
+	case "account_templates":
+		flag = "--account-template"
+	case "export_files":
+		flag = "--export-file"
+	default:
+		return nil, fmt.Errorf("unsupported template category %q", template.Category)
+	}
+
+	command := "add-shared-part"
+	if !link {
+		command = "remove-shared-part"
+	}
+	return append([]string{command, "--shared-part", TemplateHandle(sharedPart), flag, TemplateHandle(template)}, scope...), nil
+}
+
+// scopeArgs returns the flag selecting the firm or partner a command runs in. Partners only hold
+// reconciliation texts, account templates and shared parts.
+func scopeArgs(target models.FirmOption, template models.Template) ([]string, error) {
+	if target.ID == "" {
+		return nil, fmt.Errorf("no firm set")
+	}
+	if target.Type != "partner" {
+		return []string{"--firm", target.ID}, nil
+	}
+	if template.Category == "export_files" {
+		return nil, fmt.Errorf("export files are not available in partner %s", target.ID)
+	}
+	return []string{"--partner", target.ID}, nil
+}
+
+// RunSharedPart links or unlinks a shared part and a template in the firm or partner.
+func (r *Runner) RunSharedPart(link bool, sharedPart, template models.Template, target models.FirmOption) Result {
+	result := Result{Template: template}
+	result.Args, result.Err = r.BuildSharedPartArgs(link, sharedPart, template, target)
+	if result.Err == nil {
+		result.Output, result.Err = r.Run(result.Args)
+	}
+	return result
+}
+
+// Run executes the CLI with the given arguments and returns its combined output.
+func (r *Runner) Run(args []string) (string, error) {
+	cmd := exec.Command(r.Command, args...)
+	cmd.Dir = r.Dir
+	output, err := cmd.CombinedOutput()
+	return strings.TrimSpace(string(output)), err
+}
+
+// RunTemplate runs the action for a single template.
+func (r *Runner) RunTemplate(action string, template models.Template, target models.FirmOption) Result {
+	result := Result{Template: template}
+	result.Args, result.Err = r.BuildArgs(action, template, target)
+	if result.Err == nil {
+		result.Output, result.Err = r.Run(result.Args)
+	}
+	return result
+}
+
+// RunAction runs the action for every template and collects one result per template.
+func (r *Runner) RunAction(action string, templates []models.Template, target models.FirmOption) []Result {
+	results := make([]Result, 0, len(templates))
+	for _, template := range templates {
+		results = append(results, r.RunTemplate(action, template, target))
+	}
+	return results
+}
+
+// TemplateHandle returns the identifier the CLI expects for a template.
+func TemplateHandle(template models.Template) string {
+	if template.Category == "reconciliation_texts" {
+		if handle, ok := template.Config["handle"].(string); ok && handle != "" {
+			return handle
+		}
+	}
+	if template.Category == "shared_parts" {
+		if name, ok := template.Config["name"].(string); ok && name != "" {
+			return name
+		}
+	}
+	return template.Name
+}
+
+func isAction(action string) bool {
+	for _, a := range Actions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,173 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rufex/sftui/internal/models"
+)
+
+func fakeCLI(t *testing.T, body string) string {
+	t.Helper()
+	script := filepath.Join(t.TempDir(), "silverfin")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\n"+body+"\n"), 0755); err != nil {
+		t.Fatalf("Failed to write fake CLI: %v", err)
+	}
+	return script
+}
+
+var testFirm = models.FirmOption{ID: "1001", Name: "Demo", Type: "firm"}
+
+func TestBuildArgs(t *testing.T) {
+	runner := &Runner{Command: "silverfin"}
+
+	tests := []struct {
+		name     string
+		action   string
+		template models.Template
+		expected string
+	}{
+		{
+			name:     "reconciliation uses handle from config",
+			action:   "update",
+			template: models.Template{Name: "dir_name", Category: "reconciliation_texts", Config: map[string]interface{}{"handle": "rt_handle"}},
+			expected: "update-reconciliation --handle rt_handle --firm 1001",
+		},
+		{
+			name:     "account template uses name",
+			action:   "import",
+			template: models.Template{Name: "account_1", Category: "account_templates"},
+			expected: "import-account-template --name account_1 --firm 1001",
+		},
+		{
+			name:     "export file uses name",
+			action:   "create",
+			template: models.Template{Name: "export_1", Category: "export_files"},
+			expected: "create-export-file --name export_1 --firm 1001",
+		},
+		{
+			name:     "shared part uses shared-part flag",
+			action:   "update",
+			template: models.Template{Name: "shared_part_1", Category: "shared_parts"},
+			expected: "update-shared-part --shared-part shared_part_1 --firm 1001",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			args, err := runner.BuildArgs(test.action, test.template, testFirm)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if strings.Join(args, " ") != test.expected {
+				t.Errorf("Expected args %q, got %q", test.expected, strings.Join(args, " "))
+			}
+		})
+	}
+}
+
+func TestBuildArgsErrors(t *testing.T) {
+	runner := &Runner{Command: "silverfin"}
+	template := models.Template{Name: "account_1", Category: "account_templates"}
+
+	if _, err := runner.BuildArgs("delete", template, testFirm); err == nil {
+		t.Errorf("Expected error for unknown action")
+	}
+	if _, err := runner.BuildArgs("update", template, models.FirmOption{}); err == nil {
+		t.Errorf("Expected error when no firm is set")
+	}
+	if _, err := runner.BuildArgs("update", models.Template{Name: "x", Category: "other"}, testFirm); err == nil {
+		t.Errorf("Expected error for unknown category")
+	}
+}
+
+func TestBuildArgsForPartner(t *testing.T) {
+	runner := &Runner{Command: "silverfin"}
+	partner := models.FirmOption{ID: "25", Name: "Partner 1", Type: "partner"}
+	reconciliation := models.Template{Name: "dir_name", Category: "reconciliation_texts", Config: map[string]interface{}{"handle": "rt_handle"}}
+	sharedPart := models.Template{Name: "shared_part_1", Category: "shared_parts"}
+
+	args, err := runner.BuildArgs("update", reconciliation, partner)
+	if err != nil || strings.Join(args, " ") != "update-reconciliation --handle rt_handle --partner 25" {
+		t.Errorf("Expected a partner-scoped update, got %q, %v", strings.Join(args, " "), err)
+	}
+	args, err = runner.BuildSharedPartArgs(true, sharedPart, reconciliation, partner)
+	if err != nil || strings.Join(args, " ") != "add-shared-part --shared-part shared_part_1 --handle rt_handle --partner 25" {
+		t.Errorf("Expected a partner-scoped link, got %q, %v", strings.Join(args, " "), err)
+	}
+	if _, err := runner.BuildArgs("import", models.Template{Name: "export_1", Category: "export_files"}, partner); err == nil {
+		t.Errorf("Expected error for an export file in a partner")
+	}
+}
+
+func TestBuildSharedPartArgs(t *testing.T) {
+	runner := &Runner{Command: "silverfin"}
+	sharedPart := models.Template{Name: "shared_part_1", Category: "shared_parts"}
+
+	tests := []struct {
+		link     bool
+		template models.Template
+		expected string
+	}{
+		{true, models.Template{Name: "rt_dir", Category: "reconciliation_texts", Config: map[string]interface{}{"handle": "rt_1"}}, "add-shared-part --shared-part shared_part_1 --handle rt_1 --firm 1001"},
+		{false, models.Template{Name: "account_1", Category: "account_templates"}, "remove-shared-part --shared-part shared_part_1 --account-template account_1 --firm 1001"},
+		{true, models.Template{Name: "export_1", Category: "export_files"}, "add-shared-part --shared-part shared_part_1 --export-file export_1 --firm 1001"},
+	}
+
+	for _, test := range tests {
+		args, err := runner.BuildSharedPartArgs(test.link, sharedPart, test.template, testFirm)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if strings.Join(args, " ") != test.expected {
+			t.Errorf("Expected args %q, got %q", test.expected, strings.Join(args, " "))
+		}
+	}
+
+	if _, err := runner.BuildSharedPartArgs(true, sharedPart, sharedPart, testFirm); err == nil {
+		t.Errorf("Expected error linking a shared part to a shared part")
+	}
+	if _, err := runner.BuildSharedPartArgs(true, sharedPart, models.Template{Name: "account_1", Category: "account_templates"}, models.FirmOption{}); err == nil {
+		t.Errorf("Expected error when no firm is set")
+	}
+}
+
+func TestRunActionWithFakeCLI(t *testing.T) {
+	runner := &Runner{Command: fakeCLI(t, `echo "$@"
+if [ "$3" = "broken" ]; then exit 1; fi`)}
+
+	templates := []models.Template{
+		{Name: "account_1", Category: "account_templates"},
+		{Name: "broken", Category: "export_files"},
+	}
+
+	results := runner.RunAction("update", templates, testFirm)
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+
+	if results[0].Err != nil {
+		t.Errorf("Expected first template to succeed, got %v", results[0].Err)
+	}
+	if results[0].Output != "update-account-template --name account_1 --firm 1001" {
+		t.Errorf("Unexpected CLI output: %q", results[0].Output)
+	}
+
+	if results[1].Err == nil {
+		t.Errorf("Expected second template to fail")
+	}
+}
+
+func TestNewRunnerUsesEnvironment(t *testing.T) {
+	t.Setenv("SFTUI_CLI", "/tmp/fake-silverfin")
+	if runner := NewRunner(); runner.Command != "/tmp/fake-silverfin" {
+		t.Errorf("Expected command from SFTUI_CLI, got %s", runner.Command)
+	}
+
+	t.Setenv("SFTUI_CLI", "")
+	if runner := NewRunner(); runner.Command != DefaultCommand {
+		t.Errorf("Expected default command %s, got %s", DefaultCommand, runner.Command)
+	}
+}
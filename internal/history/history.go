@@ -0,0 +1,204 @@
+package history
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/rufex/sftui/internal/models"
+	"github.com/rufex/sftui/internal/template"
+)
+
+// MaxEntries is the number of edits kept; older ones are dropped.
+const MaxEntries = 100
+
+var (
+	ErrNothingToUndo = errors.New("nothing to undo")
+	ErrNothingToRedo = errors.New("nothing to redo")
+	// ErrConflict is returned when a file changed since the edit, so undoing or redoing it would
+	// lose that change. Nothing is written in that case.
+	ErrConflict = errors.New("changed since the edit")
+)
+
+// History is the undo stack of the edits made through the ConfigManager. Entries before
+// Position are applied, the ones after it were undone and can be redone.
+type History struct {
+	Entries  []models.HistoryEntry `json:"entries"`
+	Position int                   `json:"position"`
+
+	path string
+}
+
+// PathFor returns the history file of the repository at root. It is kept in the user's config
+// directory, keyed by the absolute repository path, so the content of edited files never ends
+// up in the repository.
+func PathFor(root string) (string, error) {
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return "", err
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(abs))
+	return filepath.Join(dir, "sftui", "history", hex.EncodeToString(sum[:8])+".json"), nil
+}
+
+// New returns an empty history saved to path, or kept in memory only when path is "".
+func New(path string) *History {
+	return &History{path: path}
+}
+
+// Load reads the history saved at path. A missing file gives an empty history.
+func Load(path string) (*History, error) {
+	h := New(path)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return h, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, h); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if h.Position < 0 || h.Position > len(h.Entries) {
+		h.Position = len(h.Entries)
+	}
+	return h, nil
+}
+
+// Save writes the history to its file.
+func (h *History) Save() error {
+	if h.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(h.path), 0700); err != nil {
+		return err
+	}
+	return template.WriteFileAtomic(h.path, append(data, '\n'), 0600)
+}
+
+// Record adds an edit. Edits that were undone can no longer be redone afterwards.
+func (h *History) Record(entry models.HistoryEntry) {
+	h.Entries = append(h.Entries[:h.Position], entry)
+	if len(h.Entries) > MaxEntries {
+		h.Entries = h.Entries[len(h.Entries)-MaxEntries:]
+	}
+	h.Position = len(h.Entries)
+}
+
+// Undo restores the files of the last applied edit to their content before it.
+func (h *History) Undo() (models.HistoryEntry, error) {
+	if h.Position == 0 {
+		return models.HistoryEntry{}, ErrNothingToUndo
+	}
+	entry := h.Entries[h.Position-1]
+	if err := restore(entry, true); err != nil {
+		return entry, err
+	}
+	h.Position--
+	return entry, nil
+}
+
+// Redo applies the first undone edit again.
+func (h *History) Redo() (models.HistoryEntry, error) {
+	if h.Position == len(h.Entries) {
+		return models.HistoryEntry{}, ErrNothingToRedo
+	}
+	entry := h.Entries[h.Position]
+	if err := restore(entry, false); err != nil {
+		return entry, err
+	}
+	h.Position++
+	return entry, nil
+}
+
+// restore writes the content of the files before (undo) or after the edit, once every file is
+// checked to still hold the content on the other side of it. FieldsOnly files get their changed
+// keys set instead.
+func restore(entry models.HistoryEntry, undo bool) error {
+	for _, file := range entry.Files {
+		matches := false
+		if file.FieldsOnly {
+			var err error
+			if matches, err = template.MatchesFields(file.Path, fieldsOf(entry, file.Path), !undo); err != nil {
+				return err
+			}
+		} else {
+			expected := file.After
+			if !undo {
+				expected = file.Before
+			}
+			matches = sameContent(file.Path, expected)
+		}
+		if !matches {
+			return fmt.Errorf("%s %w", file.Path, ErrConflict)
+		}
+	}
+
+	for _, file := range entry.Files {
+		if file.FieldsOnly {
+			if err := template.SetFields(file.Path, fieldsOf(entry, file.Path), undo); err != nil {
+				return err
+			}
+			continue
+		}
+
+		content := file.Before
+		if !undo {
+			content = file.After
+		}
+		if err := writeContent(file.Path, content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fieldsOf returns the field changes of an edit in one file.
+func fieldsOf(entry models.HistoryEntry, path string) []models.FieldChange {
+	var changes []models.FieldChange
+	for _, change := range entry.Fields {
+		if change.File == path {
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+func sameContent(path string, content *string) bool {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return content == nil
+	}
+	return err == nil && content != nil && string(data) == *content
+}
+
+// writeContent writes content to path keeping its permissions, or removes it when content is nil.
+func writeContent(path string, content *string) error {
+	if content == nil {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return template.WriteFileAtomic(path, []byte(*content), mode)
+}
@@ -0,0 +1,230 @@
+package history
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rufex/sftui/internal/models"
+)
+
+func content(s string) *string {
+	return &s
+}
+
+func TestUndoRedo(t *testing.T) {
+	dir := t.TempDir()
+	config := filepath.Join(dir, "config.json")
+	part := filepath.Join(dir, "text_parts", "notes.liquid")
+	if err := os.WriteFile(config, []byte(`{"public": true}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(part), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(part, []byte("notes"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	h := New("")
+	h.Record(models.HistoryEntry{
+		Description: "Add text part notes",
+		Files: []models.FileChange{
+			{Path: config, Before: content(`{"public": false}`), After: content(`{"public": true}`)},
+			{Path: part, Before: nil, After: content("notes")},
+		},
+	})
+
+	if _, err := h.Redo(); !errors.Is(err, ErrNothingToRedo) {
+		t.Errorf("Redo() error = %v, want ErrNothingToRedo", err)
+	}
+
+	if _, err := h.Undo(); err != nil {
+		t.Fatalf("Undo() error = %v", err)
+	}
+	if data, _ := os.ReadFile(config); string(data) != `{"public": false}` {
+		t.Errorf("config after undo = %s", data)
+	}
+	if info, _ := os.Stat(config); info.Mode().Perm() != 0600 {
+		t.Errorf("config mode after undo = %v, want 0600", info.Mode().Perm())
+	}
+	if _, err := os.Stat(part); !os.IsNotExist(err) {
+		t.Errorf("Expected the added text part to be removed by undo, got %v", err)
+	}
+	if _, err := h.Undo(); !errors.Is(err, ErrNothingToUndo) {
+		t.Errorf("Undo() error = %v, want ErrNothingToUndo", err)
+	}
+
+	if _, err := h.Redo(); err != nil {
+		t.Fatalf("Redo() error = %v", err)
+	}
+	if data, _ := os.ReadFile(config); string(data) != `{"public": true}` {
+		t.Errorf("config after redo = %s", data)
+	}
+	if data, _ := os.ReadFile(part); string(data) != "notes" {
+		t.Errorf("text part after redo = %q, want %q", data, "notes")
+	}
+	if h.Position != 1 {
+		t.Errorf("Position = %d, want 1", h.Position)
+	}
+}
+
+func TestUndoConflict(t *testing.T) {
+	dir := t.TempDir()
+	config := filepath.Join(dir, "config.json")
+	part := filepath.Join(dir, "notes.liquid")
+	if err := os.WriteFile(config, []byte("edited elsewhere"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(part, []byte("new"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	h := New("")
+	h.Record(models.HistoryEntry{Files: []models.FileChange{
+		{Path: part, Before: nil, After: content("new")},
+		{Path: config, Before: content("old"), After: content("saved")},
+	}})
+
+	if _, err := h.Undo(); !errors.Is(err, ErrConflict) {
+		t.Fatalf("Undo() error = %v, want ErrConflict", err)
+	}
+	if _, err := os.Stat(part); err != nil {
+		t.Errorf("Expected no file to be touched on conflict, got %v", err)
+	}
+	if h.Position != 1 {
+		t.Errorf("Position = %d, want 1", h.Position)
+	}
+}
+
+func TestRecord(t *testing.T) {
+	tests := []struct {
+		name     string
+		recorded int
+		undone   int
+		expected int
+	}{
+		{name: "appends", recorded: 3, expected: 4},
+		{name: "drops undone edits", recorded: 3, undone: 2, expected: 2},
+		{name: "keeps the last MaxEntries", recorded: MaxEntries, expected: MaxEntries},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			h := New("")
+			for i := 0; i < test.recorded; i++ {
+				h.Record(models.HistoryEntry{})
+			}
+			h.Position -= test.undone
+			h.Record(models.HistoryEntry{Description: "last"})
+
+			if len(h.Entries) != test.expected || h.Position != test.expected {
+				t.Errorf("got %d entries at position %d, want %d", len(h.Entries), h.Position, test.expected)
+			}
+			if h.Entries[len(h.Entries)-1].Description != "last" {
+				t.Errorf("Expected the new edit to be last")
+			}
+		})
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".sftui", "history.json")
+
+	loaded, err := Load(path)
+	if err != nil || len(loaded.Entries) != 0 {
+		t.Fatalf("Load() of a missing file = %v, %v; want an empty history", loaded, err)
+	}
+
+	loaded.Record(models.HistoryEntry{
+		Description: "Set public of balance to true",
+		Fields:      []models.FieldChange{{File: "balance/config.json", Field: "public", Old: false, New: true}},
+	})
+	loaded.Record(models.HistoryEntry{Description: "Set host to x"})
+	loaded.Position = 1
+	if err := loaded.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	reloaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(reloaded.Entries) != 2 || reloaded.Position != 1 {
+		t.Errorf("reloaded %d entries at position %d, want 2 at 1", len(reloaded.Entries), reloaded.Position)
+	}
+	if field := reloaded.Entries[0].Fields[0]; field.Field != "public" || field.New != true {
+		t.Errorf("reloaded field change = %+v", field)
+	}
+}
+
+func TestUndoFieldsOnly(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"host": "https://new.example.com", "token": "refreshed"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	historyPath := filepath.Join(t.TempDir(), "history.json")
+	h := New(historyPath)
+	h.Record(models.HistoryEntry{
+		Description: "Set host to https://new.example.com",
+		Files:       []models.FileChange{{Path: path, FieldsOnly: true}},
+		Fields:      []models.FieldChange{{File: path, Field: "host", Old: "https://old.example.com", New: "https://new.example.com"}},
+	})
+	if err := h.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := h.Undo(); err != nil {
+		t.Fatalf("Undo() error = %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.Contains(string(data), `"host": "https://old.example.com"`) || !strings.Contains(string(data), `"token": "refreshed"`) {
+		t.Errorf("Expected only host to be set back, got %s", data)
+	}
+
+	// Redo conflicts once the key changed outside sftui
+	if err := os.WriteFile(path, []byte(`{"host": "https://other.example.com"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.Redo(); !errors.Is(err, ErrConflict) {
+		t.Errorf("Redo() error = %v, want ErrConflict", err)
+	}
+
+	saved, _ := os.ReadFile(historyPath)
+	if strings.Contains(string(saved), "token") {
+		t.Errorf("Expected the history not to hold the file content, got %s", saved)
+	}
+	if info, _ := os.Stat(historyPath); info.Mode().Perm() != 0600 {
+		t.Errorf("history mode = %v, want 0600", info.Mode().Perm())
+	}
+}
+
+func TestPathFor(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		t.Skip("no user config directory")
+	}
+	t.Chdir(t.TempDir())
+
+	repo, err := PathFor(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixtures, _ := PathFor(filepath.Join("fixtures", "market-repo"))
+	abs, _ := filepath.Abs(".")
+	same, _ := PathFor(abs)
+
+	if !strings.HasPrefix(repo, configDir) {
+		t.Errorf("PathFor() = %s, want a file in %s", repo, configDir)
+	}
+	if repo == fixtures {
+		t.Errorf("Expected different repositories to get different files, both got %s", repo)
+	}
+	if repo != same {
+		t.Errorf("Expected relative and absolute roots to match, got %s and %s", repo, same)
+	}
+}
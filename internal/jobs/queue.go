@@ -0,0 +1,112 @@
+package jobs
+
+import (
+	"context"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/rufex/sftui/internal/models"
+)
+
+// DefaultConcurrency is the number of jobs a queue runs at the same time.
+const DefaultConcurrency = 4
+
+type Job struct {
+	ID  int
+	Run func() (string, error)
+}
+
+// StatusMsg reports a job status change back into the Bubble Tea loop.
+type StatusMsg struct {
+	JobID  int
+	Status models.JobStatus
+	Output string
+	Err    error
+}
+
+// DoneMsg is sent once every job of the queue has finished or been cancelled.
+type DoneMsg struct{}
+
+type Queue struct {
+	concurrency int
+	msgs        chan tea.Msg
+	ctx         context.Context
+	cancel      context.CancelFunc
+}
+
+func NewQueue(concurrency int) *Queue {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Queue{
+		concurrency: concurrency,
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+}
+
+// Start runs the jobs in the background and returns a command waiting for the first message.
+func (q *Queue) Start(jobs []Job) tea.Cmd {
+	// Every job sends at most two messages, plus the final DoneMsg, so workers never block.
+	q.msgs = make(chan tea.Msg, 2*len(jobs)+1)
+
+	go func() {
+		sem := make(chan struct{}, q.concurrency)
+		var wg sync.WaitGroup
+
+		for _, job := range jobs {
+			select {
+			case <-q.ctx.Done():
+				q.msgs <- StatusMsg{JobID: job.ID, Status: models.JobCancelled}
+				continue
+			case sem <- struct{}{}:
+			}
+
+			// Cancellation may have raced with acquiring a slot
+			if q.ctx.Err() != nil {
+				<-sem
+				q.msgs <- StatusMsg{JobID: job.ID, Status: models.JobCancelled}
+				continue
+			}
+
+			q.msgs <- StatusMsg{JobID: job.ID, Status: models.JobRunning}
+			wg.Add(1)
+			go func(job Job) {
+				defer wg.Done()
+				defer func() { <-sem }()
+
+				output, err := job.Run()
+				status := models.JobSucceeded
+				if err != nil {
+					status = models.JobFailed
+				}
+				q.msgs <- StatusMsg{JobID: job.ID, Status: status, Output: output, Err: err}
+			}(job)
+		}
+
+		wg.Wait()
+		q.msgs <- DoneMsg{}
+		close(q.msgs)
+	}()
+
+	return q.Next()
+}
+
+// Next returns a command that waits for the next message from the queue.
+func (q *Queue) Next() tea.Cmd {
+	msgs := q.msgs
+	return func() tea.Msg {
+		msg, ok := <-msgs
+		if !ok {
+			return nil
+		}
+		return msg
+	}
+}
+
+// Cancel stops pending jobs. Jobs that are already running are left to finish.
+func (q *Queue) Cancel() {
+	q.cancel()
+}
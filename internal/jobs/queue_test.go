@@ -0,0 +1,116 @@
+package jobs
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/rufex/sftui/internal/models"
+)
+
+// drain executes the queue commands until DoneMsg and returns the final status of every job.
+func drain(t *testing.T, q *Queue, cmd tea.Cmd) map[int]models.JobStatus {
+	t.Helper()
+	statuses := make(map[int]models.JobStatus)
+	for {
+		switch msg := cmd().(type) {
+		case StatusMsg:
+			statuses[msg.JobID] = msg.Status
+		case DoneMsg:
+			return statuses
+		case nil:
+			t.Fatalf("Queue closed before DoneMsg")
+		}
+		cmd = q.Next()
+	}
+}
+
+func TestQueueRunsAllJobs(t *testing.T) {
+	q := NewQueue(2)
+
+	var jobs []Job
+	for i := 0; i < 5; i++ {
+		fail := i == 3
+		jobs = append(jobs, Job{ID: i, Run: func() (string, error) {
+			if fail {
+				return "", errors.New("boom")
+			}
+			return "ok", nil
+		}})
+	}
+
+	statuses := drain(t, q, q.Start(jobs))
+
+	for i := 0; i < 5; i++ {
+		expected := models.JobSucceeded
+		if i == 3 {
+			expected = models.JobFailed
+		}
+		if statuses[i] != expected {
+			t.Errorf("Expected job %d to be %s, got %s", i, expected, statuses[i])
+		}
+	}
+}
+
+func TestQueueBoundsConcurrency(t *testing.T) {
+	q := NewQueue(2)
+
+	var running, peak int32
+	var jobs []Job
+	for i := 0; i < 6; i++ {
+		jobs = append(jobs, Job{ID: i, Run: func() (string, error) {
+			current := atomic.AddInt32(&running, 1)
+			for {
+				observed := atomic.LoadInt32(&peak)
+				if current <= observed || atomic.CompareAndSwapInt32(&peak, observed, current) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			return "", nil
+		}})
+	}
+
+	drain(t, q, q.Start(jobs))
+
+	if peak > 2 {
+		t.Errorf("Expected at most 2 concurrent jobs, observed %d", peak)
+	}
+}
+
+func TestQueueCancelStopsPendingJobs(t *testing.T) {
+	q := NewQueue(1)
+
+	release := make(chan struct{})
+	var jobs []Job
+	for i := 0; i < 3; i++ {
+		jobs = append(jobs, Job{ID: i, Run: func() (string, error) {
+			<-release
+			return "", nil
+		}})
+	}
+
+	cmd := q.Start(jobs)
+
+	// Wait for the first job to start, then cancel while it still holds the only slot
+	if msg, ok := cmd().(StatusMsg); !ok || msg.Status != models.JobRunning {
+		t.Fatalf("Expected first message to report a running job, got %#v", msg)
+	}
+	q.Cancel()
+	close(release)
+
+	statuses := drain(t, q, q.Next())
+
+	if statuses[0] != models.JobSucceeded {
+		t.Errorf("Expected running job to finish, got %s", statuses[0])
+	}
+	for i := 1; i < 3; i++ {
+		if statuses[i] != models.JobCancelled {
+			t.Errorf("Expected pending job %d to be cancelled, got %s", i, statuses[i])
+		}
+	}
+}
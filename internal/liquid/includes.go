@@ -0,0 +1,67 @@
+package liquid
+
+import (
+	"regexp"
+	"strings"
+)
+
+type IncludeKind int
+
+const (
+	OtherInclude IncludeKind = iota
+	PartInclude
+	SharedInclude
+)
+
+// Include is an {% include "..." %} statement found in Liquid source.
+type Include struct {
+	Kind IncludeKind
+	Name string // text part or shared part name without the parts/ or shared/ prefix
+	Path string // included path as written
+	Line int    // 1-based source line
+}
+
+var includePattern = regexp.MustCompile(`\{%-?\s*include\s+["']([^"']+)["']`)
+
+// Includes returns the include statements of Liquid source in order of appearance. Includes
+// inside comments and raw blocks are ignored.
+func Includes(src string) []Include {
+	var includes []Include
+	line := 1
+	for _, token := range Tokenize(src) {
+		if token.Kind == Tag {
+			for _, loc := range includePattern.FindAllStringSubmatchIndex(token.Value, -1) {
+				path := token.Value[loc[2]:loc[3]]
+				include := Include{
+					Path: path,
+					Name: path,
+					Line: line + strings.Count(token.Value[:loc[0]], "\n"),
+				}
+				switch {
+				case strings.HasPrefix(path, "shared/"):
+					include.Kind = SharedInclude
+					include.Name = strings.TrimPrefix(path, "shared/")
+				case strings.HasPrefix(path, "parts/"):
+					include.Kind = PartInclude
+					include.Name = strings.TrimPrefix(path, "parts/")
+				}
+				includes = append(includes, include)
+			}
+		}
+		line += strings.Count(token.Value, "\n")
+	}
+	return includes
+}
+
+// SharedParts returns the distinct shared part names included by Liquid source.
+func SharedParts(src string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, include := range Includes(src) {
+		if include.Kind == SharedInclude && !seen[include.Name] {
+			seen[include.Name] = true
+			names = append(names, include.Name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,34 @@
+package liquid
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIncludes(t *testing.T) {
+	src := `{% comment %}{% include "shared/commented" %}{% endcomment %}
+{% include "parts/part_1" %}
+{%- include 'shared/shared_part_1' -%}{% include "shared/shared_part_2" %}
+{% raw %}{% include "shared/raw" %}{% endraw %}
+{% include "other" %}`
+
+	expected := []Include{
+		{Kind: PartInclude, Name: "part_1", Path: "parts/part_1", Line: 2},
+		{Kind: SharedInclude, Name: "shared_part_1", Path: "shared/shared_part_1", Line: 3},
+		{Kind: SharedInclude, Name: "shared_part_2", Path: "shared/shared_part_2", Line: 3},
+		{Kind: OtherInclude, Name: "other", Path: "other", Line: 5},
+	}
+
+	if result := Includes(src); !reflect.DeepEqual(result, expected) {
+		t.Errorf("Includes() = %+v, expected %+v", result, expected)
+	}
+}
+
+func TestSharedParts(t *testing.T) {
+	src := `{% include "shared/a" %}{% include "parts/p" %}
+{% include "shared/b" %}{% include "shared/a" %}`
+
+	if result := SharedParts(src); !reflect.DeepEqual(result, []string{"a", "b"}) {
+		t.Errorf("SharedParts() = %v, expected [a b]", result)
+	}
+}
@@ -0,0 +1,134 @@
+package liquid
+
+import (
+	"regexp"
+	"strings"
+)
+
+type TokenKind int
+
+const (
+	Text TokenKind = iota
+	Tag
+	Output
+	Comment
+)
+
+type Token struct {
+	Kind  TokenKind
+	Value string
+}
+
+var (
+	tagNamePattern    = regexp.MustCompile(`^\{%-?\s*(#|\w+)`)
+	endCommentPattern = regexp.MustCompile(`\{%-?\s*endcomment\s*-?%\}`)
+	endRawPattern     = regexp.MustCompile(`\{%-?\s*endraw\s*-?%\}`)
+)
+
+// Tokenize splits Liquid source into text, tag ({% %}), output ({{ }}) and comment tokens.
+// Unterminated delimiters run to the end of the source.
+func Tokenize(src string) []Token {
+	var tokens []Token
+	appendToken := func(kind TokenKind, value string) {
+		if value == "" {
+			return
+		}
+		// Merge adjacent tokens of the same kind to keep the token list small
+		if n := len(tokens); n > 0 && tokens[n-1].Kind == kind {
+			tokens[n-1].Value += value
+			return
+		}
+		tokens = append(tokens, Token{Kind: kind, Value: value})
+	}
+
+	for len(src) > 0 {
+		start := nextDelimiter(src)
+		if start < 0 {
+			appendToken(Text, src)
+			break
+		}
+		appendToken(Text, src[:start])
+		src = src[start:]
+
+		if strings.HasPrefix(src, "{{") {
+			end := closingIndex(src, "}}")
+			appendToken(Output, src[:end])
+			src = src[end:]
+			continue
+		}
+
+		end := closingIndex(src, "%}")
+		tag := src[:end]
+		name := ""
+		if match := tagNamePattern.FindStringSubmatch(tag); match != nil {
+			name = match[1]
+		}
+
+		switch name {
+		case "#":
+			appendToken(Comment, tag)
+			src = src[end:]
+		case "comment":
+			blockEnd := len(src)
+			if loc := endCommentPattern.FindStringIndex(src[end:]); loc != nil {
+				blockEnd = end + loc[1]
+			}
+			appendToken(Comment, src[:blockEnd])
+			src = src[blockEnd:]
+		case "raw":
+			appendToken(Tag, tag)
+			src = src[end:]
+			if loc := endRawPattern.FindStringIndex(src); loc != nil {
+				appendToken(Text, src[:loc[0]])
+				appendToken(Tag, src[loc[0]:loc[1]])
+				src = src[loc[1]:]
+			} else {
+				appendToken(Text, src)
+				src = ""
+			}
+		default:
+			appendToken(Tag, tag)
+			src = src[end:]
+		}
+	}
+
+	return tokens
+}
+
+// Lines splits tokens at newlines and returns the tokens of each source line.
+func Lines(tokens []Token) [][]Token {
+	lines := [][]Token{{}}
+	for _, token := range tokens {
+		parts := strings.Split(token.Value, "\n")
+		for i, part := range parts {
+			if i > 0 {
+				lines = append(lines, []Token{})
+			}
+			if part != "" {
+				current := len(lines) - 1
+				lines[current] = append(lines[current], Token{Kind: token.Kind, Value: part})
+			}
+		}
+	}
+	return lines
+}
+
+func nextDelimiter(src string) int {
+	tag := strings.Index(src, "{%")
+	output := strings.Index(src, "{{")
+	switch {
+	case tag < 0:
+		return output
+	case output < 0:
+		return tag
+	default:
+		return min(tag, output)
+	}
+}
+
+func closingIndex(src, closing string) int {
+	if idx := strings.Index(src[2:], closing); idx >= 0 {
+		return idx + 2 + len(closing)
+	}
+	return len(src)
+}
@@ -0,0 +1,93 @@
+package liquid
+
+import (
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		expected []Token
+	}{
+		{
+			name:     "plain text",
+			src:      "Hello",
+			expected: []Token{{Text, "Hello"}},
+		},
+		{
+			name: "output and tag",
+			src:  "Total: {{ total | currency }}{% if x %}yes{% endif %}",
+			expected: []Token{
+				{Text, "Total: "},
+				{Output, "{{ total | currency }}"},
+				{Tag, "{% if x %}"},
+				{Text, "yes"},
+				{Tag, "{% endif %}"},
+			},
+		},
+		{
+			name: "comment block spans tags",
+			src:  "a{% comment %}{% if x %}{% endcomment %}b",
+			expected: []Token{
+				{Text, "a"},
+				{Comment, "{% comment %}{% if x %}{% endcomment %}"},
+				{Text, "b"},
+			},
+		},
+		{
+			name: "inline comment with whitespace control",
+			src:  "{%- # note -%}x",
+			expected: []Token{
+				{Comment, "{%- # note -%}"},
+				{Text, "x"},
+			},
+		},
+		{
+			name: "raw keeps delimiters as text",
+			src:  "{% raw %}{{ x }}{% endraw %}",
+			expected: []Token{
+				{Tag, "{% raw %}"},
+				{Text, "{{ x }}"},
+				{Tag, "{% endraw %}"},
+			},
+		},
+		{
+			name:     "unterminated output",
+			src:      "{{ broken",
+			expected: []Token{{Output, "{{ broken"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tokens := Tokenize(test.src)
+			if len(tokens) != len(test.expected) {
+				t.Fatalf("Tokenize(%q) = %v, expected %v", test.src, tokens, test.expected)
+			}
+			for i := range tokens {
+				if tokens[i] != test.expected[i] {
+					t.Errorf("Token %d = %v, expected %v", i, tokens[i], test.expected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestLines(t *testing.T) {
+	lines := Lines(Tokenize("{% comment %}\nfirst\n{% endcomment %}\n{{ x }}"))
+
+	if len(lines) != 4 {
+		t.Fatalf("Expected 4 lines, got %d", len(lines))
+	}
+
+	for i := 0; i < 3; i++ {
+		if len(lines[i]) != 1 || lines[i][0].Kind != Comment {
+			t.Errorf("Expected line %d to be a single comment token, got %v", i, lines[i])
+		}
+	}
+
+	if len(lines[3]) != 1 || lines[3][0].Kind != Output {
+		t.Errorf("Expected last line to be an output token, got %v", lines[3])
+	}
+}
@@ -0,0 +1,72 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rufex/sftui/internal/models"
+)
+
+// DefaultPath is where the log file is written, relative to the repository root.
+var DefaultPath = filepath.Join(".sftui", "sftui.log")
+
+// FileSink appends log entries to a file.
+type FileSink struct {
+	file *os.File
+}
+
+// OpenFile opens the log file at path for appending. Its directory gets a .gitignore ignoring
+// everything in it, so logs written inside a template repository never end up in git.
+func OpenFile(path string) (*FileSink, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	gitignore := filepath.Join(filepath.Dir(path), ".gitignore")
+	if _, err := os.Stat(gitignore); os.IsNotExist(err) {
+		if err := os.WriteFile(gitignore, []byte("*\n"), 0644); err != nil {
+			return nil, err
+		}
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileSink{file: file}, nil
+}
+
+func (s *FileSink) Write(entry models.LogEntry) error {
+	_, err := fmt.Fprintf(s.file, "%s %s\n", entry.Time.Format("2006-01-02T15:04:05Z07:00"), Format(entry))
+	return err
+}
+
+func (s *FileSink) Close() error {
+	return s.file.Close()
+}
+
+// Format renders an entry without its timestamp, e.g. "ERROR [account_1] update failed".
+func Format(entry models.LogEntry) string {
+	if entry.Group == "" {
+		return fmt.Sprintf("%-5s %s", entry.Level, entry.Message)
+	}
+	return fmt.Sprintf("%-5s [%s] %s", entry.Level, entry.Group, entry.Message)
+}
+
+// Filter returns the indices of the entries matching the query (case-insensitive).
+func Filter(entries []models.LogEntry, query string) []int {
+	query = strings.ToLower(query)
+
+	var matches []int
+	for i, entry := range entries {
+		if query == "" ||
+			strings.Contains(strings.ToLower(entry.Message), query) ||
+			strings.Contains(strings.ToLower(entry.Group), query) ||
+			strings.Contains(strings.ToLower(entry.Level.String()), query) {
+			matches = append(matches, i)
+		}
+	}
+	return matches
+}
@@ -0,0 +1,87 @@
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rufex/sftui/internal/models"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		entry    models.LogEntry
+		expected string
+	}{
+		{models.LogEntry{Level: models.LogInfo, Message: "Ready"}, "INFO  Ready"},
+		{models.LogEntry{Level: models.LogError, Group: "account_1", Message: "update failed"}, "ERROR [account_1] update failed"},
+		{models.LogEntry{Level: models.LogWarn, Message: "cancelled"}, "WARN  cancelled"},
+	}
+
+	for _, test := range tests {
+		if result := Format(test.entry); result != test.expected {
+			t.Errorf("Format(%+v) = %q, expected %q", test.entry, result, test.expected)
+		}
+	}
+}
+
+func TestFilter(t *testing.T) {
+	entries := []models.LogEntry{
+		{Level: models.LogInfo, Message: "Ready"},
+		{Level: models.LogError, Group: "account_1", Message: "update failed"},
+		{Level: models.LogInfo, Group: "export_1", Message: "update succeeded"},
+	}
+
+	tests := []struct {
+		query    string
+		expected []int
+	}{
+		{"", []int{0, 1, 2}},
+		{"UPDATE", []int{1, 2}},
+		{"account", []int{1}},
+		{"error", []int{1}},
+		{"missing", nil},
+	}
+
+	for _, test := range tests {
+		result := Filter(entries, test.query)
+		if len(result) != len(test.expected) {
+			t.Errorf("Filter(%q) = %v, expected %v", test.query, result, test.expected)
+			continue
+		}
+		for i := range result {
+			if result[i] != test.expected[i] {
+				t.Errorf("Filter(%q) = %v, expected %v", test.query, result, test.expected)
+				break
+			}
+		}
+	}
+}
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".sftui", "sftui.log")
+
+	sink, err := OpenFile(path)
+	if err != nil {
+		t.Fatalf("Expected log file to open, got %v", err)
+	}
+
+	entry := models.LogEntry{Time: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), Level: models.LogWarn, Message: "careful"}
+	if err := sink.Write(entry); err != nil {
+		t.Fatalf("Expected write to succeed, got %v", err)
+	}
+	sink.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Expected log file to exist, got %v", err)
+	}
+	if strings.TrimSpace(string(data)) != "2024-01-02T03:04:05Z WARN  careful" {
+		t.Errorf("Unexpected log file contents: %q", string(data))
+	}
+	if ignore, _ := os.ReadFile(filepath.Join(filepath.Dir(path), ".gitignore")); string(ignore) != "*\n" {
+		t.Errorf("Expected the log directory to be ignored by git, got %q", ignore)
+	}
+}
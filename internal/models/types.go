@@ -1,6 +1,9 @@
 package models
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/lipgloss"
 )
@@ -16,11 +19,151 @@ const (
 )
 
 type Template struct {
+	Name       string
+	Path       string
+	Type       string
+	Category   string
+	Config     map[string]interface{}
+	ConfigData []byte            // config.json as loaded, to tell whether it changed on disk since
+	Validation []ValidationIssue // problems found in config.json when the template was loaded
+	ModTime    time.Time         // latest modification time of the files in the template directory
+}
+
+// TemplateID identifies a template by category and name (its directory, which is also its
+// handle) independently of its position in Model.Templates.
+type TemplateID struct {
+	Category string
 	Name     string
-	Path     string
-	Type     string
+}
+
+// String returns the category/name form used in messages.
+func (id TemplateID) String() string {
+	return id.Category + "/" + id.Name
+}
+
+// ID returns the stable identity of the template.
+func (t Template) ID() TemplateID {
+	return TemplateID{Category: t.Category, Name: t.Name}
+}
+
+// ValidationIssue is a problem with a config.json key. Severity is LogError for invalid or
+// missing required keys and LogWarn for questionable values.
+type ValidationIssue struct {
+	Field    string
+	Message  string
+	Severity LogLevel
+	Line     int // position of a JSON syntax error in config.json, 0 otherwise
+	Column   int
+}
+
+// ValidationCounts returns the number of validation errors and warnings of a template.
+func (t Template) ValidationCounts() (errors, warnings int) {
+	for _, issue := range t.Validation {
+		if issue.Severity == LogError {
+			errors++
+		} else {
+			warnings++
+		}
+	}
+	return errors, warnings
+}
+
+type JobStatus int
+
+const (
+	JobPending JobStatus = iota
+	JobRunning
+	JobSucceeded
+	JobFailed
+	JobCancelled
+)
+
+func (s JobStatus) String() string {
+	switch s {
+	case JobPending:
+		return "pending"
+	case JobRunning:
+		return "running"
+	case JobSucceeded:
+		return "succeeded"
+	case JobFailed:
+		return "failed"
+	case JobCancelled:
+		return "cancelled"
+	default:
+		return "unknown"
+	}
+}
+
+type Job struct {
+	ID       int
+	Label    string
 	Category string
-	Config   map[string]interface{}
+	Status   JobStatus
+	Output   string
+	Error    string
+}
+
+type LogLevel int
+
+const (
+	LogInfo LogLevel = iota
+	LogWarn
+	LogError
+)
+
+func (l LogLevel) String() string {
+	switch l {
+	case LogWarn:
+		return "WARN"
+	case LogError:
+		return "ERROR"
+	default:
+		return "INFO"
+	}
+}
+
+type LogEntry struct {
+	Time    time.Time
+	Level   LogLevel
+	Group   string // job or template the entry belongs to, empty for general messages
+	Message string
+}
+
+// FileChange is the content of a file before and after an edit. A nil content means the file
+// did not exist. Files holding secrets, like the Silverfin config, are FieldsOnly: their content
+// is not kept and the edit is undone by setting the keys of its FieldChanges back.
+type FileChange struct {
+	Path       string  `json:"path"`
+	Before     *string `json:"before,omitempty"`
+	After      *string `json:"after,omitempty"`
+	FieldsOnly bool    `json:"fields_only,omitempty"`
+}
+
+// FieldChange is a key of a JSON config file changed by an edit: a top-level Field, or Key
+// inside the Field object (such as a repository in defaultFirmIDs).
+type FieldChange struct {
+	File  string      `json:"file"`
+	Field string      `json:"field"`
+	Key   string      `json:"key,omitempty"`
+	Old   interface{} `json:"old"` // nil when the key was added
+	New   interface{} `json:"new"` // nil when the key was removed
+}
+
+// Name returns the changed key as Field or Field.Key.
+func (c FieldChange) Name() string {
+	if c.Key == "" {
+		return c.Field
+	}
+	return c.Field + "." + c.Key
+}
+
+// HistoryEntry is an edit made through the ConfigManager, which can be undone and redone.
+type HistoryEntry struct {
+	Time        time.Time     `json:"time"`
+	Description string        `json:"description"`
+	Files       []FileChange  `json:"files"`
+	Fields      []FieldChange `json:"fields"`
 }
 
 type SilverfinConfig struct {
@@ -29,6 +172,126 @@ type SilverfinConfig struct {
 	Firms          map[string]map[string]string `json:",inline"`
 }
 
+// TemplateSort is the order of the Templates section.
+type TemplateSort int
+
+const (
+	SortByCategory TemplateSort = iota // category order, then name (the order templates are loaded in)
+	SortByName
+	SortByHandle
+	SortByModified  // most recently modified first
+	SortByGitStatus // templates with uncommitted changes first
+)
+
+func (s TemplateSort) String() string {
+	switch s {
+	case SortByName:
+		return "name"
+	case SortByHandle:
+		return "handle"
+	case SortByModified:
+		return "modified"
+	case SortByGitStatus:
+		return "git status"
+	default:
+		return "category"
+	}
+}
+
+// TemplateRow is one line of the grouped Templates section: a category heading or a template.
+type TemplateRow struct {
+	Category  string // category of a heading, "" for template rows
+	Count     int    // templates of the category matching the search
+	Collapsed bool   // the templates of the category are hidden
+	Template  int    // position in FilteredTemplates, -1 for headings
+}
+
+// GraphRow is one line of the dependency graph screen.
+type GraphRow struct {
+	Prefix   string // tree branches drawn before the name
+	Category string // category of the template or shared part, "" for headings
+	Name     string
+	Note     string // explains a mismatch between used_in and the Liquid
+	Problem  bool   // true when the row shows a mismatch or a missing shared part
+}
+
+// ContentMatch is a line of a Liquid file found by the content search, with the lines around it.
+type ContentMatch struct {
+	Template TemplateID
+	Path     string // path of the Liquid file
+	File     string // Liquid file relative to the template directory
+	Line     int    // 1-based line number
+	Text     string
+	Before   string // previous line, "" on the first line
+	After    string // next line, "" on the last line
+}
+
+// ContentRow is one line of the content search screen: a template, one of its Liquid files, a
+// matching line or a line of context around it.
+type ContentRow struct {
+	Template TemplateID
+	File     string // "" on template rows
+	Line     int    // 0 on template and file rows
+	Text     string
+	Match    int // index in ContentMatches on matching lines, -1 otherwise
+}
+
+type ConsistencyKind int
+
+const (
+	OrphanLink        ConsistencyKind = iota // used_in lists a template that does not include the shared part
+	MissingLink                              // a template includes a shared part that does not list it in used_in
+	UnknownSharedPart                        // a template includes a shared part that does not exist
+	UnknownTemplate                          // used_in lists a template that does not exist
+)
+
+// ConsistencyIssue is a disagreement between shared part used_in metadata and Liquid includes.
+type ConsistencyIssue struct {
+	Kind       ConsistencyKind
+	Template   TemplateID
+	SharedPart string
+}
+
+// Message describes the issue for the consistency panel.
+func (i ConsistencyIssue) Message() string {
+	switch i.Kind {
+	case OrphanLink:
+		return fmt.Sprintf("%s lists %s in used_in, but its Liquid does not include it", i.SharedPart, i.Template)
+	case MissingLink:
+		return fmt.Sprintf("%s includes %s, but it is missing from used_in", i.Template, i.SharedPart)
+	case UnknownSharedPart:
+		return fmt.Sprintf("%s includes %s, which does not exist", i.Template, i.SharedPart)
+	case UnknownTemplate:
+		return fmt.Sprintf("%s lists %s in used_in, which does not exist", i.SharedPart, i.Template)
+	default:
+		return ""
+	}
+}
+
+// Fixable reports whether the issue can be fixed by rewriting used_in to match the Liquid.
+func (i ConsistencyIssue) Fixable() bool {
+	return i.Kind != UnknownSharedPart
+}
+
+type LoadProblemKind int
+
+const (
+	UnreadableDirectory LoadProblemKind = iota // a category or template directory could not be read
+	InvalidConfig                              // config.json could not be read or parsed
+	MissingLiquid                              // the main Liquid file of a template does not exist
+	MissingTextPart                            // a text part points at a file that does not exist
+)
+
+// LoadProblem is a problem found while discovering templates on disk.
+type LoadProblem struct {
+	Kind     LoadProblemKind
+	Path     string // file or directory the problem is about
+	Template string // directory of the affected template, empty when no template was loaded
+	Line     int    // position in config.json of invalid JSON, 0 otherwise
+	Column   int
+	Message  string
+}
+
 type FirmOption struct {
 	ID   string
 	Name string
@@ -38,12 +301,18 @@ type FirmOption struct {
 type Model struct {
 	CurrentSection              Section
 	Templates                   []Template
-	SelectedTemplate            int
+	SelectedTemplate            int // cursor position in FilteredTemplates
 	TemplatesOffset             int
-	SelectedTemplates           map[int]bool
+	SelectedTemplates           map[TemplateID]bool // multi-selection for bulk actions
 	SearchMode                  bool
 	SearchQuery                 string
-	FilteredTemplates           []int
+	SearchError                 string       // why SearchQuery does not parse as a query, "" when it does
+	FilteredTemplates           []TemplateID // templates shown in the Templates section, in display order
+	TemplateSort                TemplateSort
+	GroupTemplates              bool                  // true when templates are listed under category headings
+	CollapsedCategories         map[string]bool       // categories whose templates are hidden while grouped
+	TemplateRows                []TemplateRow         // lines of the grouped Templates section, nil when not grouped
+	TemplateGitStatus           map[TemplateID]string // git status code of templates with uncommitted changes
 	ShowActionPopup             bool
 	SelectedAction              int
 	ShowFirmPopup               bool
@@ -52,6 +321,9 @@ type Model struct {
 	ShowHostPopup               bool
 	HostTextInput               textinput.Model
 	Firm                        string
+	FirmID                      string // firm or partner of this session, used by the views and CLI invocations
+	FirmType                    string // "firm" or "partner", the Type of the FirmOption of FirmID
+	DefaultFirmID               string // firm saved in defaultFirmIDs for the repository
 	Host                        string
 	ShowHelp                    bool
 	Output                      string
@@ -64,13 +336,132 @@ type Model struct {
 	ShowTextPartPopup           bool
 	TextPartNameInput           textinput.Model
 	TextPartPathInput           textinput.Model
-	TextPartEditMode            string              // "name" or "path"
-	SharedPartsUsage            map[string][]string // maps template handle to shared part names
-	ShowInPlaceEdit             bool                // true when showing in-place edit for a config field
-	InPlaceEditField            string              // name of the field being edited in-place
-	InPlaceEditOptions          []string            // available options for the field
-	InPlaceEditOriginalValue    interface{}         // original value before editing (for revert on escape)
-	InPlaceEditSelectedIndex    int                 // currently selected option index
+	TextPartEditMode            string                  // "name" or "path"
+	TextPartPopupAction         string                  // "rename", "add" or "duplicate"
+	ShowConfirmPopup            bool                    // true while asking to confirm a destructive action
+	ShowNewTemplateWizard       bool                    // true while the new template wizard is open
+	NewTemplateStep             int                     // 0 while choosing the category, 1 while entering handle and names
+	NewTemplateCategory         int                     // index of the chosen category
+	NewTemplateInputs           []textinput.Model       // handle followed by one translated name per language
+	NewTemplateFocus            int                     // index of the focused input
+	ShowSharedPartPopup         bool                    // true while linking templates to a shared part
+	SharedPartTemplate          TemplateID              // shared part being linked
+	SharedPartCandidates        []TemplateID            // templates that can use the shared part
+	SharedPartLinks             map[TemplateID]bool     // pending used_in state
+	SelectedSharedPartCandidate int                     // cursor position in SharedPartCandidates
+	SharedPartPopupOffset       int                     // first visible candidate of the popup
+	SharedPartSyncCLI           bool                    // also run add-shared-part/remove-shared-part for the firm
+	ShowGraph                   bool                    // true when the dependency graph screen is open
+	GraphRows                   []GraphRow              // rows of the dependency graph screen
+	GraphSelected               int                     // highlighted row of the dependency graph
+	GraphOffset                 int                     // first visible row of the dependency graph
+	ShowConsistency             bool                    // true when the consistency panel is open
+	ConsistencyIssues           []ConsistencyIssue      // issues found by the last consistency check
+	SelectedIssue               int                     // highlighted issue of the consistency panel
+	ConsistencyOffset           int                     // first visible issue of the consistency panel
+	ShowProblems                bool                    // true when the load problems panel is open
+	LoadProblems                []LoadProblem           // problems found while loading templates
+	SelectedProblem             int                     // highlighted problem of the problems panel
+	ProblemsOffset              int                     // first visible problem of the problems panel
+	ShowContentSearch           bool                    // true when the content search screen is open
+	ContentSearchEditing        bool                    // true while typing the content search query
+	ContentSearchQuery          string                  // text searched for in the Liquid files
+	ContentSearchRunning        bool                    // true while files are being searched
+	ContentSearchFiles          int                     // files searched so far
+	ContentSearchTotal          int                     // files to search
+	ContentMatches              []ContentMatch          // matching lines, sorted by template, file and line
+	ContentRows                 []ContentRow            // rows of the content search screen
+	ContentSelected             int                     // highlighted row of the content search screen
+	ContentOffset               int                     // first visible row of the content search screen
+	ShowHistory                 bool                    // true when the edit history panel is open
+	History                     []HistoryEntry          // edits made through the ConfigManager, oldest first
+	HistoryPosition             int                     // number of History entries applied; later ones can be redone
+	SelectedHistory             int                     // highlighted entry of the history panel, an index of History
+	HistoryOffset               int                     // first visible row of the history panel
+	ShowFirmMatrix              bool                    // true when the firm matrix screen is open
+	FirmMatrixTemplates         []TemplateID            // rows of the firm matrix, ordered by category and name
+	FirmMatrixSelected          int                     // highlighted row of the firm matrix
+	FirmMatrixOffset            int                     // first visible row of the firm matrix
+	FirmMatrixColumn            int                     // highlighted column of the firm matrix, an index of FirmOptions
+	FirmMatrixColumnOffset      int                     // first visible column of the firm matrix
+	ConfirmMessage              string                  // question shown in the confirmation popup
+	SharedPartsUsage            map[TemplateID][]string // shared part names listing each template in used_in
+	ShowInPlaceEdit             bool                    // true when showing in-place edit for a config field
+	InPlaceEditField            string                  // name of the field being edited in-place
+	InPlaceEditOptions          []string                // available options for the field
+	InPlaceEditOriginalValue    interface{}             // original value before editing (for revert on escape)
+	InPlaceEditSelectedIndex    int                     // currently selected option index
+	Jobs                        []Job                   // background jobs of the current bulk action
+	JobsRunning                 bool                    // true while the job queue is processing
+	JobsAction                  string                  // name of the action the jobs belong to
+	Log                         []LogEntry              // append-only application log shown in the Output section
+	LogOffset                   int                     // number of log lines scrolled up from the newest entry
+	LogFileError                string                  // first error writing the log file, shown in the status bar
+	LogSearchMode               bool                    // true while typing a log search query
+	LogSearchQuery              string                  // filters the log to entries containing the query
+	LogExpanded                 bool                    // true when the log is shown full screen
+	ShowPreview                 bool                    // true when the Liquid source preview is open
+	PreviewTitle                string                  // template and file shown in the preview
+	PreviewPath                 string                  // path of the previewed file
+	PreviewContent              string                  // source of the previewed file
+	PreviewOffset               int                     // first visible line of the preview
+	PreviewSearchMode           bool                    // true while typing a preview search query
+	PreviewSearchQuery          string                  // text searched for in the preview
+	PreviewMatchLine            int                     // line of the current search match, -1 when none
+}
+
+// TemplateByID returns the loaded template with the given ID. The app keeps a template.Registry
+// for indexed lookups; views only resolve the few rows they draw.
+func (m *Model) TemplateByID(id TemplateID) (Template, bool) {
+	for _, template := range m.Templates {
+		if template.Category == id.Category && template.Name == id.Name {
+			return template, true
+		}
+	}
+	return Template{}, false
+}
+
+// CurrentTemplate returns the template under the cursor of the Templates section.
+func (m *Model) CurrentTemplate() (Template, bool) {
+	if m.SelectedTemplate < 0 || m.SelectedTemplate >= len(m.FilteredTemplates) {
+		return Template{}, false
+	}
+	return m.TemplateByID(m.FilteredTemplates[m.SelectedTemplate])
+}
+
+// TemplateLine returns the line of the Templates section showing the cursor.
+func (m *Model) TemplateLine() int {
+	if m.TemplateRows == nil {
+		return m.SelectedTemplate
+	}
+	for i, row := range m.TemplateRows {
+		if row.Category == "" && row.Template == m.SelectedTemplate {
+			return i
+		}
+	}
+	return 0
+}
+
+// TemplateLineCount returns the number of lines of the Templates section.
+func (m *Model) TemplateLineCount() int {
+	if m.TemplateRows == nil {
+		return len(m.FilteredTemplates)
+	}
+	return len(m.TemplateRows)
+}
+
+// FullScreenContentHeight returns the content height of a section that fills the screen above the status bar.
+func (m *Model) FullScreenContentHeight() int {
+	return max(1, m.Height-4)
+}
+
+// OutputContentHeight returns the number of lines available to the Output section.
+func (m *Model) OutputContentHeight() int {
+	logLines := 3
+	if len(m.Jobs) == 0 {
+		return logLines
+	}
+	return logLines + min(len(m.Jobs), 5)
 }
 
 var (
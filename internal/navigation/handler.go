@@ -1,7 +1,9 @@
 package navigation
 
 import (
+	"github.com/rufex/sftui/internal/logging"
 	"github.com/rufex/sftui/internal/models"
+	"github.com/rufex/sftui/internal/template"
 )
 
 type Handler struct{}
@@ -16,21 +18,26 @@ func (h *Handler) AdjustScrolling(m *models.Model) {
 	if m.SearchMode {
 		searchBarHeight = 3
 	}
-	reservedLines := (4 * 2) + 4 + 1 + searchBarHeight // top sections + output + status + search
+	reservedLines := (4 * 2) + (m.OutputContentHeight() + 3) + 1 + searchBarHeight // top sections + output + status + search
 	availableContentHeight := m.Height - reservedLines
 	if availableContentHeight < 1 {
 		availableContentHeight = 1
 	}
 
-	// Ensure selected template is visible
-	if m.SelectedTemplate < m.TemplatesOffset {
-		m.TemplatesOffset = m.SelectedTemplate
-	} else if m.SelectedTemplate >= m.TemplatesOffset+availableContentHeight {
-		m.TemplatesOffset = m.SelectedTemplate - availableContentHeight + 1
+	// Ensure selected template is visible, together with the heading of its group
+	line := m.TemplateLine()
+	top := line
+	if top > 0 && m.TemplateRows != nil && m.TemplateRows[top-1].Category != "" {
+		top--
+	}
+	if top < m.TemplatesOffset {
+		m.TemplatesOffset = top
+	} else if line >= m.TemplatesOffset+availableContentHeight {
+		m.TemplatesOffset = line - availableContentHeight + 1
 	}
 
-	// Ensure offset is within bounds (use filtered templates count)
-	maxOffset := max(0, len(m.FilteredTemplates)-availableContentHeight)
+	// Ensure offset is within bounds (use the lines of the filtered templates)
+	maxOffset := max(0, m.TemplateLineCount()-availableContentHeight)
 	if m.TemplatesOffset > maxOffset {
 		m.TemplatesOffset = maxOffset
 	}
@@ -85,14 +92,35 @@ func (h *Handler) HandleTemplateNavigation(m *models.Model, direction string) {
 	}
 }
 
+// HandleOutputNavigation scrolls the log; the offset counts lines up from the newest entry.
+func (h *Handler) HandleOutputNavigation(m *models.Model, direction string) {
+	if m.CurrentSection != models.OutputSection {
+		return
+	}
+
+	entryCount := len(logging.Filter(m.Log, m.LogSearchQuery))
+
+	switch direction {
+	case "up":
+		if m.LogOffset < entryCount-1 {
+			m.LogOffset++
+		}
+	case "down":
+		if m.LogOffset > 0 {
+			m.LogOffset--
+		}
+	}
+}
+
 func (h *Handler) HandleDetailsNavigation(m *models.Model, direction string) {
 	if m.CurrentSection != models.DetailsSection || len(m.FilteredTemplates) == 0 {
 		return
 	}
 
-	// Get actual template index from filtered list
-	actualIndex := m.FilteredTemplates[m.SelectedTemplate]
-	template := m.Templates[actualIndex]
+	template, ok := m.CurrentTemplate()
+	if !ok {
+		return
+	}
 
 	// Count config fields (fixed keys for all template types)
 	configFieldCount := h.GetActualConfigFieldCount(template)
@@ -103,10 +131,8 @@ func (h *Handler) HandleDetailsNavigation(m *models.Model, direction string) {
 	// Count shared parts
 	sharedPartsCount := h.GetSharedPartsCount(template, m.SharedPartsUsage)
 
-	totalFieldCount := configFieldCount + textPartsCount + sharedPartsCount
-	if totalFieldCount == 0 {
-		return
-	}
+	// The main Liquid file is always listed between the config fields and the text parts
+	totalFieldCount := configFieldCount + 1 + textPartsCount + sharedPartsCount
 
 	switch direction {
 	case "up":
@@ -116,11 +142,12 @@ func (h *Handler) HandleDetailsNavigation(m *models.Model, direction string) {
 	}
 
 	// Update text part selection based on which field is selected
-	if m.SelectedDetailField >= configFieldCount {
+	textPartIndex := m.SelectedDetailField - configFieldCount - 1
+	if textPartIndex >= 0 && textPartIndex < textPartsCount {
 		// We're in the text parts section
-		m.SelectedTextPart = m.SelectedDetailField - configFieldCount
+		m.SelectedTextPart = textPartIndex
 	} else {
-		// We're in the config section
+		// We're in the config, main file or shared parts section
 		m.SelectedTextPart = -1 // No text part selected
 	}
 }
@@ -153,8 +180,9 @@ func (h *Handler) GetActualConfigFieldCount(template models.Template) int {
 	return count
 }
 
-func (h *Handler) GetConfigFieldCount(template models.Template, sharedPartsUsage map[string][]string) int {
-	count := h.GetActualConfigFieldCount(template)
+func (h *Handler) GetConfigFieldCount(template models.Template, sharedPartsUsage map[models.TemplateID][]string) int {
+	// Config fields plus the main Liquid file
+	count := h.GetActualConfigFieldCount(template) + 1
 
 	// Add text parts count (for templates that support them, excluding shared_parts)
 	if template.Category != "shared_parts" {
@@ -171,16 +199,14 @@ func (h *Handler) GetConfigFieldCount(template models.Template, sharedPartsUsage
 	return count
 }
 
-func (h *Handler) GetSharedPartsCount(template models.Template, sharedPartsUsage map[string][]string) int {
-	if template.Category == "shared_parts" {
-		return 0
+// GetSharedPartsCount returns the number of shared parts a template uses, or for a shared part
+// the number of templates using it.
+func (h *Handler) GetSharedPartsCount(tmpl models.Template, sharedPartsUsage map[models.TemplateID][]string) int {
+	if tmpl.Category == "shared_parts" {
+		return len(template.SharedPartUsers(sharedPartsUsage, tmpl.Name))
 	}
 
-	templateKey := template.Category + "/" + template.Name
-	if sharedParts, exists := sharedPartsUsage[templateKey]; exists {
-		return len(sharedParts)
-	}
-	return 0
+	return len(sharedPartsUsage[tmpl.ID()])
 }
 
 func (h *Handler) GetTextPartsCount(template models.Template) int {
@@ -201,9 +227,10 @@ func (h *Handler) HandleTextPartNavigation(m *models.Model, direction string) {
 		return
 	}
 
-	// Get actual template index from filtered list
-	actualIndex := m.FilteredTemplates[m.SelectedTemplate]
-	template := m.Templates[actualIndex]
+	template, ok := m.CurrentTemplate()
+	if !ok {
+		return
+	}
 
 	textPartsCount := h.GetTextPartsCount(template)
 	if textPartsCount == 0 {
@@ -0,0 +1,147 @@
+package search
+
+import (
+	"context"
+	"os"
+	"strings"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/rufex/sftui/internal/models"
+)
+
+// DefaultConcurrency is the number of files a search reads at the same time.
+const DefaultConcurrency = 8
+
+// File is a Liquid file of a template to search.
+type File struct {
+	Template models.TemplateID
+	Path     string
+	Name     string // file relative to the template directory
+}
+
+// ResultMsg reports the matches found in one file. Files are reported as they are searched,
+// so results stream into the Bubble Tea loop while the search goes on.
+type ResultMsg struct {
+	Search  int // ID of the search the result belongs to
+	Matches []models.ContentMatch
+	Err     error
+}
+
+// DoneMsg is sent once every file was searched or the search was cancelled.
+type DoneMsg struct {
+	Search    int
+	Cancelled bool
+}
+
+// Search greps a set of files in the background.
+type Search struct {
+	id          int
+	concurrency int
+	msgs        chan tea.Msg
+	ctx         context.Context
+	cancel      context.CancelFunc
+}
+
+// New returns a search with the given ID, used to drop messages of searches that were replaced.
+func New(id int) *Search {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Search{
+		id:          id,
+		concurrency: DefaultConcurrency,
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+}
+
+// ID returns the ID the messages of the search carry.
+func (s *Search) ID() int {
+	return s.id
+}
+
+// Start searches the files for query in the background and returns a command waiting for the
+// first message.
+func (s *Search) Start(query string, files []File) tea.Cmd {
+	// One message per file plus the final DoneMsg, so workers never block
+	s.msgs = make(chan tea.Msg, len(files)+1)
+	work := make(chan File)
+
+	go func() {
+		var wg sync.WaitGroup
+		for i := 0; i < s.concurrency; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for file := range work {
+					matches, err := SearchFile(file, query)
+					s.msgs <- ResultMsg{Search: s.id, Matches: matches, Err: err}
+				}
+			}()
+		}
+
+	feed:
+		for _, file := range files {
+			select {
+			case <-s.ctx.Done():
+				break feed
+			case work <- file:
+			}
+		}
+		close(work)
+		wg.Wait()
+
+		s.msgs <- DoneMsg{Search: s.id, Cancelled: s.ctx.Err() != nil}
+		close(s.msgs)
+	}()
+
+	return s.Next()
+}
+
+// Next returns a command that waits for the next message of the search.
+func (s *Search) Next() tea.Cmd {
+	msgs := s.msgs
+	return func() tea.Msg {
+		msg, ok := <-msgs
+		if !ok {
+			return nil
+		}
+		return msg
+	}
+}
+
+// Cancel stops searching files that were not started yet.
+func (s *Search) Cancel() {
+	s.cancel()
+}
+
+// SearchFile returns the lines of a file containing query, ignoring case. Missing files have no
+// matches; load problems already report them.
+func SearchFile(file File, query string) ([]models.ContentMatch, error) {
+	data, err := os.ReadFile(file.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	query = strings.ToLower(query)
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+
+	var matches []models.ContentMatch
+	for i, line := range lines {
+		if !strings.Contains(strings.ToLower(line), query) {
+			continue
+		}
+		match := models.ContentMatch{Template: file.Template, Path: file.Path, File: file.Name, Line: i + 1, Text: line}
+		if i > 0 {
+			match.Before = lines[i-1]
+		}
+		if i < len(lines)-1 {
+			match.After = lines[i+1]
+		}
+		matches = append(matches, match)
+	}
+	return matches, nil
+}
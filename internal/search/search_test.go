@@ -0,0 +1,101 @@
+package search
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rufex/sftui/internal/models"
+)
+
+func writeFile(t *testing.T, dir, name, content string) File {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return File{Template: models.TemplateID{Category: "reconciliation_texts", Name: name}, Path: path, Name: "main.liquid"}
+}
+
+func TestSearchFile(t *testing.T) {
+	file := writeFile(t, t.TempDir(), "rt", "{% assign a = custom.period.foo %}\r\nplain\n{{ CUSTOM.PERIOD.FOO }}")
+
+	matches, err := SearchFile(file, "custom.period.foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 2 {
+		t.Fatalf("Expected 2 matches, got %+v", matches)
+	}
+	if matches[0].Line != 1 || matches[0].Before != "" || matches[0].After != "plain" {
+		t.Errorf("Unexpected first match %+v", matches[0])
+	}
+	if matches[1].Line != 3 || matches[1].Before != "plain" || matches[1].After != "" {
+		t.Errorf("Unexpected second match %+v", matches[1])
+	}
+
+	if matches, err := SearchFile(File{Path: filepath.Join(t.TempDir(), "missing.liquid")}, "x"); err != nil || matches != nil {
+		t.Errorf("Expected a missing file to have no matches, got %v, %v", matches, err)
+	}
+}
+
+func TestSearchStreamsResults(t *testing.T) {
+	dir := t.TempDir()
+	var files []File
+	for _, name := range []string{"a", "b", "c", "d"} {
+		content := "nothing here"
+		if name != "c" {
+			content = "uses foo"
+		}
+		files = append(files, writeFile(t, dir, name, content))
+	}
+
+	s := New(7)
+	cmd := s.Start("foo", files)
+	results, matches := 0, 0
+	for {
+		msg := cmd()
+		if done, ok := msg.(DoneMsg); ok {
+			if done.Search != 7 || done.Cancelled {
+				t.Errorf("Unexpected done message %+v", done)
+			}
+			break
+		}
+		result, ok := msg.(ResultMsg)
+		if !ok {
+			t.Fatalf("Unexpected message %T", msg)
+		}
+		if result.Search != 7 {
+			t.Errorf("Expected results of search 7, got %d", result.Search)
+		}
+		results++
+		matches += len(result.Matches)
+		cmd = s.Next()
+	}
+
+	if results != 4 || matches != 3 {
+		t.Errorf("Expected 4 results with 3 matches, got %d with %d", results, matches)
+	}
+}
+
+func TestSearchCancel(t *testing.T) {
+	dir := t.TempDir()
+	var files []File
+	for i := 0; i < 100; i++ {
+		files = append(files, writeFile(t, dir, fmt.Sprintf("rt_%d", i), "foo"))
+	}
+
+	s := New(1)
+	s.Cancel()
+	cmd := s.Start("foo", files)
+	for {
+		if done, ok := cmd().(DoneMsg); ok {
+			if !done.Cancelled {
+				t.Errorf("Expected the search to report the cancellation")
+			}
+			return
+		}
+		cmd = s.Next()
+	}
+}
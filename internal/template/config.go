@@ -1,6 +1,7 @@
 package template
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -9,7 +10,10 @@ import (
 	"github.com/rufex/sftui/internal/models"
 )
 
-type ConfigManager struct{}
+type ConfigManager struct {
+	recorder func(models.HistoryEntry) // receives every edit, see SetRecorder
+	edit     *pendingEdit              // edit being recorded
+}
 
 func NewConfigManager() *ConfigManager {
 	return &ConfigManager{}
@@ -74,6 +78,31 @@ func (c *ConfigManager) LoadSilverfinConfig() (string, string, string) {
 	return firm, host, output
 }
 
+// LoadDefaultFirmID returns the firm ID configured for the current repository.
+func (c *ConfigManager) LoadDefaultFirmID() (string, error) {
+	configPath, err := c.getConfigPath()
+	if err != nil {
+		return "", err
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return "", err
+	}
+
+	var config models.SilverfinConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return "", err
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+
+	return config.DefaultFirmIDs[filepath.Base(cwd)], nil
+}
+
 func (c *ConfigManager) LoadFirmOptions() ([]models.FirmOption, error) {
 	configPath, err := c.getConfigPath()
 	if err != nil {
@@ -120,6 +149,7 @@ func (c *ConfigManager) LoadFirmOptions() ([]models.FirmOption, error) {
 		}
 	}
 
+	sortFirmOptions(firmOptions)
 	return firmOptions, nil
 }
 
@@ -129,16 +159,6 @@ func (c *ConfigManager) SetDefaultFirm(firmID string) error {
 		return err
 	}
 
-	data, err := os.ReadFile(configPath)
-	if err != nil {
-		return err
-	}
-
-	var rawConfig map[string]interface{}
-	if err := json.Unmarshal(data, &rawConfig); err != nil {
-		return err
-	}
-
 	// Get current working directory name
 	cwd, err := os.Getwd()
 	if err != nil {
@@ -147,22 +167,22 @@ func (c *ConfigManager) SetDefaultFirm(firmID string) error {
 
 	repoName := filepath.Base(cwd)
 
-	// Ensure defaultFirmIDs exists
-	if _, exists := rawConfig["defaultFirmIDs"]; !exists {
-		rawConfig["defaultFirmIDs"] = make(map[string]interface{})
-	}
-
-	// Set the default firm for this repository
-	defaultFirmIDs := rawConfig["defaultFirmIDs"].(map[string]interface{})
-	defaultFirmIDs[repoName] = firmID
-
-	// Write back to file
-	newData, err := json.MarshalIndent(rawConfig, "", "  ")
+	c.beginEdit(fmt.Sprintf("Set default firm of %s to %s", repoName, firmID))
+	defer c.endEdit()
+	file, err := c.loadSilverfinConfigFile(configPath)
 	if err != nil {
 		return err
 	}
 
-	return os.WriteFile(configPath, newData, 0644)
+	// Set the default firm for this repository, adding defaultFirmIDs when missing
+	defaultFirmIDs, ok := file.Values["defaultFirmIDs"].(map[string]interface{})
+	if !ok {
+		defaultFirmIDs = make(map[string]interface{})
+		file.Values["defaultFirmIDs"] = defaultFirmIDs
+	}
+	defaultFirmIDs[repoName] = firmID
+
+	return file.save()
 }
 
 func (c *ConfigManager) SetHost(host string) error {
@@ -171,76 +191,97 @@ func (c *ConfigManager) SetHost(host string) error {
 		return err
 	}
 
-	data, err := os.ReadFile(configPath)
+	c.beginEdit("Set host to " + host)
+	defer c.endEdit()
+	file, err := c.loadSilverfinConfigFile(configPath)
 	if err != nil {
 		return err
 	}
 
-	var rawConfig map[string]interface{}
-	if err := json.Unmarshal(data, &rawConfig); err != nil {
-		return err
-	}
+	file.Values["host"] = host
+	return file.save()
+}
 
-	// Set the host
-	rawConfig["host"] = host
+func (c *ConfigManager) UpdateReconciliationType(template models.Template, reconciliationType string) error {
+	return c.UpdateConfigField(template, "reconciliation_type", reconciliationType)
+}
 
-	// Write back to file
-	newData, err := json.MarshalIndent(rawConfig, "", "  ")
+// UpdateConfigField sets a field in the config.json of a template. The edit is based on the
+// template as it was loaded, so it fails with ErrConfigModified when config.json changed on disk
+// since then.
+func (c *ConfigManager) UpdateConfigField(template models.Template, fieldName string, value interface{}) error {
+	c.beginEdit(fmt.Sprintf("Set %s of %s to %v", fieldName, filepath.Base(template.Path), value))
+	defer c.endEdit()
+	file, err := c.loadTemplateConfig(template)
 	if err != nil {
 		return err
 	}
 
-	return os.WriteFile(configPath, newData, 0644)
+	file.Values[fieldName] = value
+	return c.saveTemplateConfig(file)
 }
 
-func (c *ConfigManager) UpdateReconciliationType(templatePath, reconciliationType string) error {
-	configPath := filepath.Join(templatePath, "config.json")
-
-	// Read existing config
-	data, err := os.ReadFile(configPath)
+// loadTemplateConfig loads the config.json of a template for an update. It fails with
+// ErrConfigModified when the file changed on disk since the template was loaded, other than by
+// an earlier step of the open edit.
+func (c *ConfigManager) loadTemplateConfig(template models.Template) (*configFile, error) {
+	file, err := c.loadConfigFile(filepath.Join(template.Path, "config.json"))
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if c.edit != nil {
+		if written, ok := c.edit.written[file.path]; ok && bytes.Equal(file.data, written) {
+			return file, nil
+		}
 	}
+	if err := file.basedOn(template.ConfigData); err != nil {
+		return nil, err
+	}
+	return file, nil
+}
 
-	var config map[string]interface{}
-	if err := json.Unmarshal(data, &config); err != nil {
+// saveTemplateConfig saves a config loaded with loadTemplateConfig. Later steps of the open edit,
+// like the links of FixConsistency, can then build on it with the template they started from.
+func (c *ConfigManager) saveTemplateConfig(file *configFile) error {
+	if err := file.save(); err != nil {
 		return err
 	}
+	if c.edit != nil {
+		c.edit.written[file.path] = file.data
+	}
+	return nil
+}
 
-	// Update the reconciliation_type
-	config["reconciliation_type"] = reconciliationType
-
-	// Write back to file
-	newData, err := json.MarshalIndent(config, "", "  ")
+// loadSilverfinConfigFile loads the Silverfin config for an update. It holds the CLI credentials,
+// so the open edit records the keys changed in it rather than its content.
+func (c *ConfigManager) loadSilverfinConfigFile(path string) (*configFile, error) {
+	file, err := loadConfigFile(path)
 	if err != nil {
-		return err
+		return nil, err
 	}
-
-	return os.WriteFile(configPath, newData, 0644)
+	c.trackFields(file.path)
+	return file, nil
 }
 
-func (c *ConfigManager) UpdateConfigField(templatePath, fieldName string, value interface{}) error {
-	configPath := filepath.Join(templatePath, "config.json")
-
-	// Read existing config
-	data, err := os.ReadFile(configPath)
+// SilverfinConfigPath returns the Silverfin config file, with symlinks resolved like the paths
+// of recorded edits.
+func (c *ConfigManager) SilverfinConfigPath() (string, error) {
+	path, err := c.getConfigPath()
 	if err != nil {
-		return err
+		return "", err
 	}
-
-	var config map[string]interface{}
-	if err := json.Unmarshal(data, &config); err != nil {
-		return err
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
 	}
+	return path, nil
+}
 
-	// Update the field
-	config[fieldName] = value
-
-	// Write back to file
-	newData, err := json.MarshalIndent(config, "", "  ")
+// loadConfigFile loads a config file for an update, recording it in the open edit.
+func (c *ConfigManager) loadConfigFile(path string) (*configFile, error) {
+	file, err := loadConfigFile(path)
 	if err != nil {
-		return err
+		return nil, err
 	}
-
-	return os.WriteFile(configPath, newData, 0644)
+	c.track(file.path)
+	return file, nil
 }
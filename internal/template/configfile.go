@@ -0,0 +1,355 @@
+package template
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ErrConfigModified is returned when saving a config file that changed on disk after it was
+// loaded, so the update would overwrite someone else's change.
+var ErrConfigModified = errors.New("modified on disk since it was loaded")
+
+// configFile is a JSON config file loaded for an update. Values can be changed freely; saving
+// keeps the key order, indentation and trailing newline of the original, writes unchanged values
+// byte for byte and replaces the file atomically with the same permissions.
+type configFile struct {
+	Values map[string]interface{}
+
+	path  string
+	data  []byte
+	mode  os.FileMode
+	order map[string][]string // key order of top-level objects replacing the original, see orderKeys
+}
+
+func loadConfigFile(path string) (*configFile, error) {
+	// Write through symlinks instead of replacing them with a regular file
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	file := &configFile{path: path, data: data, mode: info.Mode().Perm()}
+	if err := json.Unmarshal(data, &file.Values); err != nil {
+		return nil, err
+	}
+	if file.Values == nil {
+		file.Values = make(map[string]interface{})
+	}
+	return file, nil
+}
+
+// basedOn fails with ErrConfigModified when the file does not hold data, the content an edit was
+// prepared from.
+func (f *configFile) basedOn(data []byte) error {
+	if !bytes.Equal(f.data, data) {
+		return fmt.Errorf("%s was %w", f.path, ErrConfigModified)
+	}
+	return nil
+}
+
+// orderKeys makes save write the keys of the object at field in the given order. Keys of the
+// object that are not listed follow, sorted.
+func (f *configFile) orderKeys(field string, keys []string) {
+	if f.order == nil {
+		f.order = make(map[string][]string)
+	}
+	f.order[field] = keys
+}
+
+// save writes the values back. It fails with ErrConfigModified when the file no longer holds
+// what was loaded.
+func (f *configFile) save() error {
+	data, err := formatJSON(f.data, f.Values, f.order)
+	if err != nil {
+		return err
+	}
+
+	current, err := os.ReadFile(f.path)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(current, f.data) {
+		return fmt.Errorf("%s was %w", f.path, ErrConfigModified)
+	}
+
+	if err := WriteFileAtomic(f.path, data, f.mode); err != nil {
+		return err
+	}
+	f.data = data
+	return nil
+}
+
+// WriteFileAtomic writes data to a temporary file next to path and renames it over path, so a
+// crash leaves either the old or the new content.
+func WriteFileAtomic(path string, data []byte, mode os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// jsonLayout records how a value was written in the original document.
+type jsonLayout struct {
+	raw    []byte      // original bytes of the value
+	value  interface{} // decoded raw, to tell whether the value changed
+	keys   []string    // object keys in their original order
+	fields map[string]*jsonLayout
+	items  []*jsonLayout
+	inline bool // non-empty container written on a single line
+}
+
+// formatJSON encodes value in the layout of the original document: unchanged values keep their
+// original bytes, object keys keep their order with new keys appended sorted, and changed
+// containers use the original indentation. order replaces the key order of top-level objects.
+func formatJSON(original []byte, value interface{}, order map[string][]string) ([]byte, error) {
+	// Round-trip through encoding/json so structs and typed slices become maps and slices
+	normalized, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var generic interface{}
+	if err := json.Unmarshal(normalized, &generic); err != nil {
+		return nil, err
+	}
+
+	var layout *jsonLayout
+	if len(bytes.TrimSpace(original)) > 0 {
+		decoder := json.NewDecoder(bytes.NewReader(original))
+		if layout, err = scanLayout(decoder, original); err != nil {
+			return nil, err
+		}
+	}
+
+	encoder := &jsonEncoder{indent: detectIndent(original), order: order}
+	if err := encoder.encode(generic, layout, 0, nil); err != nil {
+		return nil, err
+	}
+	if len(original) == 0 || bytes.HasSuffix(original, []byte("\n")) {
+		encoder.buf.WriteByte('\n')
+	}
+	return encoder.buf.Bytes(), nil
+}
+
+func scanLayout(decoder *json.Decoder, data []byte) (*jsonLayout, error) {
+	offset := int(decoder.InputOffset())
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	layout := &jsonLayout{}
+	switch token {
+	case json.Delim('{'):
+		layout.fields = make(map[string]*jsonLayout)
+		for decoder.More() {
+			key, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			child, err := scanLayout(decoder, data)
+			if err != nil {
+				return nil, err
+			}
+			layout.keys = append(layout.keys, key.(string))
+			layout.fields[key.(string)] = child
+		}
+	case json.Delim('['):
+		for decoder.More() {
+			child, err := scanLayout(decoder, data)
+			if err != nil {
+				return nil, err
+			}
+			layout.items = append(layout.items, child)
+		}
+	}
+	if delim, ok := token.(json.Delim); ok && (delim == '{' || delim == '[') {
+		if _, err := decoder.Token(); err != nil {
+			return nil, err
+		}
+	}
+
+	// The offset before a token includes the separator and whitespace in front of it
+	raw := bytes.TrimLeft(data[offset:decoder.InputOffset()], " \t\r\n,:")
+	layout.raw = raw
+	layout.inline = !bytes.ContainsAny(raw, "\n") && len(layout.keys)+len(layout.items) > 0
+	if err := json.Unmarshal(raw, &layout.value); err != nil {
+		return nil, err
+	}
+	return layout, nil
+}
+
+// detectIndent returns the indentation of the first indented line, two spaces by default.
+func detectIndent(data []byte) string {
+	for _, line := range strings.Split(string(data), "\n")[1:] {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed != "" && len(trimmed) < len(line) {
+			return line[:len(line)-len(trimmed)]
+		}
+	}
+	return "  "
+}
+
+type jsonEncoder struct {
+	buf    bytes.Buffer
+	indent string
+	order  map[string][]string
+}
+
+// encode writes value. keys, when set, is the order to write the keys of an object in.
+func (e *jsonEncoder) encode(value interface{}, layout *jsonLayout, depth int, keys []string) error {
+	// Objects compare equal whatever their key order, so a reordered one is never unchanged
+	reordered := keys != nil || (depth == 0 && len(e.order) > 0)
+	if layout != nil && !reordered && reflect.DeepEqual(layout.value, value) {
+		e.buf.Write(layout.raw)
+		return nil
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if len(v) == 0 {
+			e.buf.WriteString("{}")
+			return nil
+		}
+		var order []string
+		var fields map[string]*jsonLayout
+		inline := false
+		listed := make(map[string]bool)
+		if _, isObject := layoutValue(layout).(map[string]interface{}); isObject {
+			fields, inline = layout.fields, layout.inline
+			if keys == nil {
+				keys = layout.keys
+			}
+		}
+		for _, key := range keys {
+			if _, ok := v[key]; ok && !listed[key] {
+				listed[key] = true
+				order = append(order, key)
+			}
+		}
+		var added []string
+		for key := range v {
+			if !listed[key] {
+				added = append(added, key)
+			}
+		}
+		sort.Strings(added)
+		order = append(order, added...)
+
+		e.buf.WriteByte('{')
+		for i, key := range order {
+			e.separator(i, inline, depth+1)
+			if err := e.scalar(key); err != nil {
+				return err
+			}
+			e.buf.WriteString(": ")
+			var childKeys []string
+			if depth == 0 {
+				childKeys = e.order[key]
+			}
+			if err := e.encode(v[key], fields[key], depth+1, childKeys); err != nil {
+				return err
+			}
+		}
+		e.closing('}', inline, depth)
+	case []interface{}:
+		if len(v) == 0 {
+			e.buf.WriteString("[]")
+			return nil
+		}
+		var items []*jsonLayout
+		inline := false
+		if _, isArray := layoutValue(layout).([]interface{}); isArray {
+			items, inline = layout.items, layout.inline
+		}
+
+		e.buf.WriteByte('[')
+		for i, item := range v {
+			e.separator(i, inline, depth+1)
+			var itemLayout *jsonLayout
+			if i < len(items) {
+				itemLayout = items[i]
+			}
+			if err := e.encode(item, itemLayout, depth+1, nil); err != nil {
+				return err
+			}
+		}
+		e.closing(']', inline, depth)
+	default:
+		return e.scalar(v)
+	}
+	return nil
+}
+
+func layoutValue(layout *jsonLayout) interface{} {
+	if layout == nil {
+		return nil
+	}
+	return layout.value
+}
+
+func (e *jsonEncoder) separator(index int, inline bool, depth int) {
+	if index > 0 {
+		e.buf.WriteByte(',')
+	}
+	if inline {
+		if index > 0 {
+			e.buf.WriteByte(' ')
+		}
+		return
+	}
+	e.buf.WriteByte('\n')
+	e.buf.WriteString(strings.Repeat(e.indent, depth))
+}
+
+func (e *jsonEncoder) closing(delim byte, inline bool, depth int) {
+	if !inline {
+		e.buf.WriteByte('\n')
+		e.buf.WriteString(strings.Repeat(e.indent, depth))
+	}
+	e.buf.WriteByte(delim)
+}
+
+// scalar writes a string, number, bool or null without escaping <, > and &, which Liquid
+// snippets in configs use.
+func (e *jsonEncoder) scalar(value interface{}) error {
+	var out bytes.Buffer
+	encoder := json.NewEncoder(&out)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return err
+	}
+	e.buf.Write(bytes.TrimRight(out.Bytes(), "\n"))
+	return nil
+}
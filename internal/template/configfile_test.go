@@ -0,0 +1,169 @@
+package template
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFormatJSON(t *testing.T) {
+	original := `{
+    "name_en": "Balance <b>sheet</b>",
+    "handle": "balance",
+    "account_range": ["1", "2"],
+    "text_parts": {},
+    "id": {
+        "1001": 11
+    },
+    "amount": 1.50
+}
+`
+
+	tests := []struct {
+		name     string
+		update   func(values map[string]interface{})
+		expected string
+	}{
+		{
+			name:     "unchanged values are written byte for byte",
+			update:   func(values map[string]interface{}) {},
+			expected: original,
+		},
+		{
+			name: "changed and added keys keep order and indentation",
+			update: func(values map[string]interface{}) {
+				values["handle"] = "balance_sheet"
+				values["published"] = true
+				values["text_parts"] = map[string]interface{}{"notes": "text_parts/notes.liquid"}
+				values["account_range"] = []interface{}{"1", "2", "3"}
+				delete(values, "amount")
+			},
+			expected: `{
+    "name_en": "Balance <b>sheet</b>",
+    "handle": "balance_sheet",
+    "account_range": ["1", "2", "3"],
+    "text_parts": {
+        "notes": "text_parts/notes.liquid"
+    },
+    "id": {
+        "1001": 11
+    },
+    "published": true
+}
+`,
+		},
+		{
+			name: "nested changes keep the unchanged siblings",
+			update: func(values map[string]interface{}) {
+				values["id"].(map[string]interface{})["1002"] = 12
+			},
+			expected: `{
+    "name_en": "Balance <b>sheet</b>",
+    "handle": "balance",
+    "account_range": ["1", "2"],
+    "text_parts": {},
+    "id": {
+        "1001": 11,
+        "1002": 12
+    },
+    "amount": 1.50
+}
+`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.json")
+			if err := os.WriteFile(path, []byte(original), 0644); err != nil {
+				t.Fatal(err)
+			}
+			file, err := loadConfigFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			test.update(file.Values)
+			if err := file.save(); err != nil {
+				t.Fatalf("save failed: %v", err)
+			}
+
+			data, _ := os.ReadFile(path)
+			if string(data) != test.expected {
+				t.Errorf("Expected:\n%s\ngot:\n%s", test.expected, data)
+			}
+		})
+	}
+}
+
+func TestConfigFileSave(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte(`{"host": "a"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := loadConfigFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.Values["host"] = "b"
+	if err := file.save(); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	if string(data) != `{"host": "b"}` {
+		t.Errorf("Expected the compact layout without trailing newline to be kept, got %s", data)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+		t.Errorf("Expected mode 0600 to be kept, got %v", info.Mode().Perm())
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("Expected no temporary files to be left, got %d entries", len(entries))
+	}
+
+	// A change on disk after loading is not overwritten
+	file, err = loadConfigFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(`{"host": "c"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	file.Values["host"] = "d"
+	if err := file.save(); !errors.Is(err, ErrConfigModified) {
+		t.Errorf("Expected ErrConfigModified, got %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != `{"host": "c"}` {
+		t.Errorf("Expected the concurrent change to be kept, got %s", data)
+	}
+}
+
+func TestConfigFileSaveThroughSymlink(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "real.json")
+	link := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(target, []byte("{\n  \"host\": \"a\"\n}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+
+	file, err := loadConfigFile(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.Values["host"] = "b"
+	if err := file.save(); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+
+	if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("Expected config.json to stay a symlink")
+	}
+	if data, _ := os.ReadFile(target); string(data) != "{\n  \"host\": \"b\"\n}\n" {
+		t.Errorf("Expected the link target to be updated, got %q", data)
+	}
+}
@@ -0,0 +1,108 @@
+package template
+
+import (
+	"sort"
+
+	"github.com/rufex/sftui/internal/models"
+)
+
+// CheckConsistency compares the used_in metadata of the shared parts with the shared parts the
+// Liquid of every template includes. Issues are sorted by template and shared part.
+func (m *Manager) CheckConsistency(templates []models.Template, sharedPartsUsage map[models.TemplateID][]string) []models.ConsistencyIssue {
+	graph := m.BuildDependencyGraph(templates, sharedPartsUsage)
+
+	var issues []models.ConsistencyIssue
+	for _, template := range graph.Templates {
+		id := template.ID()
+		for _, link := range graph.Links[id] {
+			issue := models.ConsistencyIssue{Template: id, SharedPart: link.Name}
+			switch {
+			case link.Missing && link.InLiquid:
+				issue.Kind = models.UnknownSharedPart
+			case link.InUsedIn && !link.InLiquid:
+				issue.Kind = models.OrphanLink
+			case link.InLiquid && !link.InUsedIn:
+				issue.Kind = models.MissingLink
+			default:
+				continue
+			}
+			issues = append(issues, issue)
+		}
+	}
+
+	for sharedPart, links := range graph.Nested {
+		for _, link := range links {
+			if link.Missing {
+				issues = append(issues, models.ConsistencyIssue{Kind: models.UnknownSharedPart, Template: models.TemplateID{Category: "shared_parts", Name: sharedPart}, SharedPart: link.Name})
+			}
+		}
+	}
+
+	// used_in entries pointing at templates that are not in the repository
+	for id, sharedParts := range sharedPartsUsage {
+		if _, exists := graph.Links[id]; exists {
+			continue
+		}
+		for _, sharedPart := range sharedParts {
+			issues = append(issues, models.ConsistencyIssue{Kind: models.UnknownTemplate, Template: id, SharedPart: sharedPart})
+		}
+	}
+
+	sort.Slice(issues, func(i, j int) bool {
+		if issues[i].Template != issues[j].Template {
+			return issues[i].Template.String() < issues[j].Template.String()
+		}
+		return issues[i].SharedPart < issues[j].SharedPart
+	})
+	return issues
+}
+
+// FixConsistency rewrites the used_in arrays of the shared parts so they match the Liquid
+// includes: orphan links and links to unknown templates are removed, missing links are added.
+// Includes of unknown shared parts need a Liquid change and are skipped. It returns the number
+// of issues fixed by a change to a used_in array.
+func (c *ConfigManager) FixConsistency(templates []models.Template, issues []models.ConsistencyIssue) (int, error) {
+	c.beginEdit("Fix shared part links")
+	defer c.endEdit()
+	registry := NewRegistry(templates)
+	sharedParts := make(map[string]models.Template)
+	for _, template := range templates {
+		if template.Category == "shared_parts" {
+			sharedParts[template.Name] = template
+		}
+	}
+
+	fixed := 0
+	for _, issue := range issues {
+		if !issue.Fixable() {
+			continue
+		}
+
+		sharedPart, ok := sharedParts[issue.SharedPart]
+		if !ok {
+			continue
+		}
+
+		// Links to unknown templates are only known by the handle used_in lists them with
+		target, ok := registry.Get(issue.Template)
+		if !ok {
+			target = models.Template{Category: issue.Template.Category, Name: issue.Template.Name}
+		}
+
+		var changed bool
+		var err error
+		if issue.Kind == models.MissingLink {
+			changed, err = c.LinkSharedPart(sharedPart, target)
+		} else {
+			changed, err = c.UnlinkSharedPart(sharedPart, target)
+		}
+		if err != nil {
+			return fixed, err
+		}
+		if changed {
+			fixed++
+		}
+	}
+
+	return fixed, nil
+}
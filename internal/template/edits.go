@@ -0,0 +1,224 @@
+package template
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"time"
+
+	"github.com/rufex/sftui/internal/models"
+)
+
+// pendingEdit collects the files a ConfigManager edit touches, with their content from before
+// the edit changed them.
+type pendingEdit struct {
+	description string
+	depth       int // edits nested in this one
+	paths       []string
+	before      map[string]*string
+	fieldsOnly  map[string]bool   // files recorded by their changed keys only, see trackFields
+	written     map[string][]byte // template configs as last saved by the edit
+}
+
+// SetRecorder makes the ConfigManager report every edit, with the files it changed, to record.
+func (c *ConfigManager) SetRecorder(record func(models.HistoryEntry)) {
+	c.recorder = record
+}
+
+// Batch runs several ConfigManager edits as a single history entry.
+func (c *ConfigManager) Batch(description string, edits func() error) error {
+	c.beginEdit(description)
+	defer c.endEdit()
+	return edits()
+}
+
+// beginEdit starts recording an edit. Edits started while another one is open, like the links
+// made by FixConsistency or inside Batch, become part of it.
+func (c *ConfigManager) beginEdit(description string) {
+	if c.edit != nil {
+		c.edit.depth++
+		return
+	}
+	c.edit = &pendingEdit{description: description, before: make(map[string]*string), fieldsOnly: make(map[string]bool), written: make(map[string][]byte)}
+}
+
+// track records the content of a file before the open edit changes it.
+func (c *ConfigManager) track(path string) {
+	if c.edit == nil || c.recorder == nil {
+		return
+	}
+	if _, ok := c.edit.before[path]; ok {
+		return
+	}
+	c.edit.paths = append(c.edit.paths, path)
+	c.edit.before[path] = readContent(path)
+}
+
+// trackFields is track for a JSON file holding credentials: the edit reports the keys it changed,
+// one level deep, but not the content of the file.
+func (c *ConfigManager) trackFields(path string) {
+	c.track(path)
+	if c.edit != nil {
+		c.edit.fieldsOnly[path] = true
+	}
+}
+
+// endEdit closes the edit and reports the files it changed, also when it failed halfway.
+func (c *ConfigManager) endEdit() {
+	edit := c.edit
+	if edit == nil {
+		return
+	}
+	if edit.depth > 0 {
+		edit.depth--
+		return
+	}
+	c.edit = nil
+	if c.recorder == nil {
+		return
+	}
+
+	entry := models.HistoryEntry{Time: time.Now(), Description: edit.description}
+	for _, path := range edit.paths {
+		before, after := edit.before[path], readContent(path)
+		if reflect.DeepEqual(before, after) {
+			continue
+		}
+		if edit.fieldsOnly[path] {
+			if changes := nestedFieldChanges(fieldChanges(path, before, after)); len(changes) > 0 {
+				entry.Files = append(entry.Files, models.FileChange{Path: path, FieldsOnly: true})
+				entry.Fields = append(entry.Fields, changes...)
+			}
+			continue
+		}
+		entry.Files = append(entry.Files, models.FileChange{Path: path, Before: before, After: after})
+		if filepath.Ext(path) == ".json" {
+			entry.Fields = append(entry.Fields, fieldChanges(path, before, after)...)
+		}
+	}
+	if len(entry.Files) > 0 {
+		c.recorder(entry)
+	}
+}
+
+// readContent returns the content of a file, or nil when it does not exist.
+func readContent(path string) *string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	content := string(data)
+	return &content
+}
+
+// fieldChanges lists the top-level keys that differ between two versions of a JSON file.
+func fieldChanges(path string, before, after *string) []models.FieldChange {
+	old, updated := decodeObject(before), decodeObject(after)
+
+	var changes []models.FieldChange
+	for _, key := range unionKeys(old, updated) {
+		if !reflect.DeepEqual(old[key], updated[key]) {
+			changes = append(changes, models.FieldChange{File: path, Field: key, Old: old[key], New: updated[key]})
+		}
+	}
+	return changes
+}
+
+// unionKeys returns the keys of both objects in alphabetical order.
+func unionKeys(a, b map[string]interface{}) []string {
+	union := make(map[string]interface{}, len(a)+len(b))
+	for key, value := range a {
+		union[key] = value
+	}
+	for key, value := range b {
+		union[key] = value
+	}
+	return sortedKeys(union)
+}
+
+// nestedFieldChanges splits the changes of objects into changes of their keys.
+func nestedFieldChanges(changes []models.FieldChange) []models.FieldChange {
+	var nested []models.FieldChange
+	for _, change := range changes {
+		old, oldIsObject := change.Old.(map[string]interface{})
+		updated, newIsObject := change.New.(map[string]interface{})
+		if !(oldIsObject || change.Old == nil) || !(newIsObject || change.New == nil) {
+			nested = append(nested, change)
+			continue
+		}
+		for _, key := range unionKeys(old, updated) {
+			if !reflect.DeepEqual(old[key], updated[key]) {
+				nested = append(nested, models.FieldChange{File: change.File, Field: change.Field, Key: key, Old: old[key], New: updated[key]})
+			}
+		}
+	}
+	return nested
+}
+
+// MatchesFields reports whether the keys of a config file hold the values from before (undo) or
+// after the changes, so they can be set to the other side without losing a later change.
+func MatchesFields(path string, changes []models.FieldChange, before bool) (bool, error) {
+	file, err := loadConfigFile(path)
+	if err != nil {
+		return false, err
+	}
+	for _, change := range changes {
+		expected := change.New
+		if before {
+			expected = change.Old
+		}
+		if !reflect.DeepEqual(fieldValue(file.Values, change), expected) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// SetFields sets the keys of a config file to their values from before (undo) or after the
+// changes, leaving every other key as it is.
+func SetFields(path string, changes []models.FieldChange, before bool) error {
+	file, err := loadConfigFile(path)
+	if err != nil {
+		return err
+	}
+	for _, change := range changes {
+		value := change.New
+		if before {
+			value = change.Old
+		}
+
+		values := file.Values
+		key := change.Field
+		if change.Key != "" {
+			object, ok := values[change.Field].(map[string]interface{})
+			if !ok {
+				object = make(map[string]interface{})
+				values[change.Field] = object
+			}
+			values, key = object, change.Key
+		}
+		if value == nil {
+			delete(values, key)
+		} else {
+			values[key] = value
+		}
+	}
+	return file.save()
+}
+
+func fieldValue(values map[string]interface{}, change models.FieldChange) interface{} {
+	if change.Key == "" {
+		return values[change.Field]
+	}
+	object, _ := values[change.Field].(map[string]interface{})
+	return object[change.Key]
+}
+
+func decodeObject(content *string) map[string]interface{} {
+	var object map[string]interface{}
+	if content != nil {
+		json.Unmarshal([]byte(*content), &object)
+	}
+	return object
+}
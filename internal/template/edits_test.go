@@ -0,0 +1,213 @@
+package template
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rufex/sftui/internal/models"
+)
+
+func TestConfigManagerRecordsEdits(t *testing.T) {
+	templatePath := filepath.Join(t.TempDir(), "balance")
+	if err := os.MkdirAll(filepath.Join(templatePath, "text_parts"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	config := "{\n  \"handle\": \"balance\",\n  \"text_parts\": {\n    \"notes\": \"text_parts/notes.liquid\"\n  }\n}\n"
+	files := map[string]string{
+		"config.json":             config,
+		"main.liquid":             `{% include "parts/notes" %}`,
+		"text_parts/notes.liquid": "notes",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(templatePath, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Field updates are based on the template as loaded, so load it right before each one
+	loaded := func() models.Template { return NewManager().LoadTemplate(templatePath, "reconciliation_texts") }
+
+	tests := []struct {
+		name        string
+		edit        func(c *ConfigManager) error
+		description string
+		files       int
+		fields      []string
+	}{
+		{
+			name:        "field update",
+			edit:        func(c *ConfigManager) error { return c.UpdateConfigField(loaded(), "public", true) },
+			description: "Set public of balance to true",
+			files:       1,
+			fields:      []string{"public"},
+		},
+		{
+			name:        "unchanged value is not recorded",
+			edit:        func(c *ConfigManager) error { return c.UpdateConfigField(loaded(), "handle", "balance") },
+			description: "",
+		},
+		{
+			name: "rename records config, moved file and includes",
+			edit: func(c *ConfigManager) error {
+				_, err := c.RenameTextPart(loaded(), "notes", "remarks", "text_parts/remarks.liquid")
+				return err
+			},
+			description: "Rename text part notes of balance to remarks",
+			files:       4,
+			fields:      []string{"text_parts"},
+		},
+		{
+			name: "batch is one entry",
+			edit: func(c *ConfigManager) error {
+				// Later steps of a batch build on the earlier ones without reloading the template
+				template := loaded()
+				return c.Batch("Update balance", func() error {
+					if err := c.UpdateConfigField(template, "public", false); err != nil {
+						return err
+					}
+					return c.AddTextPart(template, "extra", "text_parts/extra.liquid")
+				})
+			},
+			description: "Update balance",
+			files:       2,
+			fields:      []string{"public", "text_parts"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var entries []models.HistoryEntry
+			c := NewConfigManager()
+			c.SetRecorder(func(entry models.HistoryEntry) { entries = append(entries, entry) })
+
+			if err := test.edit(c); err != nil {
+				t.Fatal(err)
+			}
+
+			if test.description == "" {
+				if len(entries) != 0 {
+					t.Errorf("recorded %d entries, want none", len(entries))
+				}
+				return
+			}
+			if len(entries) != 1 {
+				t.Fatalf("recorded %d entries, want 1", len(entries))
+			}
+			entry := entries[0]
+			if entry.Description != test.description {
+				t.Errorf("description = %q, want %q", entry.Description, test.description)
+			}
+			if len(entry.Files) != test.files {
+				t.Errorf("recorded %d files, want %d", len(entry.Files), test.files)
+			}
+			var fields []string
+			for _, change := range entry.Fields {
+				fields = append(fields, change.Field)
+			}
+			if len(fields) != len(test.fields) {
+				t.Fatalf("fields = %v, want %v", fields, test.fields)
+			}
+			for i := range fields {
+				if fields[i] != test.fields[i] {
+					t.Errorf("fields = %v, want %v", fields, test.fields)
+				}
+			}
+		})
+	}
+}
+
+func TestFieldChanges(t *testing.T) {
+	before := `{"public": false, "handle": "a"}`
+	after := `{"public": true, "handle": "a", "published": true}`
+
+	changes := fieldChanges("config.json", &before, &after)
+	if len(changes) != 2 {
+		t.Fatalf("got %d changes, want 2", len(changes))
+	}
+	if changes[0].Field != "public" || changes[0].Old != false || changes[0].New != true {
+		t.Errorf("changes[0] = %+v, want public false → true", changes[0])
+	}
+	if changes[1].Field != "published" || changes[1].Old != nil || changes[1].New != true {
+		t.Errorf("changes[1] = %+v, want published added", changes[1])
+	}
+}
+
+func TestSilverfinConfigEditsRecordKeysOnly(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	configPath := filepath.Join(home, ".silverfin", "config.json")
+	if err := os.MkdirAll(filepath.Dir(configPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	config := `{"host": "https://old.example.com", "defaultFirmIDs": {"other-repo": "1002"}, "1001": {"accessToken": "secret"}}`
+	if err := os.WriteFile(configPath, []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+	repo := filepath.Join(t.TempDir(), "market-repo")
+	if err := os.Mkdir(repo, 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(repo)
+
+	var entries []models.HistoryEntry
+	c := NewConfigManager()
+	c.SetRecorder(func(entry models.HistoryEntry) { entries = append(entries, entry) })
+	if err := c.SetHost("https://new.example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.SetDefaultFirm("1001"); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []models.FieldChange{
+		{Field: "host", Old: "https://old.example.com", New: "https://new.example.com"},
+		{Field: "defaultFirmIDs", Key: "market-repo", Old: nil, New: "1001"},
+	}
+	if len(entries) != len(expected) {
+		t.Fatalf("recorded %d entries, want %d", len(entries), len(expected))
+	}
+	for i, entry := range entries {
+		if len(entry.Files) != 1 || !entry.Files[0].FieldsOnly || entry.Files[0].Before != nil || entry.Files[0].After != nil {
+			t.Errorf("entry %d files = %+v, want the config without content", i, entry.Files)
+		}
+		if len(entry.Fields) != 1 {
+			t.Fatalf("entry %d fields = %+v, want 1", i, entry.Fields)
+		}
+		field := entry.Fields[0]
+		if field.Field != expected[i].Field || field.Key != expected[i].Key || field.Old != expected[i].Old || field.New != expected[i].New {
+			t.Errorf("entry %d field = %+v, want %+v", i, field, expected[i])
+		}
+	}
+}
+
+func TestSetFields(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	// The token was refreshed after the edit, which undoing must keep
+	config := "{\n  \"host\": \"https://new.example.com\",\n  \"defaultFirmIDs\": {\n    \"market-repo\": \"1001\"\n  },\n  \"1001\": {\n    \"accessToken\": \"refreshed\"\n  }\n}\n"
+	if err := os.WriteFile(path, []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+	changes := []models.FieldChange{
+		{File: path, Field: "host", Old: "https://old.example.com", New: "https://new.example.com"},
+		{File: path, Field: "defaultFirmIDs", Key: "market-repo", Old: nil, New: "1001"},
+	}
+
+	if ok, err := MatchesFields(path, changes, true); err != nil || ok {
+		t.Errorf("MatchesFields(before) = %v, %v; want false", ok, err)
+	}
+	if ok, err := MatchesFields(path, changes, false); err != nil || !ok {
+		t.Fatalf("MatchesFields(after) = %v, %v; want true", ok, err)
+	}
+	if err := SetFields(path, changes, true); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "{\n  \"host\": \"https://old.example.com\",\n  \"defaultFirmIDs\": {},\n  \"1001\": {\n    \"accessToken\": \"refreshed\"\n  }\n}\n"
+	if data, _ := os.ReadFile(path); string(data) != expected {
+		t.Errorf("config after undo = %s, want %s", data, expected)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+		t.Errorf("config mode = %v, want 0600", info.Mode().Perm())
+	}
+}
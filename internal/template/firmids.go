@@ -0,0 +1,51 @@
+package template
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/rufex/sftui/internal/models"
+)
+
+// RemoteIDKey returns the config.json key of the map that holds the template IDs of a firm or
+// partner, keyed by firm or partner ID.
+func RemoteIDKey(option models.FirmOption) string {
+	if option.Type == "partner" {
+		return "partner_id"
+	}
+	return "id"
+}
+
+// RemoteID returns the ID of the template in a firm or partner. ok is false when the template
+// was never imported there.
+func RemoteID(template models.Template, option models.FirmOption) (id string, ok bool) {
+	ids, _ := template.Config[RemoteIDKey(option)].(map[string]interface{})
+	value, ok := ids[option.ID]
+	if !ok {
+		return "", false
+	}
+	if number, isNumber := value.(float64); isNumber {
+		return strconv.FormatFloat(number, 'f', -1, 64), true
+	}
+	return fmt.Sprint(value), true
+}
+
+// MissingQuery returns the search query that lists the templates missing in a firm or partner.
+func MissingQuery(option models.FirmOption) string {
+	return fmt.Sprintf("-%s:%s", option.Type, option.ID)
+}
+
+// sortFirmOptions orders firms before partners, each by numeric ID.
+func sortFirmOptions(options []models.FirmOption) {
+	sort.SliceStable(options, func(i, j int) bool {
+		a, b := options[i], options[j]
+		if a.Type != b.Type {
+			return a.Type == "firm"
+		}
+		if len(a.ID) != len(b.ID) {
+			return len(a.ID) < len(b.ID)
+		}
+		return a.ID < b.ID
+	})
+}
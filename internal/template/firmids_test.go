@@ -0,0 +1,37 @@
+package template
+
+import (
+	"testing"
+
+	"github.com/rufex/sftui/internal/models"
+)
+
+func TestRemoteID(t *testing.T) {
+	template := models.Template{Name: "rt_1", Config: map[string]interface{}{
+		"id":         map[string]interface{}{"1001": float64(11), "1002": "12"},
+		"partner_id": map[string]interface{}{"25": float64(5)},
+	}}
+
+	tests := []struct {
+		option   models.FirmOption
+		expected string
+		ok       bool
+		missing  string
+	}{
+		{models.FirmOption{ID: "1001", Type: "firm"}, "11", true, "-firm:1001"},
+		{models.FirmOption{ID: "1002", Type: "firm"}, "12", true, "-firm:1002"},
+		{models.FirmOption{ID: "1003", Type: "firm"}, "", false, "-firm:1003"},
+		{models.FirmOption{ID: "25", Type: "partner"}, "5", true, "-partner:25"},
+		{models.FirmOption{ID: "1001", Type: "partner"}, "", false, "-partner:1001"},
+	}
+
+	for _, test := range tests {
+		id, ok := RemoteID(template, test.option)
+		if id != test.expected || ok != test.ok {
+			t.Errorf("RemoteID(%s %s) = %q, %v, expected %q, %v", test.option.Type, test.option.ID, id, ok, test.expected, test.ok)
+		}
+		if query := MissingQuery(test.option); query != test.missing {
+			t.Errorf("MissingQuery(%s %s) = %q, expected %q", test.option.Type, test.option.ID, query, test.missing)
+		}
+	}
+}
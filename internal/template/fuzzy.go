@@ -0,0 +1,110 @@
+package template
+
+import (
+	"unicode"
+)
+
+// Fuzzy match scoring. Every matched character is worth scoreMatch; characters at the start of a
+// word and runs of consecutive characters earn bonuses, skipped characters cost a point each.
+const (
+	scoreMatch       = 16
+	bonusFirstChar   = 10 // match on the first character of the target
+	bonusBoundary    = 8  // match after _, -, /, . or a space
+	bonusCamelCase   = 7  // match on an upper case letter following a lower case one
+	bonusConsecutive = 8  // match directly after the previous match
+	penaltyGap       = 1  // per character skipped between two matches
+	maxLeadingGap    = 3  // cap of the penalty for characters skipped before the first match
+)
+
+// FuzzyScore matches the characters of query in order, case-insensitively, against target. It
+// returns the score of the best alignment and the rune positions of target it matched; ok is
+// false when target does not contain every character of query. An empty query matches with
+// score 0.
+func FuzzyScore(query, target string) (score int, positions []int, ok bool) {
+	q := []rune(query)
+	t := []rune(target)
+	if len(q) == 0 {
+		return 0, nil, true
+	}
+	if len(q) > len(t) {
+		return 0, nil, false
+	}
+
+	bonus := make([]int, len(t))
+	lower := make([]rune, len(t))
+	for i, c := range t {
+		lower[i] = unicode.ToLower(c)
+		switch {
+		case i == 0:
+			bonus[i] = bonusFirstChar
+		case isWordSeparator(t[i-1]):
+			bonus[i] = bonusBoundary
+		case unicode.IsUpper(c) && unicode.IsLower(t[i-1]):
+			bonus[i] = bonusCamelCase
+		}
+	}
+	for i := range q {
+		q[i] = unicode.ToLower(q[i])
+	}
+
+	// best[j][i] is the score of the best alignment of q[:j+1] with q[j] matched at t[i], and
+	// from[j][i] the position of q[j-1] in that alignment.
+	const none = -1 << 30
+	best := make([][]int, len(q))
+	from := make([][]int, len(q))
+	for j := range q {
+		best[j] = make([]int, len(t))
+		from[j] = make([]int, len(t))
+		for i := range t {
+			best[j][i] = none
+			if lower[i] != q[j] {
+				continue
+			}
+			if j == 0 {
+				best[j][i] = scoreMatch + bonus[i] - min(i, maxLeadingGap)*penaltyGap
+				continue
+			}
+			for k := j - 1; k < i; k++ {
+				if best[j-1][k] == none {
+					continue
+				}
+				candidate := best[j-1][k] + scoreMatch + bonus[i]
+				if k == i-1 {
+					candidate += bonusConsecutive
+				} else {
+					candidate -= (i - k - 1) * penaltyGap
+				}
+				if candidate > best[j][i] {
+					best[j][i] = candidate
+					from[j][i] = k
+				}
+			}
+		}
+	}
+
+	last := len(q) - 1
+	end := -1
+	for i := range t {
+		if best[last][i] != none && (end < 0 || best[last][i] > best[last][end]) {
+			end = i
+		}
+	}
+	if end < 0 {
+		return 0, nil, false
+	}
+
+	positions = make([]int, len(q))
+	for j, i := last, end; j >= 0; j-- {
+		positions[j] = i
+		i = from[j][i]
+	}
+	return best[last][end], positions, true
+}
+
+func isWordSeparator(c rune) bool {
+	switch c {
+	case '_', '-', '/', '.', ' ', '\\':
+		return true
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package template
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/rufex/sftui/internal/models"
+)
+
+func TestFuzzyScore(t *testing.T) {
+	tests := []struct {
+		query     string
+		target    string
+		ok        bool
+		positions []int
+	}{
+		{"", "anything", true, nil},
+		{"abc", "abc", true, []int{0, 1, 2}},
+		{"abc", "acb", false, nil},
+		{"BS", "balance_sheet", true, []int{0, 8}},
+		{"sheet", "balance_sheet_sheet", true, []int{8, 9, 10, 11, 12}},
+		{"vat", "overview_vat_return", true, []int{9, 10, 11}},
+		{"rt", "ReconciliationText", true, []int{0, 14}},
+	}
+
+	for _, test := range tests {
+		_, positions, ok := FuzzyScore(test.query, test.target)
+		if ok != test.ok || !reflect.DeepEqual(positions, test.positions) {
+			t.Errorf("FuzzyScore(%q, %q) = %v %v, expected %v %v", test.query, test.target, positions, ok, test.positions, test.ok)
+		}
+	}
+}
+
+func TestFuzzyScoreRanking(t *testing.T) {
+	// Each query should score the first target above the second
+	tests := []struct {
+		query  string
+		better string
+		worse  string
+	}{
+		{"vat", "vat_return", "overview_vat"},      // match at the start
+		{"vat", "overview_vat", "value_added_tax"}, // consecutive characters
+		{"bs", "balance_sheet", "bonus"},           // word boundaries
+		{"tax", "tax", "t_a_x"},                    // fewer skipped characters
+		{"ab", "a_b", "axxxxxxxxxxxxxxxb"},
+	}
+
+	for _, test := range tests {
+		better, _, _ := FuzzyScore(test.query, test.better)
+		worse, _, _ := FuzzyScore(test.query, test.worse)
+		if better <= worse {
+			t.Errorf("Expected %q to score %q (%d) above %q (%d)", test.query, test.better, better, test.worse, worse)
+		}
+	}
+}
+
+func TestFilterTemplatesRanking(t *testing.T) {
+	templates := []models.Template{
+		{Name: "overview_vat", Category: "reconciliation_texts", Path: "reconciliation_texts/overview_vat"},
+		{Name: "notes", Category: "account_templates", Path: "account_templates/notes"},
+		{Name: "vat_return", Category: "reconciliation_texts", Path: "reconciliation_texts/vat_return"},
+		{Name: "vacation_tool", Category: "export_files", Path: "export_files/vacation_tool"},
+	}
+
+	ids := NewManager().FilterTemplates(templates, "vat")
+	var names []string
+	for _, id := range ids {
+		names = append(names, id.Name)
+	}
+	expected := []string{"vat_return", "overview_vat", "vacation_tool"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("FilterTemplates(vat) = %v, expected %v", names, expected)
+	}
+}
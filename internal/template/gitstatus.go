@@ -0,0 +1,88 @@
+package template
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/rufex/sftui/internal/models"
+)
+
+// GitStatus returns the porcelain status code ("M", "A", "D", "??", ...) of every template with
+// uncommitted changes below the repository root. Templates without changes are left out.
+func (m *Manager) GitStatus(templates []models.Template) (map[models.TemplateID]string, error) {
+	root := m.RootPath()
+	topLevel, err := runGit(root, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+	output, err := runGit(root, "status", "--porcelain", "-z", "--untracked-files=all", "--", ".")
+	if err != nil {
+		return nil, err
+	}
+
+	dirs := make(map[string]models.TemplateID, len(templates))
+	for _, template := range templates {
+		if dir, err := resolvePath(template.Path); err == nil {
+			dirs[dir] = template.ID()
+		}
+	}
+
+	status := make(map[models.TemplateID]string)
+	entries := strings.Split(output, "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if len(entry) < 4 {
+			continue
+		}
+		code := strings.TrimSpace(entry[:2])
+		if code[0] == 'R' || code[0] == 'C' {
+			i++ // renames and copies are followed by the original path
+		}
+
+		path := filepath.Join(strings.TrimSpace(topLevel), filepath.FromSlash(entry[3:]))
+		if id, ok := templateOf(dirs, path); ok {
+			if _, seen := status[id]; !seen {
+				status[id] = code
+			}
+		}
+	}
+	return status, nil
+}
+
+// templateOf returns the template whose directory contains path.
+func templateOf(dirs map[string]models.TemplateID, path string) (models.TemplateID, bool) {
+	for dir := path; ; dir = filepath.Dir(dir) {
+		if id, ok := dirs[dir]; ok {
+			return id, true
+		}
+		if parent := filepath.Dir(dir); parent == dir {
+			return models.TemplateID{}, false
+		}
+	}
+}
+
+func resolvePath(path string) (string, error) {
+	absolute, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(absolute)
+}
+
+func runGit(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], message)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return string(output), nil
+}
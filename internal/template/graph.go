@@ -0,0 +1,179 @@
+package template
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/rufex/sftui/internal/liquid"
+	"github.com/rufex/sftui/internal/models"
+)
+
+// SharedPartLink is a dependency of a template on a shared part, as declared by the shared
+// part's used_in metadata and/or an include statement in the template's Liquid.
+type SharedPartLink struct {
+	Name     string
+	InUsedIn bool // the shared part's used_in lists the template
+	InLiquid bool // the template's Liquid includes the shared part
+	Missing  bool // no shared part with this name exists
+}
+
+// Mismatch reports whether used_in and the Liquid of a template disagree about the link, or
+// the shared part does not exist. Links between shared parts only come from Liquid and can
+// only be missing.
+func (l SharedPartLink) Mismatch() bool {
+	return l.Missing || l.InUsedIn != l.InLiquid
+}
+
+// DependencyGraph links templates to the shared parts they use and shared parts to the shared
+// parts they include.
+type DependencyGraph struct {
+	Templates   []models.Template                      // templates that can use shared parts, in input order
+	Links       map[models.TemplateID][]SharedPartLink // sorted by shared part name
+	Nested      map[string][]SharedPartLink            // shared part name to the shared parts its Liquid includes
+	SharedParts map[string]models.Template             // shared parts by name
+}
+
+// SharedPartUsers returns the templates whose used_in lists the shared part, sorted by
+// category and name.
+func SharedPartUsers(sharedPartsUsage map[models.TemplateID][]string, sharedPartName string) []models.TemplateID {
+	var users []models.TemplateID
+	for id, sharedParts := range sharedPartsUsage {
+		for _, name := range sharedParts {
+			if name == sharedPartName {
+				users = append(users, id)
+				break
+			}
+		}
+	}
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].String() < users[j].String()
+	})
+	return users
+}
+
+// LiquidSharedParts returns the shared parts included from the main Liquid file and the text
+// parts of a template.
+func (m *Manager) LiquidSharedParts(template models.Template) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, file := range m.LiquidFiles(template) {
+		data, err := os.ReadFile(filepath.Join(template.Path, file))
+		if err != nil {
+			continue
+		}
+		for _, name := range liquid.SharedParts(string(data)) {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// BuildDependencyGraph combines the used_in metadata of the shared parts with the include
+// statements found in the Liquid of every template.
+func (m *Manager) BuildDependencyGraph(templates []models.Template, sharedPartsUsage map[models.TemplateID][]string) *DependencyGraph {
+	graph := &DependencyGraph{
+		Links:       make(map[models.TemplateID][]SharedPartLink),
+		Nested:      make(map[string][]SharedPartLink),
+		SharedParts: make(map[string]models.Template),
+	}
+
+	for _, template := range templates {
+		if template.Category == "shared_parts" {
+			graph.SharedParts[template.Name] = template
+		}
+	}
+
+	for _, template := range templates {
+		if template.Category == "shared_parts" {
+			var links []SharedPartLink
+			for _, name := range m.LiquidSharedParts(template) {
+				_, exists := graph.SharedParts[name]
+				links = append(links, SharedPartLink{Name: name, InLiquid: true, Missing: !exists})
+			}
+			sortLinks(links)
+			graph.Nested[template.Name] = links
+			continue
+		}
+
+		if UsedInType(template.Category) == "" {
+			continue
+		}
+
+		id := template.ID()
+		inUsedIn := make(map[string]bool)
+		for _, name := range sharedPartsUsage[id] {
+			inUsedIn[name] = true
+		}
+		inLiquid := make(map[string]bool)
+		for _, name := range m.LiquidSharedParts(template) {
+			inLiquid[name] = true
+		}
+
+		var links []SharedPartLink
+		for _, names := range []map[string]bool{inUsedIn, inLiquid} {
+			for name := range names {
+				if !containsLink(links, name) {
+					_, exists := graph.SharedParts[name]
+					links = append(links, SharedPartLink{Name: name, InUsedIn: inUsedIn[name], InLiquid: inLiquid[name], Missing: !exists})
+				}
+			}
+		}
+
+		sortLinks(links)
+		graph.Templates = append(graph.Templates, template)
+		graph.Links[id] = links
+	}
+
+	return graph
+}
+
+// UnusedSharedParts returns the shared parts no template or shared part depends on, sorted.
+func (g *DependencyGraph) UnusedSharedParts() []string {
+	used := make(map[string]bool)
+	for _, links := range g.Links {
+		for _, link := range links {
+			used[link.Name] = true
+		}
+	}
+	for _, links := range g.Nested {
+		for _, link := range links {
+			used[link.Name] = true
+		}
+	}
+
+	var unused []string
+	for name := range g.SharedParts {
+		if !used[name] {
+			unused = append(unused, name)
+		}
+	}
+	sort.Strings(unused)
+	return unused
+}
+
+// SharedPartPath returns the directory of a shared part, or "" when it does not exist.
+func (g *DependencyGraph) SharedPartPath(name string) string {
+	if sharedPart, ok := g.SharedParts[name]; ok {
+		return filepath.Clean(sharedPart.Path)
+	}
+	return ""
+}
+
+func containsLink(links []SharedPartLink, name string) bool {
+	for _, link := range links {
+		if link.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func sortLinks(links []SharedPartLink) {
+	sort.Slice(links, func(i, j int) bool {
+		return links[i].Name < links[j].Name
+	})
+}
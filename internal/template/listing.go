@@ -0,0 +1,88 @@
+package template
+
+import (
+	"sort"
+
+	"github.com/rufex/sftui/internal/models"
+)
+
+// SortTemplates orders the IDs of the Templates section in place. Ties are broken by name so
+// the order is stable across reloads.
+func SortTemplates(registry *Registry, ids []models.TemplateID, order models.TemplateSort, gitStatus map[models.TemplateID]string) {
+	less := func(a, b models.TemplateID) bool {
+		switch order {
+		case models.SortByName:
+			if a.Name != b.Name {
+				return a.Name < b.Name
+			}
+		case models.SortByHandle:
+			first, _ := registry.Get(a)
+			second, _ := registry.Get(b)
+			if Handle(first) != Handle(second) {
+				return Handle(first) < Handle(second)
+			}
+		case models.SortByModified:
+			first, _ := registry.Get(a)
+			second, _ := registry.Get(b)
+			if !first.ModTime.Equal(second.ModTime) {
+				return first.ModTime.After(second.ModTime)
+			}
+		case models.SortByGitStatus:
+			first, second := gitStatus[a], gitStatus[b]
+			if (first == "") != (second == "") {
+				return second == ""
+			}
+			if first != second {
+				return first < second
+			}
+		}
+		if a.Category != b.Category {
+			return categoryIndex(a.Category) < categoryIndex(b.Category)
+		}
+		return a.Name < b.Name
+	}
+
+	sort.SliceStable(ids, func(i, j int) bool { return less(ids[i], ids[j]) })
+}
+
+// GroupTemplates splits sorted IDs under category headings in Categories order, keeping the
+// order within each category. Templates of collapsed categories are left out of the returned
+// IDs; their heading still shows how many there are.
+func GroupTemplates(ids []models.TemplateID, collapsed map[string]bool) ([]models.TemplateID, []models.TemplateRow) {
+	byCategory := make(map[string][]models.TemplateID)
+	for _, id := range ids {
+		byCategory[id.Category] = append(byCategory[id.Category], id)
+	}
+
+	visible := []models.TemplateID{}
+	rows := []models.TemplateRow{}
+	for _, category := range Categories {
+		members := byCategory[category]
+		if len(members) == 0 {
+			continue
+		}
+		rows = append(rows, models.TemplateRow{Category: category, Count: len(members), Collapsed: collapsed[category], Template: -1})
+		if collapsed[category] {
+			continue
+		}
+		for _, id := range members {
+			rows = append(rows, models.TemplateRow{Template: len(visible)})
+			visible = append(visible, id)
+		}
+	}
+	return visible, rows
+}
+
+// GetCategoryGroupName returns the heading of a category in the grouped Templates section.
+func (m *Manager) GetCategoryGroupName(category string) string {
+	return m.GetCategoryDisplayName(category) + "s"
+}
+
+func categoryIndex(category string) int {
+	for i, c := range Categories {
+		if c == category {
+			return i
+		}
+	}
+	return len(Categories)
+}
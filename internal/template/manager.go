@@ -5,7 +5,8 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
-	"strings"
+	"sort"
+	"time"
 
 	"github.com/rufex/sftui/internal/models"
 )
@@ -16,26 +17,30 @@ func NewManager() *Manager {
 	return &Manager{}
 }
 
-func (m *Manager) LoadTemplates() []models.Template {
-	var templates []models.Template
+// Categories lists the template directories of a repository in display order.
+var Categories = []string{"account_templates", "reconciliation_texts", "export_files", "shared_parts"}
+
+// LoadTemplates discovers the templates of the repository together with the problems found
+// while loading them.
+func (m *Manager) LoadTemplates() ([]models.Template, []models.LoadProblem) {
+	return m.scanDirectory(m.RootPath())
+}
 
+// RootPath returns the repository directory templates are loaded from.
+func (m *Manager) RootPath() string {
 	// Check if fixtures directory exists, use it for testing
 	if _, err := os.Stat("fixtures/market-repo"); err == nil {
-		templates = m.scanDirectory("fixtures/market-repo")
-	} else {
-		// Scan current directory for templates
-		templates = m.scanDirectory(".")
+		return "fixtures/market-repo"
 	}
-
-	return templates
+	// Scan current directory for templates
+	return "."
 }
 
-func (m *Manager) scanDirectory(rootPath string) []models.Template {
+func (m *Manager) scanDirectory(rootPath string) ([]models.Template, []models.LoadProblem) {
 	var templates []models.Template
+	var problems []models.LoadProblem
 
-	categories := []string{"account_templates", "reconciliation_texts", "export_files", "shared_parts"}
-
-	for _, category := range categories {
+	for _, category := range Categories {
 		categoryPath := filepath.Join(rootPath, category)
 		if _, err := os.Stat(categoryPath); os.IsNotExist(err) {
 			continue
@@ -43,33 +48,71 @@ func (m *Manager) scanDirectory(rootPath string) []models.Template {
 
 		filepath.WalkDir(categoryPath, func(path string, d fs.DirEntry, err error) error {
 			if err != nil {
+				problems = append(problems, models.LoadProblem{Kind: models.UnreadableDirectory, Path: path, Message: err.Error()})
+				if d != nil && d.IsDir() {
+					return filepath.SkipDir
+				}
 				return nil
 			}
 
 			if d.Name() == "config.json" {
-				templateDir := filepath.Dir(path)
-				templateName := filepath.Base(templateDir)
-
-				// Load config
-				config := make(map[string]interface{})
-				if data, err := os.ReadFile(path); err == nil && len(data) > 0 {
-					json.Unmarshal(data, &config)
-				}
-
-				template := models.Template{
-					Name:     templateName,
-					Path:     templateDir,
-					Type:     category,
-					Category: category,
-					Config:   config,
-				}
+				template := m.LoadTemplate(filepath.Dir(path), category)
 				templates = append(templates, template)
+				problems = append(problems, m.TemplateProblems(template)...)
 			}
 			return nil
 		})
 	}
 
-	return templates
+	return templates, problems
+}
+
+// LoadTemplate reads a single template directory and its config.json.
+func (m *Manager) LoadTemplate(templateDir, category string) models.Template {
+	// Load config
+	config := make(map[string]interface{})
+	var validation []models.ValidationIssue
+	data, err := os.ReadFile(filepath.Join(templateDir, "config.json"))
+	switch {
+	case err != nil:
+		validation = append(validation, models.ValidationIssue{Field: "config.json", Message: err.Error(), Severity: models.LogError})
+	case len(data) == 0:
+		validation = append(validation, models.ValidationIssue{Field: "config.json", Message: "file is empty", Severity: models.LogError})
+	default:
+		if err := json.Unmarshal(data, &config); err != nil {
+			config = make(map[string]interface{})
+			validation = append(validation, invalidJSONIssue(data, err))
+		} else {
+			validation = ValidateConfig(category, config)
+		}
+	}
+
+	return models.Template{
+		Name:       filepath.Base(templateDir),
+		Path:       templateDir,
+		Type:       category,
+		Category:   category,
+		Config:     config,
+		ConfigData: data,
+		Validation: validation,
+		ModTime:    latestModTime(templateDir),
+	}
+}
+
+// latestModTime returns the modification time of the most recently changed file in a
+// template directory.
+func latestModTime(templateDir string) time.Time {
+	var latest time.Time
+	filepath.WalkDir(templateDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil && info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+		return nil
+	})
+	return latest
 }
 
 func (m *Manager) GetCategoryPrefix(category string) string {
@@ -102,42 +145,37 @@ func (m *Manager) GetCategoryDisplayName(category string) string {
 	}
 }
 
+// FuzzyMatch reports whether target contains the characters of query in order, ignoring case.
 func (m *Manager) FuzzyMatch(query, target string) bool {
-	if query == "" {
-		return true
-	}
-
-	query = strings.ToLower(query)
-	target = strings.ToLower(target)
+	_, _, ok := FuzzyScore(query, target)
+	return ok
+}
 
-	queryIdx := 0
-	for i := 0; i < len(target) && queryIdx < len(query); i++ {
-		if target[i] == query[queryIdx] {
-			queryIdx++
-		}
+// FilterTemplates returns the IDs of the templates matching a search query (see ParseQuery),
+// ranked by score. A query that does not parse is matched as plain text.
+func (m *Manager) FilterTemplates(templates []models.Template, searchQuery string) []models.TemplateID {
+	query, err := ParseQuery(searchQuery)
+	if err != nil {
+		query = &Query{Root: &TermNode{Value: searchQuery}}
 	}
 
-	return queryIdx == len(query)
-}
-
-func (m *Manager) FilterTemplates(templates []models.Template, searchQuery string) []int {
-	if searchQuery == "" {
-		// No search query, show all templates
-		filteredTemplates := make([]int, len(templates))
-		for i := range templates {
-			filteredTemplates[i] = i
-		}
-		return filteredTemplates
+	var usage map[models.TemplateID][]string
+	if query.UsesSharedParts() {
+		usage = NewRegistry(templates).SharedPartsUsage()
 	}
 
-	filteredTemplates := []int{}
-	for i, template := range templates {
-		// Match against template name, category, and path
-		if m.FuzzyMatch(searchQuery, template.Name) ||
-			m.FuzzyMatch(searchQuery, template.Category) ||
-			m.FuzzyMatch(searchQuery, template.Path) {
-			filteredTemplates = append(filteredTemplates, i)
+	filteredTemplates := []models.TemplateID{}
+	scores := make(map[models.TemplateID]int)
+	for _, template := range templates {
+		if score, ok := query.Score(template, usage); ok {
+			filteredTemplates = append(filteredTemplates, template.ID())
+			scores[template.ID()] = score
 		}
 	}
+
+	// Best matches first; templates scoring the same keep their load order
+	sort.SliceStable(filteredTemplates, func(i, j int) bool {
+		return scores[filteredTemplates[i]] > scores[filteredTemplates[j]]
+	})
 	return filteredTemplates
 }
@@ -0,0 +1,96 @@
+package template
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/rufex/sftui/internal/models"
+)
+
+// TemplateProblems returns the load problems of a template: a config.json that could not be
+// read or parsed, a missing main Liquid file and text parts pointing at missing files.
+func (m *Manager) TemplateProblems(template models.Template) []models.LoadProblem {
+	var problems []models.LoadProblem
+
+	configPath := filepath.Join(template.Path, "config.json")
+	for _, issue := range template.Validation {
+		if issue.Field == "config.json" {
+			problems = append(problems, models.LoadProblem{
+				Kind:     models.InvalidConfig,
+				Path:     configPath,
+				Template: template.Path,
+				Line:     issue.Line,
+				Column:   issue.Column,
+				Message:  issue.Message,
+			})
+		}
+	}
+	// Without a config the Liquid files of the template are unknown
+	if len(problems) > 0 {
+		return problems
+	}
+
+	mainPath := m.GetMainLiquidPath(template)
+	if _, err := os.Stat(mainPath); err != nil {
+		problems = append(problems, models.LoadProblem{
+			Kind:     models.MissingLiquid,
+			Path:     mainPath,
+			Template: template.Path,
+			Message:  fmt.Sprintf("main Liquid file %s does not exist", m.GetMainLiquidFile(template)),
+		})
+	}
+
+	if template.Category == "shared_parts" {
+		return problems
+	}
+	for _, part := range m.GetTextParts(template) {
+		partPath := m.GetTextPartPath(template, part)
+		if _, err := os.Stat(partPath); err != nil {
+			problems = append(problems, models.LoadProblem{
+				Kind:     models.MissingTextPart,
+				Path:     partPath,
+				Template: template.Path,
+				Message:  fmt.Sprintf("text part %q points at missing file %s", part.Name, part.Path),
+			})
+		}
+	}
+
+	return problems
+}
+
+// invalidJSONIssue describes a config.json that failed to parse, with the line and column of
+// the error when the decoder reports an offset.
+func invalidJSONIssue(data []byte, err error) models.ValidationIssue {
+	issue := models.ValidationIssue{Field: "config.json", Message: "invalid JSON: " + err.Error(), Severity: models.LogError}
+
+	var offset int64
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		offset = syntaxErr.Offset
+	case errors.As(err, &typeErr):
+		offset = typeErr.Offset
+	default:
+		return issue
+	}
+
+	issue.Line, issue.Column = jsonPosition(data, offset)
+	issue.Message = fmt.Sprintf("invalid JSON at line %d, column %d: %v", issue.Line, issue.Column, err)
+	return issue
+}
+
+// jsonPosition converts a byte offset reported by encoding/json to a 1-based line and column.
+func jsonPosition(data []byte, offset int64) (line, column int) {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	before := data[:offset]
+	line = bytes.Count(before, []byte("\n")) + 1
+	column = int(offset) - (bytes.LastIndexByte(before, '\n') + 1)
+	return line, column
+}
@@ -0,0 +1,524 @@
+package template
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/rufex/sftui/internal/models"
+)
+
+// QueryNode is a node of a parsed template search query.
+type QueryNode interface {
+	// String returns the node in prefix notation, e.g. (AND type:rt (NOT published)).
+	String() string
+	score(template models.Template, ctx *queryContext) (int, bool)
+}
+
+// AndNode matches templates matching all of its children.
+type AndNode struct {
+	Children []QueryNode
+}
+
+// OrNode matches templates matching any of its children.
+type OrNode struct {
+	Children []QueryNode
+}
+
+// NotNode matches templates that do not match its child.
+type NotNode struct {
+	Child QueryNode
+}
+
+// TermNode matches a single key:value term. Key is empty for free text, which fuzzy-matches the
+// name, category and path or names a config key that is set.
+type TermNode struct {
+	Key   string
+	Value string
+}
+
+func (n *AndNode) String() string { return joinNodes("AND", n.Children) }
+func (n *OrNode) String() string  { return joinNodes("OR", n.Children) }
+func (n *NotNode) String() string { return "(NOT " + n.Child.String() + ")" }
+
+func (n *TermNode) String() string {
+	value := n.Value
+	if value == "" || strings.ContainsAny(value, " ()") {
+		value = strconv.Quote(value)
+	}
+	if n.Key == "" {
+		return value
+	}
+	return n.Key + ":" + value
+}
+
+func joinNodes(operator string, children []QueryNode) string {
+	parts := []string{operator}
+	for _, child := range children {
+		parts = append(parts, child.String())
+	}
+	return "(" + strings.Join(parts, " ") + ")"
+}
+
+// Query is a parsed search query of the Templates section. The zero Query matches every template.
+type Query struct {
+	Root QueryNode
+}
+
+// ParseQuery parses a search query. Terms are separated by spaces and combined with AND unless
+// joined by OR; AND binds tighter than OR and parentheses group terms. A leading - or NOT negates
+// a term. Terms are key:value pairs or free text:
+//
+//	type:rt              category by prefix (rt, at, ef, sp) or name
+//	name: handle: path:  name, handle and directory of the template
+//	firm:1001            templates imported in the firm (listed in the id map)
+//	partner:25           templates imported in the partner (listed in the partner_id map)
+//	uses:shared_part_1   templates listed in the used_in of the shared part
+//	part:notes           templates with a text part of that name
+//	published:true       any other config.json key; key: matches when the key exists
+//
+// Values with spaces can be quoted. An unfinished query, like an unclosed parenthesis or a
+// trailing OR, is parsed as far as it goes so the filter can follow the user's typing.
+func ParseQuery(input string) (*Query, error) {
+	p := &queryParser{tokens: tokenizeQuery(input)}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind == tokenClose {
+		return nil, fmt.Errorf("unexpected ) at position %d", p.peek().position+1)
+	}
+	return &Query{Root: root}, nil
+}
+
+// Match reports whether the template matches the query. sharedPartsUsage is the used_in
+// mapping of the repository, only needed by uses: terms.
+func (q *Query) Match(template models.Template, sharedPartsUsage map[models.TemplateID][]string) bool {
+	_, ok := q.Score(template, sharedPartsUsage)
+	return ok
+}
+
+// Score matches the template like Match and also returns how well it matched: the sum of the
+// fuzzy scores of its text terms (the best alternative for OR). Other terms score 0.
+func (q *Query) Score(template models.Template, sharedPartsUsage map[models.TemplateID][]string) (int, bool) {
+	if q == nil || q.Root == nil {
+		return 0, true
+	}
+	return q.Root.score(template, &queryContext{usage: sharedPartsUsage})
+}
+
+// HighlightTerms returns the text and name: terms the query does not negate, whose matched
+// characters are highlighted in template names.
+func (q *Query) HighlightTerms() []string {
+	var terms []string
+	var collect func(node QueryNode)
+	collect = func(node QueryNode) {
+		switch n := node.(type) {
+		case *AndNode:
+			for _, child := range n.Children {
+				collect(child)
+			}
+		case *OrNode:
+			for _, child := range n.Children {
+				collect(child)
+			}
+		case *TermNode:
+			if (n.Key == "" || strings.EqualFold(n.Key, "name")) && n.Value != "" {
+				terms = append(terms, n.Value)
+			}
+		}
+	}
+	if q != nil {
+		collect(q.Root)
+	}
+	return terms
+}
+
+// String returns the parsed query in prefix notation, or "" when it matches everything.
+func (q *Query) String() string {
+	if q == nil || q.Root == nil {
+		return ""
+	}
+	return q.Root.String()
+}
+
+// UsesSharedParts reports whether the query has uses: terms and so needs the used_in mapping.
+func (q *Query) UsesSharedParts() bool {
+	return q != nil && hasKey(q.Root, "uses")
+}
+
+func hasKey(node QueryNode, key string) bool {
+	switch n := node.(type) {
+	case *AndNode:
+		for _, child := range n.Children {
+			if hasKey(child, key) {
+				return true
+			}
+		}
+	case *OrNode:
+		for _, child := range n.Children {
+			if hasKey(child, key) {
+				return true
+			}
+		}
+	case *NotNode:
+		return hasKey(n.Child, key)
+	case *TermNode:
+		return strings.EqualFold(n.Key, key)
+	}
+	return false
+}
+
+type queryTokenKind int
+
+const (
+	tokenEnd queryTokenKind = iota
+	tokenTerm
+	tokenOpen
+	tokenClose
+	tokenOr
+	tokenAnd
+	tokenNot
+)
+
+type queryToken struct {
+	kind     queryTokenKind
+	key      string
+	value    string
+	position int
+}
+
+func tokenizeQuery(input string) []queryToken {
+	var tokens []queryToken
+	i := 0
+	for i < len(input) {
+		switch c := input[i]; {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '(':
+			tokens = append(tokens, queryToken{kind: tokenOpen, position: i})
+			i++
+		case c == ')':
+			tokens = append(tokens, queryToken{kind: tokenClose, position: i})
+			i++
+		case c == '-':
+			tokens = append(tokens, queryToken{kind: tokenNot, position: i})
+			i++
+		default:
+			start := i
+			word, end := readQueryWord(input, i)
+			i = end
+
+			token := queryToken{kind: tokenTerm, value: word, position: start}
+			switch word {
+			case "OR", "|":
+				token.kind = tokenOr
+			case "AND", "&":
+				token.kind = tokenAnd
+			case "NOT":
+				token.kind = tokenNot
+			default:
+				if key, value, found := strings.Cut(word, ":"); found && key != "" && input[start] != '"' {
+					token.key = key
+					token.value = unquoteQueryValue(value)
+				} else {
+					token.value = unquoteQueryValue(word)
+				}
+			}
+			tokens = append(tokens, token)
+		}
+	}
+	return append(tokens, queryToken{kind: tokenEnd, position: len(input)})
+}
+
+// readQueryWord reads a term up to the next space or parenthesis outside quotes.
+func readQueryWord(input string, start int) (string, int) {
+	inQuotes := false
+	i := start
+	for ; i < len(input); i++ {
+		c := input[i]
+		if c == '"' {
+			inQuotes = !inQuotes
+			continue
+		}
+		if !inQuotes && (c == ' ' || c == '\t' || c == '(' || c == ')') {
+			break
+		}
+	}
+	if inQuotes {
+		// An unclosed quote runs to the end of the query
+		return input[start:] + `"`, len(input)
+	}
+	return input[start:i], i
+}
+
+func unquoteQueryValue(value string) string {
+	if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+type queryParser struct {
+	tokens []queryToken
+	pos    int
+}
+
+func (p *queryParser) peek() queryToken {
+	return p.tokens[p.pos]
+}
+
+func (p *queryParser) next() queryToken {
+	token := p.tokens[p.pos]
+	if token.kind != tokenEnd {
+		p.pos++
+	}
+	return token
+}
+
+func (p *queryParser) parseOr() (QueryNode, error) {
+	var children []QueryNode
+	for {
+		if p.peek().kind == tokenOr {
+			return nil, fmt.Errorf("OR at position %d needs a term before it", p.peek().position+1)
+		}
+		node, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		if node != nil {
+			children = append(children, node)
+		}
+		if p.peek().kind != tokenOr {
+			break
+		}
+		p.next()
+	}
+	return combine(children, func(c []QueryNode) QueryNode { return &OrNode{Children: c} }), nil
+}
+
+func (p *queryParser) parseAnd() (QueryNode, error) {
+	var children []QueryNode
+	for {
+		switch p.peek().kind {
+		case tokenEnd, tokenClose, tokenOr:
+			return combine(children, func(c []QueryNode) QueryNode { return &AndNode{Children: c} }), nil
+		case tokenAnd:
+			p.next()
+			continue
+		}
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if node != nil {
+			children = append(children, node)
+		}
+	}
+}
+
+func (p *queryParser) parseUnary() (QueryNode, error) {
+	token := p.next()
+	switch token.kind {
+	case tokenNot:
+		if kind := p.peek().kind; kind == tokenEnd || kind == tokenClose || kind == tokenOr {
+			return nil, nil // nothing typed after the - yet
+		}
+		child, err := p.parseUnary()
+		if err != nil || child == nil {
+			return child, err
+		}
+		return &NotNode{Child: child}, nil
+	case tokenOpen:
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek().kind == tokenClose {
+			p.next()
+		}
+		return node, nil
+	default:
+		return &TermNode{Key: token.key, Value: token.value}, nil
+	}
+}
+
+// combine returns nil for no nodes, the node itself for one and a new group otherwise.
+func combine(nodes []QueryNode, group func([]QueryNode) QueryNode) QueryNode {
+	switch len(nodes) {
+	case 0:
+		return nil
+	case 1:
+		return nodes[0]
+	default:
+		return group(nodes)
+	}
+}
+
+type queryContext struct {
+	usage map[models.TemplateID][]string
+}
+
+// Weights of the fields free text is matched against: a match in the name or handle ranks
+// above one that only appears in the category or path.
+const (
+	weightName = 3
+	weightPath = 1
+)
+
+func (n *AndNode) score(template models.Template, ctx *queryContext) (int, bool) {
+	total := 0
+	for _, child := range n.Children {
+		score, ok := child.score(template, ctx)
+		if !ok {
+			return 0, false
+		}
+		total += score
+	}
+	return total, true
+}
+
+func (n *OrNode) score(template models.Template, ctx *queryContext) (int, bool) {
+	best, matched := 0, false
+	for _, child := range n.Children {
+		if score, ok := child.score(template, ctx); ok && (!matched || score > best) {
+			best, matched = score, true
+		}
+	}
+	return best, matched
+}
+
+func (n *NotNode) score(template models.Template, ctx *queryContext) (int, bool) {
+	_, ok := n.Child.score(template, ctx)
+	return 0, !ok
+}
+
+func (n *TermNode) score(template models.Template, ctx *queryContext) (int, bool) {
+	value := strings.ToLower(n.Value)
+	switch strings.ToLower(n.Key) {
+	case "":
+		score, ok := textScore(n.Value, template)
+		if ok {
+			return score, true
+		}
+		setting, set := template.Config[n.Value]
+		return 0, set && isSet(setting)
+	case "name":
+		score, _, ok := FuzzyScore(n.Value, template.Name)
+		return score * weightName, ok
+	case "path":
+		score, _, ok := FuzzyScore(n.Value, template.Path)
+		return score * weightPath, ok
+	}
+	return 0, n.matchKey(template, ctx, value)
+}
+
+// textScore returns the best weighted fuzzy score of free text against the name, handle,
+// category and path of a template.
+func textScore(text string, template models.Template) (int, bool) {
+	best, matched := 0, false
+	fields := []struct {
+		value  string
+		weight int
+	}{
+		{template.Name, weightName},
+		{Handle(template), weightName},
+		{template.Category, weightPath},
+		{template.Path, weightPath},
+	}
+	for _, field := range fields {
+		if score, _, ok := FuzzyScore(text, field.value); ok && (!matched || score*field.weight > best) {
+			best, matched = score*field.weight, true
+		}
+	}
+	return best, matched
+}
+
+// matchKey matches the terms that filter without ranking.
+func (n *TermNode) matchKey(template models.Template, ctx *queryContext, value string) bool {
+	manager := NewManager()
+	switch strings.ToLower(n.Key) {
+	case "type", "category":
+		return value == "" ||
+			strings.EqualFold(manager.GetCategoryPrefix(template.Category), value) ||
+			strings.HasPrefix(template.Category, value)
+	case "handle":
+		return value == "" || strings.EqualFold(Handle(template), n.Value)
+	case "firm", "partner":
+		option := models.FirmOption{ID: n.Value, Type: strings.ToLower(n.Key)}
+		if value == "" {
+			ids, _ := template.Config[RemoteIDKey(option)].(map[string]interface{})
+			return len(ids) > 0
+		}
+		_, ok := RemoteID(template, option)
+		return ok
+	case "uses":
+		for _, sharedPart := range ctx.usage[template.ID()] {
+			if value == "" || strings.EqualFold(sharedPart, n.Value) {
+				return true
+			}
+		}
+		return false
+	case "part", "text_part":
+		for _, textPart := range manager.GetTextParts(template) {
+			if value == "" || strings.EqualFold(textPart.Name, n.Value) {
+				return true
+			}
+		}
+		return false
+	default:
+		setting, ok := template.Config[n.Key]
+		return ok && (value == "" || configValueMatches(setting, value))
+	}
+}
+
+// configValueMatches compares a config.json value with a lowercased query value. Arrays match
+// when an element matches and objects when they have the value as key.
+func configValueMatches(setting interface{}, value string) bool {
+	switch v := setting.(type) {
+	case nil:
+		return value == "null"
+	case string:
+		return strings.ToLower(v) == value
+	case bool:
+		return strconv.FormatBool(v) == value
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64) == value
+	case []interface{}:
+		for _, element := range v {
+			if configValueMatches(element, value) {
+				return true
+			}
+		}
+		return false
+	case map[string]interface{}:
+		for key := range v {
+			if strings.ToLower(key) == value {
+				return true
+			}
+		}
+		return false
+	default:
+		return fmt.Sprint(v) == value
+	}
+}
+
+// isSet reports whether a config value counts as enabled for a bare key term.
+func isSet(setting interface{}) bool {
+	switch v := setting.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case string:
+		return v != ""
+	case float64:
+		return v != 0
+	case []interface{}:
+		return len(v) > 0
+	case map[string]interface{}:
+		return len(v) > 0
+	default:
+		return true
+	}
+}
@@ -0,0 +1,133 @@
+package template
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rufex/sftui/internal/models"
+)
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"", ""},
+		{"balance", "balance"},
+		{"type:rt public:true", "(AND type:rt public:true)"},
+		{"type:rt -externally_managed", "(AND type:rt (NOT externally_managed))"},
+		{"type:rt NOT firm:1001", "(AND type:rt (NOT firm:1001))"},
+		{"type:rt OR type:at published", "(OR type:rt (AND type:at published))"},
+		{"type:rt AND (uses:sp_1 | uses:sp_2)", "(AND type:rt (OR uses:sp_1 uses:sp_2))"},
+		{"-(a OR b)", "(NOT (OR a b))"},
+		{`name_en:"Balance sheet" a-b`, `(AND name_en:"Balance sheet" a-b)`},
+		{`"key:value"`, "key:value"},
+		{"reconciliation_type:only_reconciled_with_data", "reconciliation_type:only_reconciled_with_data"},
+
+		// Unfinished queries parse as far as they go
+		{"(type:rt OR type:at", "(OR type:rt type:at)"},
+		{"type:rt OR", "type:rt"},
+		{"type:rt -", "type:rt"},
+		{"type:", `type:""`},
+		{`name_en:"Balance`, "name_en:Balance"},
+	}
+
+	for _, test := range tests {
+		query, err := ParseQuery(test.input)
+		if err != nil {
+			t.Errorf("ParseQuery(%q) failed: %v", test.input, err)
+			continue
+		}
+		if actual := query.String(); actual != test.expected {
+			t.Errorf("ParseQuery(%q) = %s, expected %s", test.input, actual, test.expected)
+		}
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"type:rt)", "unexpected ) at position 8"},
+		{"OR type:rt", "OR at position 1 needs a term before it"},
+		{"a OR OR b", "OR at position 6 needs a term before it"},
+	}
+
+	for _, test := range tests {
+		_, err := ParseQuery(test.input)
+		if err == nil || !strings.Contains(err.Error(), test.expected) {
+			t.Errorf("ParseQuery(%q) error = %v, expected %q", test.input, err, test.expected)
+		}
+	}
+}
+
+func TestQueryMatch(t *testing.T) {
+	templates := []models.Template{
+		{Name: "rt_public", Category: "reconciliation_texts", Path: "reconciliation_texts/rt_public", Config: map[string]interface{}{
+			"handle":              "public_handle",
+			"public":              true,
+			"reconciliation_type": "only_reconciled_with_data",
+			"id":                  map[string]interface{}{"1001": 11},
+			"text_parts":          map[string]interface{}{"notes": "text_parts/notes.liquid"},
+		}},
+		{Name: "rt_managed", Category: "reconciliation_texts", Path: "reconciliation_texts/rt_managed", Config: map[string]interface{}{
+			"public":                 false,
+			"externally_managed":     true,
+			"id":                     map[string]interface{}{"2002": 22},
+			"partner_id":             map[string]interface{}{"25": 5},
+			"virtual_account_number": float64(42),
+		}},
+		{Name: "at_1", Category: "account_templates", Path: "account_templates/at_1", Config: map[string]interface{}{
+			"account_range": []interface{}{"1", "2"},
+		}},
+	}
+	usage := map[models.TemplateID][]string{
+		templates[0].ID(): {"shared_part_1"},
+		templates[2].ID(): {"shared_part_2"},
+	}
+
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{"", "rt_public,rt_managed,at_1"},
+		{"type:rt", "rt_public,rt_managed"},
+		{"type:account", "at_1"},
+		{"public:true", "rt_public"},
+		{"type:rt -externally_managed", "rt_public"},
+		{"reconciliation_type:only_reconciled_with_data", "rt_public"},
+		{"firm:1001", "rt_public"},
+		{"firm:", "rt_public,rt_managed"},
+		{"-firm:1001", "rt_managed,at_1"},
+		{"partner:25", "rt_managed"},
+		{"partner:", "rt_managed"},
+		{"uses:shared_part_1 OR uses:shared_part_2", "rt_public,at_1"},
+		{"part:notes", "rt_public"},
+		{"handle:public_handle", "rt_public"},
+		{"virtual_account_number:42", "rt_managed"},
+		{"account_range:2", "at_1"},
+		{"managed", "rt_managed"},
+		{"type:rt (firm:2002 OR part:notes) -public", "rt_managed"},
+		{"NOT type:rt", "at_1"},
+		{"unknown_key:value", ""},
+	}
+
+	for _, test := range tests {
+		query, err := ParseQuery(test.query)
+		if err != nil {
+			t.Errorf("ParseQuery(%q) failed: %v", test.query, err)
+			continue
+		}
+
+		var names []string
+		for _, template := range templates {
+			if query.Match(template, usage) {
+				names = append(names, template.Name)
+			}
+		}
+		if actual := strings.Join(names, ","); actual != test.expected {
+			t.Errorf("Query %q matched %q, expected %q", test.query, actual, test.expected)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package template
+
+import "github.com/rufex/sftui/internal/models"
+
+// Registry indexes a list of templates by ID and by handle. It is a snapshot: build a new one
+// whenever templates are added, removed or reordered.
+type Registry struct {
+	templates []models.Template
+	byID      map[models.TemplateID]int
+	byHandle  map[string][]int
+}
+
+func NewRegistry(templates []models.Template) *Registry {
+	r := &Registry{
+		templates: templates,
+		byID:      make(map[models.TemplateID]int, len(templates)),
+		byHandle:  make(map[string][]int),
+	}
+	for i, template := range templates {
+		r.byID[template.ID()] = i
+		handle := Handle(template)
+		r.byHandle[handle] = append(r.byHandle[handle], i)
+	}
+	return r
+}
+
+// Handle returns the handle the Silverfin CLI knows a template by: the handle key of a
+// reconciliation text, or the directory name of other templates.
+func Handle(template models.Template) string {
+	if handle, ok := template.Config["handle"].(string); ok && handle != "" && template.Category == "reconciliation_texts" {
+		return handle
+	}
+	return template.Name
+}
+
+// Indexes reports whether the registry was built from exactly this slice, so its positions
+// are still valid for it.
+func (r *Registry) Indexes(templates []models.Template) bool {
+	if len(templates) != len(r.templates) {
+		return false
+	}
+	return len(templates) == 0 || &templates[0] == &r.templates[0]
+}
+
+// Len returns the number of templates in the registry.
+func (r *Registry) Len() int {
+	return len(r.templates)
+}
+
+// Get returns the template with the given ID.
+func (r *Registry) Get(id models.TemplateID) (models.Template, bool) {
+	index := r.Index(id)
+	if index < 0 {
+		return models.Template{}, false
+	}
+	return r.templates[index], true
+}
+
+// Index returns the position of a template in the list the registry was built from, or -1.
+func (r *Registry) Index(id models.TemplateID) int {
+	if index, ok := r.byID[id]; ok {
+		return index
+	}
+	return -1
+}
+
+// Lookup returns the template of a category with the given handle.
+func (r *Registry) Lookup(category, handle string) (models.Template, bool) {
+	for _, index := range r.byHandle[handle] {
+		if r.templates[index].Category == category {
+			return r.templates[index], true
+		}
+	}
+	return models.Template{}, false
+}
+
+// ByHandle returns the templates of every category with the given handle.
+func (r *Registry) ByHandle(handle string) []models.Template {
+	var templates []models.Template
+	for _, index := range r.byHandle[handle] {
+		templates = append(templates, r.templates[index])
+	}
+	return templates
+}
+
+// SharedPartsUsage maps every template to the shared parts whose used_in lists it. Handles
+// that match no template keep their own ID so the consistency check can report them.
+func (r *Registry) SharedPartsUsage() map[models.TemplateID][]string {
+	usage := make(map[models.TemplateID][]string)
+	for _, sharedPart := range r.templates {
+		if sharedPart.Category != "shared_parts" {
+			continue
+		}
+		usedIn, _ := sharedPart.Config["used_in"].([]interface{})
+		for _, entry := range usedIn {
+			link, ok := entry.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			usedInType, _ := link["type"].(string)
+			handle, ok := link["handle"].(string)
+			category := UsedInCategory(usedInType)
+			if !ok || category == "" {
+				continue
+			}
+
+			id := models.TemplateID{Category: category, Name: handle}
+			if user, ok := r.Lookup(category, handle); ok {
+				id = user.ID()
+			}
+			usage[id] = append(usage[id], sharedPart.Name)
+		}
+	}
+	return usage
+}
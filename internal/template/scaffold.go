@@ -0,0 +1,159 @@
+package template
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// NameLanguages lists the languages of the translated name_<lang> config keys.
+var NameLanguages = []string{"en", "nl", "de", "fr", "es"}
+
+var handlePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// NewTemplate describes a template to scaffold.
+type NewTemplate struct {
+	Category string
+	Handle   string
+	Names    map[string]string // translated names keyed by language, empty ones fall back to English
+}
+
+// ScaffoldTemplate creates the directory of a new template under rootPath with a config.json
+// holding the default keys of its category, an empty main Liquid file, a text_parts directory
+// and, for reconciliation texts, an empty Liquid test file. It returns the template directory.
+// The files are recorded as one edit, so undoing it removes them again.
+func (c *ConfigManager) ScaffoldTemplate(rootPath string, spec NewTemplate) (string, error) {
+	if !handlePattern.MatchString(spec.Handle) {
+		return "", fmt.Errorf("invalid handle %q: use letters, digits, - and _", spec.Handle)
+	}
+
+	config, err := defaultTemplateConfig(spec)
+	if err != nil {
+		return "", err
+	}
+
+	templateDir := filepath.Join(rootPath, spec.Category, spec.Handle)
+	if _, err := os.Stat(templateDir); err == nil {
+		return "", fmt.Errorf("%s already exists", templateDir)
+	}
+
+	c.beginEdit(fmt.Sprintf("Create template %s in %s", spec.Handle, spec.Category))
+	defer c.endEdit()
+	if err := c.writeScaffold(templateDir, spec, config); err != nil {
+		os.RemoveAll(templateDir)
+		return "", err
+	}
+	return templateDir, nil
+}
+
+func (c *ConfigManager) writeScaffold(templateDir string, spec NewTemplate, config map[string]interface{}) error {
+	if err := os.MkdirAll(templateDir, 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := c.createFile(filepath.Join(templateDir, "config.json"), append(data, '\n')); err != nil {
+		return err
+	}
+
+	if err := c.createFile(filepath.Join(templateDir, config["text"].(string)), nil); err != nil {
+		return err
+	}
+
+	if spec.Category == "shared_parts" {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Join(templateDir, "text_parts"), 0755); err != nil {
+		return err
+	}
+
+	if test, ok := config["test"].(string); ok {
+		testFile := filepath.Join(templateDir, test)
+		if err := os.MkdirAll(filepath.Dir(testFile), 0755); err != nil {
+			return err
+		}
+		if err := c.createFile(testFile, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createFile writes a new file of the open edit.
+func (c *ConfigManager) createFile(path string, data []byte) error {
+	c.track(path)
+	return WriteFileAtomic(path, data, 0644)
+}
+
+// defaultTemplateConfig returns the config.json keys a new template of the given category starts with.
+func defaultTemplateConfig(spec NewTemplate) (map[string]interface{}, error) {
+	config := map[string]interface{}{
+		"id":                 map[string]interface{}{},
+		"partner_id":         map[string]interface{}{},
+		"externally_managed": true,
+	}
+
+	switch spec.Category {
+	case "reconciliation_texts":
+		addTranslatedNames(config, spec)
+		config["handle"] = spec.Handle
+		config["test"] = fmt.Sprintf("tests/%s_liquid_test.yml", spec.Handle)
+		config["auto_hide_formula"] = ""
+		config["virtual_account_number"] = ""
+		config["reconciliation_type"] = "only_reconciled_with_data"
+		config["public"] = false
+		config["allow_duplicate_reconciliations"] = false
+		config["is_active"] = true
+		config["published"] = true
+		config["hide_code"] = true
+		config["use_full_width"] = false
+		config["downloadable_as_docx"] = false
+		config["text"] = "main.liquid"
+		config["text_parts"] = map[string]interface{}{}
+	case "account_templates":
+		addTranslatedNames(config, spec)
+		config["account_range"] = ""
+		config["mapping_list_ranges"] = []interface{}{}
+		config["published"] = true
+		config["hide_code"] = true
+		config["text"] = "main.liquid"
+		config["text_parts"] = map[string]interface{}{}
+	case "export_files":
+		addTranslatedNames(config, spec)
+		config["name"] = spec.Handle
+		config["file_name"] = ""
+		config["encoding"] = "UTF-8"
+		config["published"] = true
+		config["text"] = "main.liquid"
+		config["text_parts"] = map[string]interface{}{}
+	case "shared_parts":
+		config["name"] = spec.Handle
+		config["text"] = spec.Handle + ".liquid"
+		config["used_in"] = []interface{}{}
+	default:
+		return nil, fmt.Errorf("unknown category %q", spec.Category)
+	}
+
+	return config, nil
+}
+
+func addTranslatedNames(config map[string]interface{}, spec NewTemplate) {
+	english := spec.Names["en"]
+	if english == "" {
+		english = spec.Handle
+	}
+
+	for _, lang := range NameLanguages {
+		name := spec.Names[lang]
+		if name == "" {
+			name = english
+		}
+		config["name_"+lang] = name
+	}
+}
@@ -0,0 +1,110 @@
+package template
+
+import (
+	"fmt"
+
+	"github.com/rufex/sftui/internal/models"
+)
+
+// UsedInType returns the used_in type of a template category, or "" when templates of the
+// category cannot use shared parts.
+func UsedInType(category string) string {
+	switch category {
+	case "reconciliation_texts":
+		return "reconciliationText"
+	case "account_templates":
+		return "accountTemplate"
+	case "export_files":
+		return "exportFile"
+	default:
+		return ""
+	}
+}
+
+// UsedInCategory returns the template category of a used_in type, or "" when it is unknown.
+func UsedInCategory(usedInType string) string {
+	switch usedInType {
+	case "reconciliation_text", "reconciliationText", "reconciliation":
+		return "reconciliation_texts"
+	case "export_file", "exportFile":
+		return "export_files"
+	case "account_template", "accountTemplate":
+		return "account_templates"
+	default:
+		return ""
+	}
+}
+
+// LinkSharedPart adds the template to the used_in array of the shared part's config.json, by
+// the handle the Silverfin CLI knows it by. It reports whether used_in changed: linking a
+// template that is already listed does nothing. It fails with ErrConfigModified when
+// config.json changed on disk since the shared part was loaded.
+func (c *ConfigManager) LinkSharedPart(sharedPart, template models.Template) (bool, error) {
+	usedInType := UsedInType(template.Category)
+	if usedInType == "" {
+		return false, fmt.Errorf("%s templates cannot use shared parts", template.Category)
+	}
+
+	c.beginEdit(fmt.Sprintf("Link %s to %s", Handle(template), sharedPart.Name))
+	defer c.endEdit()
+	file, err := c.loadTemplateConfig(sharedPart)
+	if err != nil {
+		return false, err
+	}
+
+	usedIn, _ := file.Values["used_in"].([]interface{})
+	for _, usageInterface := range usedIn {
+		if usesTemplate(usageInterface, template) {
+			return false, nil
+		}
+	}
+
+	file.Values["used_in"] = append(usedIn, map[string]interface{}{
+		"type":   usedInType,
+		"handle": Handle(template),
+	})
+	if err := c.saveTemplateConfig(file); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// UnlinkSharedPart removes the template from the used_in array of the shared part's config.json.
+// It reports whether used_in changed.
+func (c *ConfigManager) UnlinkSharedPart(sharedPart, template models.Template) (bool, error) {
+	c.beginEdit(fmt.Sprintf("Unlink %s from %s", Handle(template), sharedPart.Name))
+	defer c.endEdit()
+	file, err := c.loadTemplateConfig(sharedPart)
+	if err != nil {
+		return false, err
+	}
+
+	usedIn, _ := file.Values["used_in"].([]interface{})
+	kept := make([]interface{}, 0, len(usedIn))
+	for _, usageInterface := range usedIn {
+		if !usesTemplate(usageInterface, template) {
+			kept = append(kept, usageInterface)
+		}
+	}
+
+	if len(kept) == len(usedIn) {
+		return false, nil
+	}
+	file.Values["used_in"] = kept
+	if err := c.saveTemplateConfig(file); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// usesTemplate reports whether a used_in entry points at the template, by its handle or, as
+// older entries do, by its directory name.
+func usesTemplate(usageInterface interface{}, template models.Template) bool {
+	usage, ok := usageInterface.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	usedInType, _ := usage["type"].(string)
+	handle, _ := usage["handle"].(string)
+	return UsedInCategory(usedInType) == template.Category && (handle == Handle(template) || handle == template.Name)
+}
@@ -0,0 +1,428 @@
+package template
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/rufex/sftui/internal/models"
+)
+
+type TextPart struct {
+	Name string
+	Path string
+}
+
+// GetTextParts returns the text parts of a template in the order of its config.json.
+func (m *Manager) GetTextParts(template models.Template) []TextPart {
+	textParts, ok := template.Config["text_parts"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	var parts []TextPart
+	for _, name := range textPartOrder(textParts, template.ConfigData) {
+		if path, ok := textParts[name].(string); ok {
+			parts = append(parts, TextPart{Name: name, Path: path})
+		}
+	}
+	return parts
+}
+
+// textPartOrder returns the names of the text parts in the order config.json data lists them.
+// Parts it does not list, as in templates built in memory, follow sorted by name.
+func textPartOrder(textParts map[string]interface{}, data []byte) []string {
+	var names []string
+	listed := make(map[string]bool, len(textParts))
+	for _, name := range objectKeys(data, "text_parts") {
+		if _, ok := textParts[name]; ok && !listed[name] {
+			listed[name] = true
+			names = append(names, name)
+		}
+	}
+
+	var unlisted []string
+	for name := range textParts {
+		if !listed[name] {
+			unlisted = append(unlisted, name)
+		}
+	}
+	sort.Strings(unlisted)
+	return append(names, unlisted...)
+}
+
+// GetMainLiquidFile returns the main Liquid file of a template relative to its directory.
+// Shared parts are named after themselves, other templates default to main.liquid.
+func (m *Manager) GetMainLiquidFile(template models.Template) string {
+	if text, ok := template.Config["text"].(string); ok && text != "" {
+		return text
+	}
+	if template.Category == "shared_parts" {
+		return template.Name + ".liquid"
+	}
+	return "main.liquid"
+}
+
+// GetMainLiquidPath returns the path of the main Liquid file of a template.
+func (m *Manager) GetMainLiquidPath(template models.Template) string {
+	return filepath.Join(template.Path, m.GetMainLiquidFile(template))
+}
+
+// LiquidFiles returns the main Liquid file and the text part files of a template, relative to
+// its directory.
+func (m *Manager) LiquidFiles(template models.Template) []string {
+	files := []string{m.GetMainLiquidFile(template)}
+	for _, part := range m.GetTextParts(template) {
+		files = append(files, part.Path)
+	}
+	return files
+}
+
+// GetTextPartPath returns the path of a text part file of a template.
+func (m *Manager) GetTextPartPath(template models.Template, part TextPart) string {
+	return filepath.Join(template.Path, part.Path)
+}
+
+// AddTextPart registers a new text part in config.json and creates its empty .liquid file.
+// Like every text part edit, it fails with ErrConfigModified when config.json changed on disk
+// since the template was loaded.
+func (c *ConfigManager) AddTextPart(template models.Template, name, path string) error {
+	c.beginEdit(fmt.Sprintf("Add text part %s to %s", name, filepath.Base(template.Path)))
+	defer c.endEdit()
+	return c.createTextPart(template, name, path, nil)
+}
+
+// DuplicateTextPart copies an existing text part to a new name and path.
+func (c *ConfigManager) DuplicateTextPart(template models.Template, sourceName, name, path string) error {
+	c.beginEdit(fmt.Sprintf("Duplicate text part %s of %s as %s", sourceName, filepath.Base(template.Path), name))
+	defer c.endEdit()
+	config, err := c.loadTemplateConfig(template)
+	if err != nil {
+		return err
+	}
+
+	sourcePath, ok := textPartsOf(config.Values)[sourceName].(string)
+	if !ok {
+		return fmt.Errorf("text part %q not found", sourceName)
+	}
+
+	content, err := os.ReadFile(filepath.Join(template.Path, sourcePath))
+	if err != nil {
+		return err
+	}
+
+	return c.createTextPart(template, name, path, content)
+}
+
+func (c *ConfigManager) createTextPart(template models.Template, name, path string, content []byte) error {
+	config, err := c.loadTemplateConfig(template)
+	if err != nil {
+		return err
+	}
+
+	textParts := textPartsOf(config.Values)
+	path, err = checkTextPart(textParts, "", name, path)
+	if err != nil {
+		return err
+	}
+
+	file := filepath.Join(template.Path, path)
+	if _, err := os.Stat(file); err == nil {
+		return fmt.Errorf("file %s already exists", path)
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+	c.track(file)
+	if err := WriteFileAtomic(file, content, 0644); err != nil {
+		return err
+	}
+
+	textParts[name] = path
+	config.Values["text_parts"] = textParts
+	if err := c.saveTemplateConfig(config); err != nil {
+		os.Remove(file)
+		return err
+	}
+	return nil
+}
+
+// DeleteTextPart removes a text part from config.json and deletes its file.
+func (c *ConfigManager) DeleteTextPart(template models.Template, name string) error {
+	c.beginEdit(fmt.Sprintf("Delete text part %s of %s", name, filepath.Base(template.Path)))
+	defer c.endEdit()
+	config, err := c.loadTemplateConfig(template)
+	if err != nil {
+		return err
+	}
+
+	textParts := textPartsOf(config.Values)
+	path, ok := textParts[name].(string)
+	if !ok {
+		return fmt.Errorf("text part %q not found", name)
+	}
+
+	delete(textParts, name)
+	if err := c.saveTemplateConfig(config); err != nil {
+		return err
+	}
+
+	c.track(filepath.Join(template.Path, path))
+	if err := os.Remove(filepath.Join(template.Path, path)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// MoveTextPart moves a text part of a template by delta places in the text_parts of its
+// config.json, keeping it within the list.
+func (c *ConfigManager) MoveTextPart(template models.Template, name string, delta int) error {
+	c.beginEdit(fmt.Sprintf("Move text part %s of %s", name, filepath.Base(template.Path)))
+	defer c.endEdit()
+	config, err := c.loadTemplateConfig(template)
+	if err != nil {
+		return err
+	}
+
+	textParts := textPartsOf(config.Values)
+	if _, ok := textParts[name]; !ok {
+		return fmt.Errorf("text part %q not found", name)
+	}
+
+	names := textPartOrder(textParts, config.data)
+	index := slices.Index(names, name)
+	position := min(max(0, index+delta), len(names)-1)
+	if position == index {
+		return nil
+	}
+	names = slices.Insert(slices.Delete(names, index, index+1), position, name)
+
+	config.orderKeys("text_parts", names)
+	return c.saveTemplateConfig(config)
+}
+
+// TextPartIncludedFrom returns the Liquid files of a template (relative to its directory) that
+// include the given text part.
+func (c *ConfigManager) TextPartIncludedFrom(templatePath, name string) ([]string, error) {
+	config, err := loadConfigFile(filepath.Join(templatePath, "config.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	includePattern := textPartIncludePattern(name)
+	var files []string
+	for _, file := range templateLiquidFiles(config.Values) {
+		data, err := os.ReadFile(filepath.Join(templatePath, file))
+		if err != nil {
+			continue
+		}
+		if includePattern.Match(data) {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+// RenameTextPart renames a text part of a template and moves its file to newPath (relative to
+// the template directory). Include statements referencing the old name in the main Liquid file
+// and the other text parts are updated. It returns the number of files whose includes changed.
+func (c *ConfigManager) RenameTextPart(template models.Template, oldName, newName, newPath string) (int, error) {
+	c.beginEdit(fmt.Sprintf("Rename text part %s of %s to %s", oldName, filepath.Base(template.Path), newName))
+	defer c.endEdit()
+	config, err := c.loadTemplateConfig(template)
+	if err != nil {
+		return 0, err
+	}
+
+	textParts := textPartsOf(config.Values)
+	oldPath, ok := textParts[oldName].(string)
+	if !ok {
+		return 0, fmt.Errorf("text part %q not found", oldName)
+	}
+
+	newPath, err = checkTextPart(textParts, oldName, newName, newPath)
+	if err != nil {
+		return 0, err
+	}
+
+	oldFile := filepath.Join(template.Path, oldPath)
+	newFile := filepath.Join(template.Path, newPath)
+	moved := false
+	if filepath.Clean(oldFile) != filepath.Clean(newFile) {
+		if _, err := os.Stat(newFile); err == nil {
+			return 0, fmt.Errorf("file %s already exists", newPath)
+		}
+		if _, err := os.Stat(oldFile); err == nil {
+			if err := os.MkdirAll(filepath.Dir(newFile), 0755); err != nil {
+				return 0, err
+			}
+			c.track(oldFile)
+			c.track(newFile)
+			if err := os.Rename(oldFile, newFile); err != nil {
+				return 0, err
+			}
+			moved = true
+		}
+	}
+
+	delete(textParts, oldName)
+	textParts[newName] = newPath
+	if err := c.saveTemplateConfig(config); err != nil {
+		// Keep the file where config.json expects it
+		if moved {
+			os.Rename(newFile, oldFile)
+		}
+		return 0, err
+	}
+
+	if oldName == newName {
+		return 0, nil
+	}
+	return c.replaceTextPartIncludes(template.Path, config.Values, oldName, newName)
+}
+
+// replaceTextPartIncludes rewrites {% include "parts/<old>" %} to the new name in every Liquid
+// file of the template and returns the number of files changed.
+func (c *ConfigManager) replaceTextPartIncludes(templatePath string, config map[string]interface{}, oldName, newName string) (int, error) {
+	includePattern := textPartIncludePattern(oldName)
+
+	changed := 0
+	for _, file := range templateLiquidFiles(config) {
+		path := filepath.Join(templatePath, file)
+		// Write through symlinks instead of replacing them with a regular file
+		if resolved, err := filepath.EvalSymlinks(path); err == nil {
+			path = resolved
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+
+		updated := includePattern.ReplaceAll(data, []byte("${1}parts/"+newName+"${2}"))
+		if string(updated) == string(data) {
+			continue
+		}
+
+		c.track(path)
+		if err := WriteFileAtomic(path, updated, info.Mode().Perm()); err != nil {
+			return changed, err
+		}
+		changed++
+	}
+
+	return changed, nil
+}
+
+func textPartIncludePattern(name string) *regexp.Regexp {
+	return regexp.MustCompile(`(\{%-?\s*include\s+["'])parts/` + regexp.QuoteMeta(name) + `(["'])`)
+}
+
+// textPartsOf returns the text_parts map of a config, adding an empty one when missing.
+func textPartsOf(config map[string]interface{}) map[string]interface{} {
+	textParts, ok := config["text_parts"].(map[string]interface{})
+	if !ok {
+		textParts = make(map[string]interface{})
+		config["text_parts"] = textParts
+	}
+	return textParts
+}
+
+// templateLiquidFiles lists the main Liquid file and the text part files of a template config.
+func templateLiquidFiles(config map[string]interface{}) []string {
+	files := []string{"main.liquid"}
+	if text, ok := config["text"].(string); ok && text != "" {
+		files[0] = text
+	}
+	textParts, _ := config["text_parts"].(map[string]interface{})
+	for _, pathInterface := range textParts {
+		if path, ok := pathInterface.(string); ok {
+			files = append(files, path)
+		}
+	}
+	return files
+}
+
+// checkTextPart validates a new text part name and path against the existing parts, ignoring
+// the part named ignore. It returns the cleaned path.
+func checkTextPart(textParts map[string]interface{}, ignore, name, path string) (string, error) {
+	if strings.TrimSpace(name) == "" {
+		return "", fmt.Errorf("text part name cannot be empty")
+	}
+	if strings.ContainsAny(name, `/\"'`) {
+		return "", fmt.Errorf("text part name %q contains invalid characters", name)
+	}
+	if strings.TrimSpace(path) == "" {
+		return "", fmt.Errorf("text part path cannot be empty")
+	}
+	if filepath.IsAbs(path) {
+		return "", fmt.Errorf("text part path must be relative to the template")
+	}
+
+	path = filepath.ToSlash(filepath.Clean(path))
+	if path == ".." || strings.HasPrefix(path, "../") {
+		return "", fmt.Errorf("text part path must stay inside the template")
+	}
+
+	for existingName, pathInterface := range textParts {
+		if existingName == ignore {
+			continue
+		}
+		if existingName == name {
+			return "", fmt.Errorf("text part %q already exists", name)
+		}
+		if existingPath, ok := pathInterface.(string); ok && filepath.ToSlash(filepath.Clean(existingPath)) == path {
+			return "", fmt.Errorf("path %s is already used by text part %q", path, existingName)
+		}
+	}
+	return path, nil
+}
+
+// objectKeys returns the keys of the object at a top-level field of a JSON document, in the
+// order they are written in.
+func objectKeys(data []byte, field string) []string {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return nil
+	}
+	for decoder.More() {
+		key, err := decoder.Token()
+		if err != nil {
+			return nil
+		}
+		if key != field {
+			var skipped json.RawMessage
+			if err := decoder.Decode(&skipped); err != nil {
+				return nil
+			}
+			continue
+		}
+
+		if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+			return nil
+		}
+		var keys []string
+		for decoder.More() {
+			key, err := decoder.Token()
+			if err != nil {
+				return nil
+			}
+			keys = append(keys, key.(string))
+			var skipped json.RawMessage
+			if err := decoder.Decode(&skipped); err != nil {
+				return nil
+			}
+		}
+		return keys
+	}
+	return nil
+}
@@ -0,0 +1,16 @@
+package template
+
+import "testing"
+
+func TestTemplateLiquidFilesLeavesConfigUnchanged(t *testing.T) {
+	config := map[string]interface{}{"text": "main.liquid"}
+
+	files := templateLiquidFiles(config)
+
+	if len(files) != 1 || files[0] != "main.liquid" {
+		t.Errorf("templateLiquidFiles() = %v, want [main.liquid]", files)
+	}
+	if _, ok := config["text_parts"]; ok {
+		t.Errorf("Expected listing the files not to add text_parts, got %v", config)
+	}
+}
@@ -0,0 +1,238 @@
+package template
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/rufex/sftui/internal/models"
+)
+
+type fieldKind int
+
+const (
+	stringField fieldKind = iota
+	boolField
+	idMapField
+	textPartsField
+	arrayField
+	usedInField
+	enumField
+)
+
+type fieldRule struct {
+	key         string
+	kind        fieldKind
+	required    bool     // missing key is an error
+	recommended bool     // missing key is a warning
+	values      []string // allowed values of enum fields
+}
+
+// ReconciliationTypes lists the allowed values of reconciliation_type.
+var ReconciliationTypes = []string{"can_be_reconciled_without_data", "reconciliation_not_necessary", "only_reconciled_with_data"}
+
+var commonRules = []fieldRule{
+	{key: "id", kind: idMapField},
+	{key: "partner_id", kind: idMapField},
+	{key: "externally_managed", kind: boolField},
+	{key: "published", kind: boolField},
+	{key: "hide_code", kind: boolField},
+	{key: "text", kind: stringField},
+}
+
+var translatedNameRules = []fieldRule{
+	{key: "name_nl", kind: stringField, recommended: true},
+	{key: "name_fr", kind: stringField, recommended: true},
+	{key: "name_de", kind: stringField, recommended: true},
+	{key: "name_es", kind: stringField, recommended: true},
+}
+
+// categoryRules describes the config.json keys of each category; unknown keys are not checked.
+var categoryRules = map[string][]fieldRule{
+	"reconciliation_texts": append(append([]fieldRule{
+		{key: "handle", kind: stringField, required: true},
+		{key: "name_en", kind: stringField, required: true},
+		{key: "text", kind: stringField, required: true},
+		{key: "text_parts", kind: textPartsField, required: true},
+		{key: "reconciliation_type", kind: enumField, values: ReconciliationTypes},
+		{key: "test", kind: stringField},
+		{key: "auto_hide_formula", kind: stringField},
+		{key: "virtual_account_number", kind: stringField},
+		{key: "public", kind: boolField},
+		{key: "allow_duplicate_reconciliations", kind: boolField},
+		{key: "is_active", kind: boolField},
+		{key: "use_full_width", kind: boolField},
+		{key: "downloadable_as_docx", kind: boolField},
+	}, translatedNameRules...), commonRules...),
+	"account_templates": append([]fieldRule{
+		{key: "text_parts", kind: textPartsField, required: true},
+		{key: "name_en", kind: stringField},
+		{key: "name_nl", kind: stringField},
+		{key: "name_fr", kind: stringField},
+		{key: "account_range", kind: stringField},
+		{key: "mapping_list_ranges", kind: arrayField},
+	}, commonRules...),
+	"export_files": append([]fieldRule{
+		{key: "text_parts", kind: textPartsField, required: true},
+		{key: "name", kind: stringField},
+		{key: "name_en", kind: stringField},
+		{key: "name_nl", kind: stringField},
+		{key: "name_fr", kind: stringField},
+		{key: "file_name", kind: stringField},
+		{key: "encoding", kind: stringField},
+	}, commonRules...),
+	"shared_parts": append([]fieldRule{
+		{key: "name", kind: stringField, required: true},
+		{key: "text", kind: stringField, required: true},
+		{key: "used_in", kind: usedInField},
+	}, commonRules...),
+}
+
+// ValidateConfig checks a config.json of the given category against the keys the Silverfin
+// CLI expects. Issues are sorted by field.
+func ValidateConfig(category string, config map[string]interface{}) []models.ValidationIssue {
+	var issues []models.ValidationIssue
+	seen := make(map[string]bool)
+
+	for _, rule := range categoryRules[category] {
+		// Category rules come first and take precedence over the common rules
+		if seen[rule.key] {
+			continue
+		}
+		seen[rule.key] = true
+
+		value, exists := config[rule.key]
+		if !exists {
+			if rule.required {
+				issues = append(issues, models.ValidationIssue{Field: rule.key, Message: "required key is missing", Severity: models.LogError})
+			} else if rule.recommended {
+				issues = append(issues, models.ValidationIssue{Field: rule.key, Message: "translation is missing", Severity: models.LogWarn})
+			}
+			continue
+		}
+
+		if message := checkField(rule, value); message != "" {
+			issues = append(issues, models.ValidationIssue{Field: rule.key, Message: message, Severity: models.LogError})
+		}
+	}
+
+	if category == "reconciliation_texts" {
+		if handle, ok := config["handle"].(string); ok && handle == "" {
+			issues = append(issues, models.ValidationIssue{Field: "handle", Message: "handle is empty", Severity: models.LogError})
+		}
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		return issues[i].Field < issues[j].Field
+	})
+	return issues
+}
+
+func checkField(rule fieldRule, value interface{}) string {
+	switch rule.kind {
+	case stringField:
+		if _, ok := value.(string); !ok {
+			return fmt.Sprintf("expected a string, got %s", jsonType(value))
+		}
+	case boolField:
+		if _, ok := value.(bool); !ok {
+			return fmt.Sprintf("expected true or false, got %s", jsonType(value))
+		}
+	case arrayField:
+		if _, ok := value.([]interface{}); !ok {
+			return fmt.Sprintf("expected an array, got %s", jsonType(value))
+		}
+	case enumField:
+		str, ok := value.(string)
+		if !ok {
+			return fmt.Sprintf("expected a string, got %s", jsonType(value))
+		}
+		for _, allowed := range rule.values {
+			if str == allowed {
+				return ""
+			}
+		}
+		return fmt.Sprintf("%q is not one of %v", str, rule.values)
+	case idMapField:
+		return checkIDMap(value)
+	case textPartsField:
+		parts, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Sprintf("expected an object of text part paths, got %s", jsonType(value))
+		}
+		for _, name := range sortedKeys(parts) {
+			if _, ok := parts[name].(string); !ok {
+				return fmt.Sprintf("path of text part %q must be a string", name)
+			}
+		}
+	case usedInField:
+		entries, ok := value.([]interface{})
+		if !ok {
+			return fmt.Sprintf("expected an array, got %s", jsonType(value))
+		}
+		for i, entryInterface := range entries {
+			entry, ok := entryInterface.(map[string]interface{})
+			if !ok {
+				return fmt.Sprintf("entry %d must be an object", i)
+			}
+			usedInType, _ := entry["type"].(string)
+			if UsedInCategory(usedInType) == "" {
+				return fmt.Sprintf("entry %d has unknown type %q", i, usedInType)
+			}
+			if handle, _ := entry["handle"].(string); handle == "" {
+				return fmt.Sprintf("entry %d has no handle", i)
+			}
+			if ids, exists := entry["id"]; exists {
+				if message := checkIDMap(ids); message != "" {
+					return fmt.Sprintf("entry %d id: %s", i, message)
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// checkIDMap checks an id or partner_id map: numeric firm/partner IDs mapped to numeric template IDs.
+func checkIDMap(value interface{}) string {
+	ids, ok := value.(map[string]interface{})
+	if !ok {
+		return fmt.Sprintf("expected an object keyed by firm ID, got %s", jsonType(value))
+	}
+	for _, key := range sortedKeys(ids) {
+		if _, err := strconv.Atoi(key); err != nil {
+			return fmt.Sprintf("key %q is not a numeric ID", key)
+		}
+		if _, ok := ids[key].(float64); !ok {
+			return fmt.Sprintf("ID of %s must be a number, got %s", key, jsonType(ids[key]))
+		}
+	}
+	return ""
+}
+
+func jsonType(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "a string"
+	case bool:
+		return "a boolean"
+	case float64:
+		return "a number"
+	case []interface{}:
+		return "an array"
+	case map[string]interface{}:
+		return "an object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,75 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rufex/sftui/internal/models"
+)
+
+// ConsistencyTitle returns the title of the consistency panel with the number of issues.
+func (r *Renderer) ConsistencyTitle(m *models.Model) string {
+	fixable := 0
+	for _, issue := range m.ConsistencyIssues {
+		if issue.Fixable() {
+			fixable++
+		}
+	}
+
+	switch {
+	case len(m.ConsistencyIssues) == 0:
+		return "Consistency"
+	case fixable == 0:
+		return fmt.Sprintf("Consistency - %d issues", len(m.ConsistencyIssues))
+	default:
+		return fmt.Sprintf("Consistency - %d issues, %d fixable (f to fix)", len(m.ConsistencyIssues), fixable)
+	}
+}
+
+// ConsistencyView lists the issues of the last consistency check.
+func (r *Renderer) ConsistencyView(m *models.Model, maxHeight, maxWidth int) string {
+	if len(m.ConsistencyIssues) == 0 {
+		return "used_in metadata matches the Liquid includes of every template"
+	}
+
+	endIdx := len(m.ConsistencyIssues)
+	if maxHeight > 0 {
+		endIdx = min(endIdx, m.ConsistencyOffset+maxHeight)
+	}
+
+	var lines []string
+	for i := m.ConsistencyOffset; i < endIdx; i++ {
+		issue := m.ConsistencyIssues[i]
+
+		label := consistencyKindLabel(issue.Kind)
+		line := fmt.Sprintf("%-18s %s", label, issue.Message())
+		if maxWidth > 0 {
+			line = r.TruncateText(line, maxWidth-4)
+		}
+
+		switch {
+		case i == m.SelectedIssue:
+			line = models.SelectedItemStyle.Render(line)
+		case !issue.Fixable():
+			line = graphProblemStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func consistencyKindLabel(kind models.ConsistencyKind) string {
+	switch kind {
+	case models.OrphanLink:
+		return "orphan link"
+	case models.MissingLink:
+		return "missing link"
+	case models.UnknownSharedPart:
+		return "unknown shared part"
+	case models.UnknownTemplate:
+		return "unknown template"
+	default:
+		return ""
+	}
+}
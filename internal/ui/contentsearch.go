@@ -0,0 +1,137 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/rufex/sftui/internal/models"
+)
+
+var contentMatchStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("3")) // Yellow
+
+// ContentSearchRows groups sorted content matches under their template and file, with the lines
+// around every match as context.
+func (r *Renderer) ContentSearchRows(matches []models.ContentMatch) []models.ContentRow {
+	var rows []models.ContentRow
+	emitted := 0 // last line of the current file already shown
+	for i, match := range matches {
+		newTemplate := i == 0 || matches[i-1].Template != match.Template
+		if newTemplate {
+			rows = append(rows, models.ContentRow{Template: match.Template, Match: -1})
+		}
+		if newTemplate || matches[i-1].Path != match.Path {
+			rows = append(rows, models.ContentRow{Template: match.Template, File: match.File, Match: -1})
+			emitted = 0
+		}
+
+		if match.Line-1 > emitted && match.Before != "" {
+			rows = append(rows, models.ContentRow{Template: match.Template, File: match.File, Line: match.Line - 1, Text: match.Before, Match: -1})
+		}
+		rows = append(rows, models.ContentRow{Template: match.Template, File: match.File, Line: match.Line, Text: match.Text, Match: i})
+		emitted = match.Line
+
+		nextIsAdjacent := i+1 < len(matches) && matches[i+1].Path == match.Path && matches[i+1].Line <= match.Line+1
+		if !nextIsAdjacent && match.After != "" {
+			rows = append(rows, models.ContentRow{Template: match.Template, File: match.File, Line: match.Line + 1, Text: match.After, Match: -1})
+			emitted = match.Line + 1
+		}
+	}
+	return rows
+}
+
+// ContentSearchTitle returns the title of the content search screen with the query and progress.
+func (r *Renderer) ContentSearchTitle(m *models.Model) string {
+	if m.ContentSearchEditing {
+		return fmt.Sprintf("Content Search: %s_", m.ContentSearchQuery)
+	}
+
+	templates := make(map[models.TemplateID]bool)
+	for _, match := range m.ContentMatches {
+		templates[match.Template] = true
+	}
+	title := fmt.Sprintf("Content Search - %q: %d matches in %d templates", m.ContentSearchQuery, len(m.ContentMatches), len(templates))
+	if m.ContentSearchRunning {
+		title += fmt.Sprintf(" (searching %d/%d files)", m.ContentSearchFiles, m.ContentSearchTotal)
+	}
+	return title
+}
+
+// ContentSearchView lists the content search results as template → file → line.
+func (r *Renderer) ContentSearchView(m *models.Model, maxHeight, maxWidth int) string {
+	if len(m.ContentRows) == 0 {
+		switch {
+		case m.ContentSearchEditing:
+			return "Type the text to find in every main Liquid file, text part and shared part, then press Enter"
+		case m.ContentSearchRunning:
+			return "Searching..."
+		default:
+			return "No matches"
+		}
+	}
+
+	endIdx := len(m.ContentRows)
+	if maxHeight > 0 {
+		endIdx = min(endIdx, m.ContentOffset+maxHeight)
+	}
+
+	var lines []string
+	for i := m.ContentOffset; i < endIdx; i++ {
+		row := m.ContentRows[i]
+
+		var line string
+		switch {
+		case row.File == "":
+			line = fmt.Sprintf("[%s] %s", r.templateManager.GetCategoryPrefix(row.Template.Category), row.Template.Name)
+		case row.Line == 0:
+			line = "  " + row.File
+		case row.Match >= 0:
+			line = fmt.Sprintf("    %4d: %s", row.Line, strings.TrimRight(row.Text, " \t"))
+		default:
+			line = fmt.Sprintf("    %4d  %s", row.Line, strings.TrimRight(row.Text, " \t"))
+		}
+		line = strings.ReplaceAll(line, "\t", "  ")
+		if maxWidth > 0 {
+			line = r.TruncateText(line, maxWidth)
+		}
+
+		switch {
+		case i == m.ContentSelected:
+			line = models.SelectedItemStyle.Render(line)
+		case row.File == "":
+			line = graphHeadingStyle.Render(line)
+		case row.Line == 0 || row.Match < 0:
+			line = models.CategoryStyle.Render(line)
+		default:
+			line = highlightText(line, m.ContentSearchQuery)
+		}
+		lines = append(lines, line)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// highlightText marks every case-insensitive occurrence of query in line.
+func highlightText(line, query string) string {
+	if query == "" {
+		return line
+	}
+
+	lower := strings.ToLower(line)
+	query = strings.ToLower(query)
+	if len(lower) != len(line) {
+		return line // case folding changed the byte offsets
+	}
+	var b strings.Builder
+	for {
+		index := strings.Index(lower, query)
+		if index < 0 {
+			b.WriteString(line)
+			return b.String()
+		}
+		b.WriteString(line[:index])
+		b.WriteString(contentMatchStyle.Render(line[index : index+len(query)]))
+		line, lower = line[index+len(query):], lower[index+len(query):]
+	}
+}
@@ -0,0 +1,130 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rufex/sftui/internal/models"
+	"github.com/rufex/sftui/internal/template"
+)
+
+const (
+	firmMatrixColumnWidth = 14
+	firmMatrixMaxName     = 40
+)
+
+// FirmMatrixTitle returns the title of the firm matrix with the number of templates missing in
+// the highlighted firm or partner.
+func (r *Renderer) FirmMatrixTitle(m *models.Model) string {
+	title := fmt.Sprintf("Firm Matrix - %d templates, %d firms and partners", len(m.FirmMatrixTemplates), len(m.FirmOptions))
+	if m.FirmMatrixColumn >= len(m.FirmOptions) {
+		return title
+	}
+
+	option := m.FirmOptions[m.FirmMatrixColumn]
+	missing := 0
+	for _, id := range m.FirmMatrixTemplates {
+		t, _ := m.TemplateByID(id)
+		if _, ok := template.RemoteID(t, option); !ok {
+			missing++
+		}
+	}
+	return title + fmt.Sprintf(" - %d missing in %s", missing, option.Name)
+}
+
+// FirmMatrixColumns returns how many firm and partner columns fit in the given width.
+func (r *Renderer) FirmMatrixColumns(m *models.Model, maxWidth int) int {
+	return max(1, (maxWidth-4-r.firmMatrixNameWidth(m))/(firmMatrixColumnWidth+1))
+}
+
+// FirmMatrixView shows a row per template and a column per firm and partner, with the ID of the
+// template there or - when it was never imported.
+func (r *Renderer) FirmMatrixView(m *models.Model, maxHeight, maxWidth int) string {
+	if len(m.FirmOptions) == 0 {
+		return "No firms or partners available"
+	}
+	if len(m.FirmMatrixTemplates) == 0 {
+		return "No templates found"
+	}
+
+	nameWidth := r.firmMatrixNameWidth(m)
+	start := m.FirmMatrixColumnOffset
+	end := min(len(m.FirmOptions), start+r.FirmMatrixColumns(m, maxWidth))
+	columns := m.FirmOptions[start:end]
+
+	// Two header lines: the firm or partner name and its type and ID, * marking the default firm
+	names := []string{fmt.Sprintf("%-*s", nameWidth, "Template")}
+	kinds := []string{strings.Repeat(" ", nameWidth)}
+	for i, option := range columns {
+		kind := fmt.Sprintf("%s %s", option.Type, option.ID)
+		if option.ID == m.FirmID && option.Type == firmType(m) {
+			kind += " *"
+		}
+		name := fmt.Sprintf("%-*s", firmMatrixColumnWidth, r.truncateCell(option.Name))
+		kind = fmt.Sprintf("%-*s", firmMatrixColumnWidth, r.truncateCell(kind))
+		if start+i == m.FirmMatrixColumn {
+			name = models.SelectedItemStyle.Render(name)
+			kind = models.SelectedItemStyle.Render(kind)
+		}
+		names = append(names, name)
+		kinds = append(kinds, kind)
+	}
+	lines := []string{
+		models.CategoryStyle.Render(strings.Join(names, " ")),
+		models.CategoryStyle.Render(strings.Join(kinds, " ")),
+	}
+
+	rowsHeight := max(1, maxHeight-len(lines))
+	endIdx := min(len(m.FirmMatrixTemplates), m.FirmMatrixOffset+rowsHeight)
+	missingStyle := logLevelStyle(models.LogError)
+	for i := m.FirmMatrixOffset; i < endIdx; i++ {
+		id := m.FirmMatrixTemplates[i]
+		t, _ := m.TemplateByID(id)
+
+		label := fmt.Sprintf("[%s] %s", r.templateManager.GetCategoryPrefix(id.Category), id.Name)
+		cells := []string{fmt.Sprintf("%-*s", nameWidth, r.truncateName(label, nameWidth))}
+		missing := make([]bool, len(columns))
+		for j, option := range columns {
+			remoteID, ok := template.RemoteID(t, option)
+			if !ok {
+				remoteID = "-"
+				missing[j] = true
+			}
+			cells = append(cells, fmt.Sprintf("%-*s", firmMatrixColumnWidth, r.truncateCell(remoteID)))
+		}
+
+		if i == m.FirmMatrixSelected {
+			lines = append(lines, models.SelectedItemStyle.Render(strings.Join(cells, " ")))
+			continue
+		}
+		for j := range columns {
+			if missing[j] {
+				cells[j+1] = missingStyle.Render(cells[j+1])
+			}
+		}
+		lines = append(lines, strings.Join(cells, " "))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// firmMatrixNameWidth fits the template column to the longest template name.
+func (r *Renderer) firmMatrixNameWidth(m *models.Model) int {
+	width := len("Template")
+	for _, id := range m.FirmMatrixTemplates {
+		width = max(width, len(id.Name)+5) // "[RT] " prefix
+	}
+	return min(width, firmMatrixMaxName)
+}
+
+func (r *Renderer) truncateCell(text string) string {
+	return r.truncateName(text, firmMatrixColumnWidth)
+}
+
+// truncateName shortens text to width characters, ending with ... when it was cut.
+func (r *Renderer) truncateName(text string, width int) string {
+	if len(text) <= width {
+		return text
+	}
+	return text[:width-3] + "..."
+}
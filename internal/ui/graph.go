@@ -0,0 +1,135 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/rufex/sftui/internal/models"
+	"github.com/rufex/sftui/internal/template"
+)
+
+var (
+	graphBranchStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("8")) // Gray
+	graphHeadingStyle = lipgloss.NewStyle().Bold(true)
+	graphProblemStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("1")) // Red
+)
+
+// GraphRows flattens the dependency graph into the rows of the graph screen: every template
+// with its shared parts and their nested shared parts, followed by the unused shared parts.
+func (r *Renderer) GraphRows(graph *template.DependencyGraph) []models.GraphRow {
+	var rows []models.GraphRow
+	for _, tmpl := range graph.Templates {
+		links := graph.Links[tmpl.ID()]
+		if len(links) == 0 {
+			continue
+		}
+
+		rows = append(rows, models.GraphRow{Category: tmpl.Category, Name: tmpl.Name})
+		for i, link := range links {
+			rows = r.appendGraphLink(rows, graph, link, "", i == len(links)-1, true, map[string]bool{})
+		}
+	}
+
+	if unused := graph.UnusedSharedParts(); len(unused) > 0 {
+		rows = append(rows, models.GraphRow{}, models.GraphRow{Name: "Unused shared parts"})
+		for i, name := range unused {
+			branch := "├─ "
+			if i == len(unused)-1 {
+				branch = "└─ "
+			}
+			rows = append(rows, models.GraphRow{Prefix: branch, Category: "shared_parts", Name: name})
+		}
+	}
+
+	return rows
+}
+
+func (r *Renderer) appendGraphLink(rows []models.GraphRow, graph *template.DependencyGraph, link template.SharedPartLink, indent string, last, fromTemplate bool, visited map[string]bool) []models.GraphRow {
+	branch, childIndent := "├─ ", indent+"│  "
+	if last {
+		branch, childIndent = "└─ ", indent+"   "
+	}
+
+	row := models.GraphRow{Prefix: indent + branch, Category: "shared_parts", Name: link.Name}
+	switch {
+	case link.Missing:
+		row.Note, row.Problem = "shared part not found", true
+	case fromTemplate && link.InUsedIn && !link.InLiquid:
+		row.Note, row.Problem = "in used_in, not included in Liquid", true
+	case fromTemplate && link.InLiquid && !link.InUsedIn:
+		row.Note, row.Problem = "included in Liquid, missing from used_in", true
+	case visited[link.Name]:
+		row.Note, row.Problem = "include cycle", true
+	}
+	rows = append(rows, row)
+
+	if link.Missing || visited[link.Name] {
+		return rows
+	}
+
+	visited[link.Name] = true
+	nested := graph.Nested[link.Name]
+	for i, child := range nested {
+		rows = r.appendGraphLink(rows, graph, child, childIndent, i == len(nested)-1, false, visited)
+	}
+	delete(visited, link.Name)
+
+	return rows
+}
+
+// GraphTitle returns the title of the dependency graph screen with the number of problems.
+func (r *Renderer) GraphTitle(m *models.Model) string {
+	problems := 0
+	for _, row := range m.GraphRows {
+		if row.Problem {
+			problems++
+		}
+	}
+	if problems == 0 {
+		return "Dependency Graph"
+	}
+	return fmt.Sprintf("Dependency Graph - %d mismatches", problems)
+}
+
+// GraphView renders the visible rows of the dependency graph screen.
+func (r *Renderer) GraphView(m *models.Model, maxHeight, maxWidth int) string {
+	if len(m.GraphRows) == 0 {
+		return "No templates use shared parts"
+	}
+
+	endIdx := len(m.GraphRows)
+	if maxHeight > 0 {
+		endIdx = min(endIdx, m.GraphOffset+maxHeight)
+	}
+
+	var lines []string
+	for i := m.GraphOffset; i < endIdx; i++ {
+		row := m.GraphRows[i]
+
+		label := row.Name
+		if row.Category != "" {
+			label = fmt.Sprintf("%s %s", r.templateManager.GetCategoryPrefix(row.Category), row.Name)
+		}
+		if row.Note != "" {
+			label += "  ! " + row.Note
+		}
+		if maxWidth > 0 {
+			label = r.TruncateText(label, max(1, maxWidth-len([]rune(row.Prefix))-4))
+		}
+
+		switch {
+		case i == m.GraphSelected && row.Category != "":
+			label = models.SelectedItemStyle.Render(label)
+		case row.Problem:
+			label = graphProblemStyle.Render(label)
+		case row.Prefix == "":
+			label = graphHeadingStyle.Render(label)
+		}
+
+		lines = append(lines, graphBranchStyle.Render(row.Prefix)+label)
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,112 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/rufex/sftui/internal/models"
+)
+
+const historyMaxValue = 40
+
+var undoneStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+
+// HistoryTitle returns the title of the history panel.
+func (r *Renderer) HistoryTitle(m *models.Model) string {
+	title := fmt.Sprintf("History - %d edits", len(m.History))
+	if undone := len(m.History) - m.HistoryPosition; undone > 0 {
+		title += fmt.Sprintf(", %d undone", undone)
+	}
+	return title
+}
+
+// HistoryListHeight returns how many entries fit above the changes of the highlighted one.
+func (r *Renderer) HistoryListHeight(contentHeight int) int {
+	return max(1, contentHeight-contentHeight/3)
+}
+
+// HistoryView lists the edits newest first, the undone ones greyed out, followed by the files
+// and config fields changed by the highlighted edit.
+func (r *Renderer) HistoryView(m *models.Model, maxHeight, maxWidth int) string {
+	if len(m.History) == 0 {
+		return "No edits yet"
+	}
+
+	var lines []string
+	listHeight := r.HistoryListHeight(maxHeight)
+	end := min(len(m.History), m.HistoryOffset+listHeight)
+	for row := m.HistoryOffset; row < end; row++ {
+		index := len(m.History) - 1 - row
+		entry := m.History[index]
+
+		line := fmt.Sprintf("%s  %s", entry.Time.Format("2006-01-02 15:04:05"), entry.Description)
+		if index >= m.HistoryPosition {
+			line += " (undone)"
+		}
+		line = r.truncateName(line, maxWidth)
+
+		switch {
+		case index == m.SelectedHistory:
+			line = models.SelectedItemStyle.Render(line)
+		case index >= m.HistoryPosition:
+			line = undoneStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+
+	if m.SelectedHistory < 0 || m.SelectedHistory >= len(m.History) {
+		return strings.Join(lines, "\n")
+	}
+	for len(lines) < listHeight {
+		lines = append(lines, "")
+	}
+	lines = append(lines, models.CategoryStyle.Render("Changes:"))
+	for _, change := range historyChanges(m.History[m.SelectedHistory]) {
+		if len(lines) == maxHeight {
+			break
+		}
+		lines = append(lines, r.truncateName("  "+change, maxWidth))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// historyChanges describes the config fields changed by an edit, and the other files it
+// added, removed or modified.
+func historyChanges(entry models.HistoryEntry) []string {
+	var changes []string
+	withFields := make(map[string]bool)
+	for _, field := range entry.Fields {
+		withFields[field.File] = true
+		changes = append(changes, fmt.Sprintf("%s: %s %s → %s", field.File, field.Name(), historyValue(field.Old), historyValue(field.New)))
+	}
+	for _, file := range entry.Files {
+		switch {
+		case withFields[file.Path]:
+		case file.Before == nil:
+			changes = append(changes, file.Path+": added")
+		case file.After == nil:
+			changes = append(changes, file.Path+": removed")
+		default:
+			changes = append(changes, file.Path+": modified")
+		}
+	}
+	return changes
+}
+
+func historyValue(value interface{}) string {
+	if value == nil {
+		return "(none)"
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	text := string(data)
+	if len(text) > historyMaxValue {
+		text = text[:historyMaxValue-3] + "..."
+	}
+	return text
+}
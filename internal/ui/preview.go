@@ -0,0 +1,100 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/rufex/sftui/internal/liquid"
+	"github.com/rufex/sftui/internal/models"
+)
+
+var (
+	liquidTagStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("5"))              // Magenta
+	liquidOutputStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("6"))              // Cyan
+	liquidCommentStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("8")).Italic(true) // Gray
+	lineNumberStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("8"))              // Gray
+	searchHitStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("3"))              // Yellow
+)
+
+// PreviewTitle returns the title of the source preview, including the search prompt.
+func (r *Renderer) PreviewTitle(m *models.Model) string {
+	title := m.PreviewTitle
+	if m.PreviewSearchMode {
+		title += fmt.Sprintf(" - search: %s_", m.PreviewSearchQuery)
+	} else if m.PreviewSearchQuery != "" {
+		title += fmt.Sprintf(" - %q (n/N: next/prev)", m.PreviewSearchQuery)
+	}
+	return title
+}
+
+// PreviewView renders the previewed Liquid source with line numbers and syntax highlighting.
+func (r *Renderer) PreviewView(m *models.Model, maxHeight, maxWidth int) string {
+	rawLines := strings.Split(m.PreviewContent, "\n")
+	tokenLines := liquid.Lines(liquid.Tokenize(m.PreviewContent))
+	query := strings.ToLower(m.PreviewSearchQuery)
+	gutterWidth := len(fmt.Sprintf("%d", len(rawLines)))
+
+	// Leave room for the gutter and the section border
+	textWidth := -1
+	if maxWidth > 0 {
+		textWidth = max(1, maxWidth-gutterWidth-7)
+	}
+
+	startIdx := min(m.PreviewOffset, max(0, len(tokenLines)-1))
+	endIdx := len(tokenLines)
+	if maxHeight > 0 {
+		endIdx = min(endIdx, startIdx+maxHeight)
+	}
+
+	var lines []string
+	for i := startIdx; i < endIdx; i++ {
+		gutter := fmt.Sprintf("%*d │ ", gutterWidth, i+1)
+		switch {
+		case i == m.PreviewMatchLine:
+			gutter = models.SelectedItemStyle.Render(gutter)
+		case query != "" && !m.PreviewSearchMode && strings.Contains(strings.ToLower(rawLines[i]), query):
+			gutter = searchHitStyle.Render(gutter)
+		default:
+			gutter = lineNumberStyle.Render(gutter)
+		}
+		lines = append(lines, gutter+renderLiquidLine(tokenLines[i], textWidth))
+	}
+
+	for maxHeight > 0 && len(lines) < maxHeight {
+		lines = append(lines, "")
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func renderLiquidLine(tokens []liquid.Token, maxWidth int) string {
+	var b strings.Builder
+	remaining := maxWidth
+	for _, token := range tokens {
+		value := strings.ReplaceAll(token.Value, "\t", "  ")
+		if maxWidth > 0 {
+			if remaining <= 0 {
+				break
+			}
+			runes := []rune(value)
+			if len(runes) > remaining {
+				value = string(runes[:remaining])
+			}
+			remaining -= len([]rune(value))
+		}
+
+		switch token.Kind {
+		case liquid.Tag:
+			b.WriteString(liquidTagStyle.Render(value))
+		case liquid.Output:
+			b.WriteString(liquidOutputStyle.Render(value))
+		case liquid.Comment:
+			b.WriteString(liquidCommentStyle.Render(value))
+		default:
+			b.WriteString(value)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,62 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rufex/sftui/internal/models"
+)
+
+// ProblemsTitle returns the title of the load problems panel with the number of problems.
+func (r *Renderer) ProblemsTitle(m *models.Model) string {
+	if len(m.LoadProblems) == 0 {
+		return "Problems"
+	}
+	return fmt.Sprintf("Problems - %d found while loading templates", len(m.LoadProblems))
+}
+
+// ProblemsView lists the problems found while loading templates.
+func (r *Renderer) ProblemsView(m *models.Model, maxHeight, maxWidth int) string {
+	if len(m.LoadProblems) == 0 {
+		return "Every template loaded without problems"
+	}
+
+	endIdx := len(m.LoadProblems)
+	if maxHeight > 0 {
+		endIdx = min(endIdx, m.ProblemsOffset+maxHeight)
+	}
+
+	var lines []string
+	for i := m.ProblemsOffset; i < endIdx; i++ {
+		problem := m.LoadProblems[i]
+
+		line := fmt.Sprintf("%-18s %s (%s)", loadProblemKindLabel(problem.Kind), problem.Message, problem.Path)
+		if maxWidth > 0 {
+			line = r.TruncateText(line, maxWidth-4)
+		}
+
+		if i == m.SelectedProblem {
+			line = models.SelectedItemStyle.Render(line)
+		} else {
+			line = logLevelStyle(models.LogError).Render(line)
+		}
+		lines = append(lines, line)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func loadProblemKindLabel(kind models.LoadProblemKind) string {
+	switch kind {
+	case models.UnreadableDirectory:
+		return "unreadable"
+	case models.InvalidConfig:
+		return "invalid config"
+	case models.MissingLiquid:
+		return "missing Liquid"
+	case models.MissingTextPart:
+		return "missing text part"
+	default:
+		return ""
+	}
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/charmbracelet/lipgloss"
 
+	"github.com/rufex/sftui/internal/logging"
 	"github.com/rufex/sftui/internal/models"
 	"github.com/rufex/sftui/internal/template"
 )
@@ -32,7 +33,39 @@ func (r *Renderer) RenderSection(m *models.Model, section models.Section, title,
 	return style.Width(width).Height(height).Render(contentWithTitle)
 }
 
+// availabilityLine shows the ID of the template in the firm or partner of the session, from the
+// id or partner_id map of its config.json.
+func (r *Renderer) availabilityLine(m *models.Model, t models.Template, maxWidth int) string {
+	target := models.FirmOption{ID: m.FirmID, Type: firmType(m)}
+	line := fmt.Sprintf("Not imported in %s %s", target.Type, target.ID)
+	remoteID, ok := template.RemoteID(t, target)
+	if ok {
+		line = fmt.Sprintf("ID in %s %s: %s", target.Type, target.ID, remoteID)
+	}
+	if maxWidth > 0 {
+		line = r.TruncateText(line, maxWidth)
+	}
+	if !ok {
+		return logLevelStyle(models.LogWarn).Render(line)
+	}
+	return line
+}
+
+// firmType returns "firm" or "partner" for the firm of the session.
+func firmType(m *models.Model) string {
+	if m.FirmType == "partner" {
+		return "partner"
+	}
+	return "firm"
+}
+
 func (r *Renderer) FirmView(m *models.Model) string {
+	if m.FirmType == "partner" {
+		return m.Firm + " - partner mode"
+	}
+	if m.FirmID != "" && m.FirmID != m.DefaultFirmID {
+		return m.Firm + " - this session only"
+	}
 	return m.Firm
 }
 
@@ -56,7 +89,7 @@ func (r *Renderer) TemplatesViewWithHeightAndWidth(m *models.Model, maxHeight, m
 }
 
 func (r *Renderer) templatesViewWithHeightAndWidth(m *models.Model, maxHeight, maxWidth int) string {
-	if len(m.FilteredTemplates) == 0 {
+	if len(m.FilteredTemplates) == 0 && len(m.TemplateRows) == 0 {
 		if m.SearchMode && m.SearchQuery != "" {
 			return "No templates match search"
 		}
@@ -65,27 +98,26 @@ func (r *Renderer) templatesViewWithHeightAndWidth(m *models.Model, maxHeight, m
 
 	var lines []string
 
-	for i, templateIdx := range m.FilteredTemplates {
-		template := m.Templates[templateIdx]
-		prefix := r.templateManager.GetCategoryPrefix(template.Category)
-
-		// Add selection indicator
-		selectionIndicator := "  " // Two spaces for unselected
-		if m.SelectedTemplates[templateIdx] {
-			selectionIndicator = "✓ " // Checkmark for selected
-		}
-
-		line := fmt.Sprintf("%s[%s] %s", selectionIndicator, prefix, template.Name)
+	registry := template.NewRegistry(m.Templates)
+	var highlight []string
+	if query, err := template.ParseQuery(m.SearchQuery); err == nil {
+		highlight = query.HighlightTerms()
+	} else if m.SearchQuery != "" {
+		highlight = []string{m.SearchQuery}
+	}
 
-		// Apply horizontal truncation if width limit is specified
-		if maxWidth > 0 {
-			line = r.TruncateText(line, maxWidth)
+	if m.TemplateRows != nil {
+		for _, row := range m.TemplateRows {
+			if row.Category != "" {
+				lines = append(lines, r.templateGroupLine(row, maxWidth))
+			} else {
+				lines = append(lines, r.templateLine(m, registry, row.Template, highlight, maxWidth))
+			}
 		}
-
-		if i == m.SelectedTemplate {
-			line = models.SelectedItemStyle.Render(line)
+	} else {
+		for i := range m.FilteredTemplates {
+			lines = append(lines, r.templateLine(m, registry, i, highlight, maxWidth))
 		}
-		lines = append(lines, line)
 	}
 
 	// If no height limit, return all lines
@@ -114,6 +146,101 @@ func (r *Renderer) templatesViewWithHeightAndWidth(m *models.Model, maxHeight, m
 	return strings.Join(visibleLines, "\n")
 }
 
+// templateGroupLine renders the heading of a category in the grouped Templates section.
+func (r *Renderer) templateGroupLine(row models.TemplateRow, maxWidth int) string {
+	marker := "▾"
+	if row.Collapsed {
+		marker = "▸"
+	}
+	line := fmt.Sprintf("%s %s (%d)", marker, r.templateManager.GetCategoryGroupName(row.Category), row.Count)
+	if maxWidth > 0 {
+		line = r.TruncateText(line, maxWidth)
+	}
+	return models.CategoryStyle.Render(line)
+}
+
+// templateLine renders the template at a position of FilteredTemplates, highlighting the
+// characters of its name matched by the search terms.
+func (r *Renderer) templateLine(m *models.Model, registry *template.Registry, position int, highlight []string, maxWidth int) string {
+	id := m.FilteredTemplates[position]
+	template, _ := registry.Get(id)
+	prefix := r.templateManager.GetCategoryPrefix(id.Category)
+
+	// Add selection indicator
+	selectionIndicator := "  " // Two spaces for unselected
+	if m.SelectedTemplates[id] {
+		selectionIndicator = "✓ " // Checkmark for selected
+	}
+
+	label := fmt.Sprintf("%s[%s] ", selectionIndicator, prefix)
+	line := label + id.Name
+	badge, badgeWidth := validationBadge(template)
+	if m.TemplateSort == models.SortByGitStatus {
+		if code := m.TemplateGitStatus[id]; code != "" {
+			badge += models.CategoryStyle.Render(" " + code)
+			badgeWidth += 1 + len(code)
+		}
+	}
+
+	// Apply horizontal truncation if width limit is specified
+	if maxWidth > 0 {
+		line = r.TruncateText(line, maxWidth-badgeWidth)
+	}
+
+	style := lipgloss.NewStyle()
+	if position == m.SelectedTemplate {
+		style = models.SelectedItemStyle
+	}
+	if len(highlight) == 0 || len(line) <= len(label) {
+		return style.Render(line) + badge
+	}
+
+	// The name may have been cut short with an ellipsis, which is never highlighted
+	name, ellipsis := line[len(label):], ""
+	if len(line) < len(label)+len(id.Name) && strings.HasSuffix(name, "...") {
+		name, ellipsis = strings.TrimSuffix(name, "..."), "..."
+	}
+	return style.Render(label) + highlightMatches(name, id.Name, highlight, style) + style.Render(ellipsis) + badge
+}
+
+// highlightMatches renders visible, the start of name, marking the characters the search terms
+// match in name.
+func highlightMatches(visible, name string, terms []string, style lipgloss.Style) string {
+	matched := make(map[int]bool)
+	for _, term := range terms {
+		if _, positions, ok := template.FuzzyScore(term, name); ok {
+			for _, position := range positions {
+				matched[position] = true
+			}
+		}
+	}
+
+	matchStyle := style.Bold(true).Underline(true)
+	var b strings.Builder
+	var run []rune
+	runMatched := false
+	flush := func() {
+		if len(run) == 0 {
+			return
+		}
+		if runMatched {
+			b.WriteString(matchStyle.Render(string(run)))
+		} else {
+			b.WriteString(style.Render(string(run)))
+		}
+		run = run[:0]
+	}
+	for i, c := range []rune(visible) {
+		if matched[i] != runMatched {
+			flush()
+			runMatched = matched[i]
+		}
+		run = append(run, c)
+	}
+	flush()
+	return b.String()
+}
+
 func (r *Renderer) DetailsView(m *models.Model) string {
 	return r.detailsViewWithHeightAndWidth(m, -1, -1)
 }
@@ -127,14 +254,11 @@ func (r *Renderer) DetailsViewWithHeightAndWidth(m *models.Model, maxHeight, max
 }
 
 func (r *Renderer) detailsViewWithHeightAndWidth(m *models.Model, maxHeight, maxWidth int) string {
-	if len(m.FilteredTemplates) == 0 || m.SelectedTemplate >= len(m.FilteredTemplates) {
+	template, ok := m.CurrentTemplate()
+	if !ok {
 		return "No template selected"
 	}
 
-	// Get actual template index from filtered list
-	actualIndex := m.FilteredTemplates[m.SelectedTemplate]
-	template := m.Templates[actualIndex]
-
 	var details []string
 
 	// Apply horizontal truncation to each line if width limit is specified
@@ -156,6 +280,22 @@ func (r *Renderer) detailsViewWithHeightAndWidth(m *models.Model, maxHeight, max
 		pathStr = r.TruncateText(pathStr, maxWidth)
 	}
 	details = append(details, pathStr)
+	if m.FirmID != "" {
+		details = append(details, r.availabilityLine(m, template, maxWidth))
+	}
+
+	// Show config.json problems first so they stay visible in short panes
+	if len(template.Validation) > 0 {
+		details = append(details, "")
+		details = append(details, "Problems:")
+		for _, issue := range template.Validation {
+			line := fmt.Sprintf("  %s: %s", issue.Field, issue.Message)
+			if maxWidth > 0 {
+				line = r.TruncateText(line, maxWidth)
+			}
+			details = append(details, logLevelStyle(issue.Severity).Render(line))
+		}
+	}
 
 	details = append(details, "")
 	details = append(details, "Configuration:")
@@ -172,6 +312,10 @@ func (r *Renderer) detailsViewWithHeightAndWidth(m *models.Model, maxHeight, max
 		}
 	}
 
+	// Show the main Liquid file, which can be opened in the source preview
+	details = append(details, "")
+	details = append(details, r.renderLiquidSection(template, m, maxWidth)...)
+
 	// Show text parts as a separate section for templates that support them (but not shared_parts)
 	if template.Category != "shared_parts" {
 		textPartsSection := r.renderTextPartsSection(template, m, maxWidth)
@@ -194,6 +338,14 @@ func (r *Renderer) detailsViewWithHeightAndWidth(m *models.Model, maxHeight, max
 		}
 	}
 
+	// Show the templates using a shared part
+	if template.Category == "shared_parts" {
+		if usedInLines := r.renderUsedInSection(template, m, maxWidth); len(usedInLines) > 0 {
+			details = append(details, "")
+			details = append(details, usedInLines...)
+		}
+	}
+
 	// If no height limit, return all details
 	if maxHeight <= 0 {
 		return strings.Join(details, "\n")
@@ -290,54 +442,41 @@ func (r *Renderer) GetConfigFieldCount(template models.Template) int {
 	return count
 }
 
-func (r *Renderer) renderTextPartsSection(template models.Template, m *models.Model, maxWidth int) string {
-	// Check if template has text_parts in config
-	textPartsInterface, exists := template.Config["text_parts"]
-	if !exists {
-		return ""
+func (r *Renderer) renderLiquidSection(template models.Template, m *models.Model, maxWidth int) []string {
+	line := fmt.Sprintf("  %s", r.templateManager.GetMainLiquidFile(template))
+	if maxWidth > 0 {
+		line = r.TruncateText(line, maxWidth)
 	}
 
-	textParts, ok := textPartsInterface.(map[string]interface{})
-	if !ok || len(textParts) == 0 {
-		return ""
+	// The main Liquid file is the field right after the config fields
+	if m.CurrentSection == models.DetailsSection && m.SelectedDetailField == r.GetConfigFieldCount(template) {
+		line = models.SelectedItemStyle.Render(line)
 	}
 
-	var lines []string
-	lines = append(lines, "Text Parts:")
+	return []string{"Liquid:", line}
+}
 
-	// Convert map to sorted slice for consistent ordering
-	type textPart struct {
-		name string
-		path string
-	}
-	var partsList []textPart
-	for name, pathInterface := range textParts {
-		if pathStr, ok := pathInterface.(string); ok {
-			partsList = append(partsList, textPart{name: name, path: pathStr})
-		}
+func (r *Renderer) renderTextPartsSection(template models.Template, m *models.Model, maxWidth int) string {
+	partsList := r.templateManager.GetTextParts(template)
+	if len(partsList) == 0 {
+		return ""
 	}
 
-	// Sort by name for consistency
-	for i := 0; i < len(partsList); i++ {
-		for j := i + 1; j < len(partsList); j++ {
-			if partsList[i].name > partsList[j].name {
-				partsList[i], partsList[j] = partsList[j], partsList[i]
-			}
-		}
-	}
+	var lines []string
+	lines = append(lines, "Text Parts:")
 
-	// Calculate field index - config fields come first
+	// Calculate field index - config fields and the main Liquid file come first
 	configFieldCount := r.GetConfigFieldCount(template)
 
 	// Render each text part (only show name)
 	for i, part := range partsList {
-		line := fmt.Sprintf("  %s", part.name)
+		line := fmt.Sprintf("  %s", part.Name)
 		if maxWidth > 0 {
 			line = r.TruncateText(line, maxWidth)
 		}
 
 		// Highlight if this text part is selected and we're in Details section
-		fieldIndex := configFieldCount + i
+		fieldIndex := configFieldCount + 1 + i
 		if m.CurrentSection == models.DetailsSection && m.SelectedDetailField == fieldIndex {
 			line = models.SelectedItemStyle.Render(line)
 		}
@@ -354,27 +493,18 @@ func (r *Renderer) renderSharedPartsSection(template models.Template, m *models.
 		return ""
 	}
 
-	// Create template key to look up shared parts
-	templateKey := template.Category + "/" + template.Name
-
 	// Get shared parts for this template
-	sharedParts, exists := m.SharedPartsUsage[templateKey]
-	if !exists || len(sharedParts) == 0 {
+	sharedParts := m.SharedPartsUsage[template.ID()]
+	if len(sharedParts) == 0 {
 		return ""
 	}
 
 	var lines []string
 	lines = append(lines, "Shared Parts:")
 
-	// Calculate field index - config fields and text parts come first
+	// Calculate field index - config fields, the main Liquid file and text parts come first
 	configFieldCount := r.GetConfigFieldCount(template)
-
-	textPartsCount := 0
-	if textPartsInterface, exists := template.Config["text_parts"]; exists {
-		if textParts, ok := textPartsInterface.(map[string]interface{}); ok {
-			textPartsCount = len(textParts)
-		}
-	}
+	textPartsCount := len(r.templateManager.GetTextParts(template))
 
 	// Sort shared parts for consistent display
 	sortedSharedParts := make([]string, len(sharedParts))
@@ -395,7 +525,7 @@ func (r *Renderer) renderSharedPartsSection(template models.Template, m *models.
 		}
 
 		// Highlight if this shared part is selected and we're in Details section
-		fieldIndex := configFieldCount + textPartsCount + i
+		fieldIndex := configFieldCount + 1 + textPartsCount + i
 		if m.CurrentSection == models.DetailsSection && m.SelectedDetailField == fieldIndex {
 			line = models.SelectedItemStyle.Render(line)
 		}
@@ -406,6 +536,31 @@ func (r *Renderer) renderSharedPartsSection(template models.Template, m *models.
 	return strings.Join(lines, "\n")
 }
 
+// renderUsedInSection lists the templates whose used_in metadata references the shared part.
+func (r *Renderer) renderUsedInSection(sharedPart models.Template, m *models.Model, maxWidth int) []string {
+	users := template.SharedPartUsers(m.SharedPartsUsage, sharedPart.Name)
+	if len(users) == 0 {
+		return nil
+	}
+
+	lines := []string{"Used In:"}
+	// The main Liquid file is the only field between the config fields and the users
+	firstField := r.GetConfigFieldCount(sharedPart) + 1
+	for i, id := range users {
+		line := fmt.Sprintf("  %s %s", r.templateManager.GetCategoryPrefix(id.Category), id.Name)
+		if maxWidth > 0 {
+			line = r.TruncateText(line, maxWidth)
+		}
+
+		if m.CurrentSection == models.DetailsSection && m.SelectedDetailField == firstField+i {
+			line = models.SelectedItemStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
 func (r *Renderer) ReconciliationTypePopupView(m *models.Model) string {
 	// Build popup content
 	var content strings.Builder
@@ -455,7 +610,161 @@ func (r *Renderer) ReconciliationTypePopupView(m *models.Model) string {
 }
 
 func (r *Renderer) OutputView(m *models.Model) string {
-	return m.Output
+	return r.OutputViewWithHeightAndWidth(m, -1, -1)
+}
+
+func (r *Renderer) OutputViewWithHeightAndWidth(m *models.Model, maxHeight, maxWidth int) string {
+	var lines []string
+
+	// Running and pending jobs come first so progress stays visible above the log
+	if len(m.Jobs) > 0 {
+		jobLines := r.jobLines(m, maxWidth)
+		var active, finished []string
+		for i, job := range m.Jobs {
+			if job.Status == models.JobRunning || job.Status == models.JobPending {
+				active = append(active, jobLines[i])
+			} else {
+				finished = append(finished, jobLines[i])
+			}
+		}
+		lines = append(active, finished...)
+		if maxHeight > 0 && len(lines) > maxHeight-1 {
+			lines = lines[:max(0, maxHeight-1)]
+		}
+	}
+
+	logHeight := -1
+	if maxHeight > 0 {
+		logHeight = maxHeight - len(lines)
+	}
+	lines = append(lines, r.LogView(m, logHeight, maxWidth))
+
+	return strings.Join(lines, "\n")
+}
+
+// LogView renders the tail of the (filtered) log, honouring the scroll offset.
+func (r *Renderer) LogView(m *models.Model, maxHeight, maxWidth int) string {
+	matches := logging.Filter(m.Log, m.LogSearchQuery)
+	if len(matches) == 0 {
+		if m.LogSearchQuery != "" {
+			return "No log entries match search"
+		}
+		return m.Output
+	}
+
+	var lines []string
+	previousGroup := ""
+	for _, idx := range matches {
+		entry := m.Log[idx]
+
+		var line string
+		if entry.Group != "" && entry.Group == previousGroup {
+			// Continuation of the same job: indent under the group's first line
+			line = "         " + entry.Message
+		} else {
+			line = entry.Time.Format("15:04:05") + " " + logging.Format(entry)
+		}
+		previousGroup = entry.Group
+
+		if maxWidth > 0 {
+			line = r.TruncateText(line, maxWidth)
+		}
+		lines = append(lines, logLevelStyle(entry.Level).Render(line))
+	}
+
+	if maxHeight <= 0 {
+		return strings.Join(lines, "\n")
+	}
+
+	offset := min(m.LogOffset, max(0, len(lines)-maxHeight))
+	endIdx := len(lines) - offset
+	startIdx := max(0, endIdx-maxHeight)
+
+	return strings.Join(lines[startIdx:endIdx], "\n")
+}
+
+// OutputTitle returns the title of the Output section, including search and scroll state.
+func (r *Renderer) OutputTitle(m *models.Model) string {
+	title := fmt.Sprintf("Output (%d)", len(m.Log))
+	if m.LogSearchMode {
+		title = fmt.Sprintf("Output - search: %s_", m.LogSearchQuery)
+	} else if m.LogSearchQuery != "" {
+		title = fmt.Sprintf("Output - %q (%d/%d)", m.LogSearchQuery, len(logging.Filter(m.Log, m.LogSearchQuery)), len(m.Log))
+	}
+	if m.LogOffset > 0 {
+		title += fmt.Sprintf(" ↑%d", m.LogOffset)
+	}
+	return title
+}
+
+// validationBadge returns the styled error/warning badge shown after a template name and its width.
+func validationBadge(template models.Template) (string, int) {
+	errors, warnings := template.ValidationCounts()
+	switch {
+	case errors > 0:
+		badge := fmt.Sprintf(" ✗%d", errors)
+		return logLevelStyle(models.LogError).Render(badge), len([]rune(badge))
+	case warnings > 0:
+		badge := fmt.Sprintf(" !%d", warnings)
+		return logLevelStyle(models.LogWarn).Render(badge), len([]rune(badge))
+	default:
+		return "", 0
+	}
+}
+
+func logLevelStyle(level models.LogLevel) lipgloss.Style {
+	switch level {
+	case models.LogWarn:
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("3")) // Yellow
+	case models.LogError:
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("1")) // Red
+	default:
+		return lipgloss.NewStyle()
+	}
+}
+
+func (r *Renderer) jobLines(m *models.Model, maxWidth int) []string {
+	var lines []string
+	for _, job := range m.Jobs {
+		prefix := r.templateManager.GetCategoryPrefix(job.Category)
+		line := fmt.Sprintf("%s [%s] %s - %s", jobStatusIcon(job.Status), prefix, job.Label, job.Status)
+		if job.Error != "" {
+			line += ": " + job.Error
+		}
+		if maxWidth > 0 {
+			line = r.TruncateText(line, maxWidth)
+		}
+		lines = append(lines, jobStatusStyle(job.Status).Render(line))
+	}
+	return lines
+}
+
+func jobStatusIcon(status models.JobStatus) string {
+	switch status {
+	case models.JobRunning:
+		return "⟳"
+	case models.JobSucceeded:
+		return "✓"
+	case models.JobFailed:
+		return "✗"
+	case models.JobCancelled:
+		return "-"
+	default:
+		return "·"
+	}
+}
+
+func jobStatusStyle(status models.JobStatus) lipgloss.Style {
+	switch status {
+	case models.JobRunning:
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("6")) // Cyan
+	case models.JobSucceeded:
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("2")) // Green
+	case models.JobFailed:
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("1")) // Red
+	default:
+		return models.CategoryStyle
+	}
 }
 
 func (r *Renderer) TruncateText(text string, maxWidth int) string {
@@ -487,6 +796,9 @@ func (r *Renderer) StatusBarView(m *models.Model) string {
 		Padding(0, 1)
 
 	status := "TAB: Next section • Shift+TAB: Prev section • ↑/k,↓/j: Navigate list • ?: Help • q: Quit"
+	if m.LogFileError != "" {
+		return statusStyle.Foreground(lipgloss.Color("1")).Width(m.Width).Render("Log file not written: " + m.LogFileError)
+	}
 	return statusStyle.Width(m.Width).Render(status)
 }
 
@@ -549,17 +861,20 @@ func (r *Renderer) ActionPopupView(m *models.Model) string {
 func (r *Renderer) FirmPopupView(m *models.Model) string {
 	// Build popup content
 	var content strings.Builder
-	content.WriteString("Select Default Firm/Partner\n\n")
+	content.WriteString("Select Firm/Partner\n\n")
 
 	// Firm and partner options
 	for i, option := range m.FirmOptions {
 		prefix := "  "
+		optionText := fmt.Sprintf("[%s] %s (%s)", option.Type, option.Name, option.ID)
+		if option.Type == "firm" && option.ID == m.DefaultFirmID {
+			optionText += " - default"
+		}
 		if i == m.SelectedFirm {
 			// Highlight selected option
-			optionText := fmt.Sprintf("> [%s] %s (%s)", option.Type, option.Name, option.ID)
-			content.WriteString(models.SelectedItemStyle.Render(optionText))
+			content.WriteString(models.SelectedItemStyle.Render("> " + optionText))
 		} else {
-			content.WriteString(fmt.Sprintf("%s[%s] %s (%s)", prefix, option.Type, option.Name, option.ID))
+			content.WriteString(prefix + optionText)
 		}
 		content.WriteString("\n")
 	}
@@ -569,11 +884,12 @@ func (r *Renderer) FirmPopupView(m *models.Model) string {
 	}
 
 	content.WriteString("\nUse ↑/↓ or k/j to navigate")
-	content.WriteString("\nPress ENTER to select, ESC to cancel")
+	content.WriteString("\nENTER for this session, d to save as default")
+	content.WriteString("\nESC to cancel")
 
 	// Calculate popup dimensions (wider for firm names)
 	popupWidth := 50
-	popupHeight := min(15, len(m.FirmOptions)+8) // Dynamic height based on options
+	popupHeight := min(16, len(m.FirmOptions)+9) // Dynamic height based on options
 
 	// Center the popup
 	leftMargin := max(0, (m.Width-popupWidth)/2)
@@ -653,8 +969,77 @@ Navigation:
   Space                   Select/deselect template (Templates section)
   Backspace               Deselect all templates (Templates section)
   /                       Enter search mode (Templates section)
+                          e.g. type:rt firm:1001 -externally_managed OR uses:sp_1
+  o                       Sort by category, name, handle, modified time or git status
+  G                       Group templates under category headings
+  z / Z                   Collapse the current group / expand all or collapse the others
   Enter                   Show actions for selected templates
+                          Details: edit a field or view a Liquid file
+                          Firm: switch firm or partner for this session (d saves a firm as default)
+  r                       Rename/move the highlighted text part (Details section)
+  a / c / d               Add, duplicate or delete a text part (Details section)
+  [ / ]                   Move the highlighted text part up / down (Details section)
+  n                       Create a new template (Templates section)
+  s                       Link/unlink templates to the selected shared part
+  g                       Show the shared part dependency graph
+  C                       Check used_in against Liquid includes
+  P                       Show problems found while loading templates
+  F                       Search the Liquid source of every template
+  M                       Show template IDs per firm and partner
+  m                       Show templates missing in the current firm (again to clear)
+  R                       Rescan the repository for templates
+  u / Ctrl+R              Undo / redo the last config edit
+  U                       Show the history of config edits
+                          Details of a shared part: Enter on Used In jumps there
+  e                       Edit main.liquid or the highlighted file in $EDITOR
+  E                       Edit config.json of the template in $EDITOR
+
+Dependency Graph:
+  ↑/k, ↓/j                Move between templates and shared parts
+  Enter                   Jump to the highlighted template or shared part
+  Esc / q / g             Close the graph
+
+Consistency Check:
+  ↑/k, ↓/j                Move between issues
+  Enter                   Jump to the template of the issue
+  f                       Rewrite used_in to match the Liquid includes
+  Esc / q / C             Close the panel
+
+Content Search:
+  Enter                   Search, then open the highlighted match in the preview
+  ↑/k, ↓/j                Move between matching lines
+  s                       Select every template with a match for a bulk action
+  /                       Edit the search text
+  Esc / q / F             Close the search
+
+Firm Matrix:
+  ↑/k, ↓/j, ←/h, →/l      Move between templates and firms/partners
+  Enter                   Jump to the highlighted template
+  m                       Show templates missing in the highlighted firm/partner
+  Esc / q / M             Close the matrix
+
+History:
+  ↑/k, ↓/j                Move between edits, newest first
+  Enter                   Undo or redo up to the highlighted edit
+  u / Ctrl+R              Undo / redo one edit
+  Esc / q / U             Close the panel
+
+Problems:
+  ↑/k, ↓/j                Move between problems
+  Enter                   Jump to the template of the problem
+  Esc / q / P             Close the panel
+
+Source Preview:
+  ↑/k, ↓/j, PgUp/PgDn     Scroll
+  /, n, N                 Search, next match, previous match
+  e                       Edit the previewed file in $EDITOR
+  Esc / q                 Close the preview
   
+Output:
+  ↑/k, ↓/j                Scroll the log (Output section)
+  /                       Search the log (Output section)
+  f                       Expand/restore the log to full screen
+
 Search Mode:
   Type                    Filter templates by name, category, or path
   Backspace               Remove last character
@@ -662,6 +1047,7 @@ Search Mode:
   Escape                  Exit search mode (clear filter)
   
 Actions:
+  Esc                     Cancel pending jobs of a running action
   ?                       Show/hide this help
   q / Ctrl+C              Quit application
 
@@ -711,51 +1097,27 @@ func min(a, b int) int {
 
 func (r *Renderer) TextPartPopupView(m *models.Model) string {
 	// Get current template and text part
-	if len(m.FilteredTemplates) == 0 || m.SelectedTemplate >= len(m.FilteredTemplates) {
+	template, ok := m.CurrentTemplate()
+	if !ok {
 		return ""
 	}
 
-	actualIndex := m.FilteredTemplates[m.SelectedTemplate]
-	template := m.Templates[actualIndex]
-
-	textPartsInterface, exists := template.Config["text_parts"]
-	if !exists {
-		return ""
-	}
-
-	textParts, ok := textPartsInterface.(map[string]interface{})
-	if !ok || len(textParts) == 0 {
-		return ""
-	}
-
-	// Convert to sorted slice
-	type textPart struct {
-		name string
-		path string
-	}
-	var partsList []textPart
-	for name, pathInterface := range textParts {
-		if pathStr, ok := pathInterface.(string); ok {
-			partsList = append(partsList, textPart{name: name, path: pathStr})
+	title := "Add Text Part"
+	if m.TextPartPopupAction != "add" {
+		partsList := r.templateManager.GetTextParts(template)
+		if m.SelectedTextPart >= len(partsList) {
+			return ""
 		}
-	}
 
-	// Sort by name
-	for i := 0; i < len(partsList); i++ {
-		for j := i + 1; j < len(partsList); j++ {
-			if partsList[i].name > partsList[j].name {
-				partsList[i], partsList[j] = partsList[j], partsList[i]
-			}
+		title = "Edit Text Part"
+		if m.TextPartPopupAction == "duplicate" {
+			title = "Duplicate Text Part " + partsList[m.SelectedTextPart].Name
 		}
 	}
 
-	if m.SelectedTextPart >= len(partsList) {
-		return ""
-	}
-
 	// Build popup content
 	var content strings.Builder
-	content.WriteString("Edit Text Part\n\n")
+	content.WriteString(title + "\n\n")
 
 	content.WriteString("Name: ")
 	content.WriteString(m.TextPartNameInput.View())
@@ -796,6 +1158,162 @@ func (r *Renderer) TextPartPopupView(m *models.Model) string {
 	return strings.Join(lines, "\n")
 }
 
+// NewTemplateWizardView renders the steps of the new template wizard.
+func (r *Renderer) NewTemplateWizardView(m *models.Model) string {
+	var content strings.Builder
+	content.WriteString("New Template\n\n")
+
+	if m.NewTemplateStep == 0 {
+		content.WriteString("Category:\n")
+		for i, category := range template.Categories {
+			line := fmt.Sprintf("  %s", r.templateManager.GetCategoryDisplayName(category))
+			if i == m.NewTemplateCategory {
+				line = models.SelectedItemStyle.Render(fmt.Sprintf("> %s", r.templateManager.GetCategoryDisplayName(category)))
+			}
+			content.WriteString(line + "\n")
+		}
+		content.WriteString("\nUse ↑/↓ or k/j to navigate")
+		content.WriteString("\nPress ENTER to continue, ESC to cancel")
+	} else {
+		category := template.Categories[m.NewTemplateCategory]
+		content.WriteString(fmt.Sprintf("Category: %s\n\n", r.templateManager.GetCategoryDisplayName(category)))
+		for i, input := range m.NewTemplateInputs {
+			// Shared parts have no translated names
+			if i > 0 && category == "shared_parts" {
+				break
+			}
+			label := "Handle:  "
+			if i > 0 {
+				label = fmt.Sprintf("Name %s: ", template.NameLanguages[i-1])
+			}
+			content.WriteString(label + input.View() + "\n")
+		}
+		content.WriteString("\nUse TAB to switch between fields")
+		content.WriteString("\nPress ENTER to create, ESC to go back")
+	}
+
+	popupWidth := 60
+	popupHeight := 16
+
+	leftMargin := max(0, (m.Width-popupWidth)/2)
+	topMargin := max(0, (m.Height-popupHeight)/2)
+
+	popupBox := models.ActiveBorderStyle.
+		Width(popupWidth).
+		Height(popupHeight).
+		Padding(1).
+		Render(content.String())
+
+	centeredPopup := strings.Repeat("\n", topMargin) + popupBox
+
+	lines := strings.Split(centeredPopup, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = strings.Repeat(" ", leftMargin) + line
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// SharedPartPopupVisibleRows returns the number of candidate templates the shared part popup shows.
+func SharedPartPopupVisibleRows(m *models.Model) int {
+	return max(3, min(len(m.SharedPartCandidates), m.Height-14))
+}
+
+// SharedPartPopupView lists the templates that can use a shared part with their pending link state.
+func (r *Renderer) SharedPartPopupView(m *models.Model) string {
+	sharedPart, ok := m.TemplateByID(m.SharedPartTemplate)
+	if !ok {
+		return ""
+	}
+
+	var content strings.Builder
+	content.WriteString(fmt.Sprintf("Templates using %s\n\n", sharedPart.Name))
+
+	visibleRows := SharedPartPopupVisibleRows(m)
+	end := min(len(m.SharedPartCandidates), m.SharedPartPopupOffset+visibleRows)
+	for i := m.SharedPartPopupOffset; i < end; i++ {
+		candidate := m.SharedPartCandidates[i]
+
+		checkbox := "[ ]"
+		if m.SharedPartLinks[candidate] {
+			checkbox = "[x]"
+		}
+		line := fmt.Sprintf("%s %s %s", checkbox, r.templateManager.GetCategoryPrefix(candidate.Category), candidate.Name)
+		if i == m.SelectedSharedPartCandidate {
+			line = models.SelectedItemStyle.Render("> " + line)
+		} else {
+			line = "  " + line
+		}
+		content.WriteString(line + "\n")
+	}
+	if len(m.SharedPartCandidates) == 0 {
+		content.WriteString("No templates can use shared parts\n")
+	}
+
+	syncState := "off"
+	if m.SharedPartSyncCLI {
+		syncState = "on"
+	}
+	content.WriteString(fmt.Sprintf("\nCLI sync for %s %s: %s (c to toggle)", firmType(m), m.FirmID, syncState))
+	content.WriteString("\nSPACE to toggle, ENTER to save, ESC to cancel")
+
+	popupWidth := 60
+	popupHeight := visibleRows + 8
+
+	leftMargin := max(0, (m.Width-popupWidth)/2)
+	topMargin := max(0, (m.Height-popupHeight)/2)
+
+	popupBox := models.ActiveBorderStyle.
+		Width(popupWidth).
+		Height(popupHeight).
+		Padding(1).
+		Render(content.String())
+
+	centeredPopup := strings.Repeat("\n", topMargin) + popupBox
+
+	lines := strings.Split(centeredPopup, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = strings.Repeat(" ", leftMargin) + line
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// ConfirmPopupView asks the user to confirm a destructive action.
+func (r *Renderer) ConfirmPopupView(m *models.Model) string {
+	var content strings.Builder
+	content.WriteString("Confirm\n\n")
+	content.WriteString(m.ConfirmMessage)
+	content.WriteString("\n\nPress y/ENTER to confirm, n/ESC to cancel")
+
+	popupWidth := 60
+	popupHeight := 8 + strings.Count(m.ConfirmMessage, "\n")
+
+	leftMargin := max(0, (m.Width-popupWidth)/2)
+	topMargin := max(0, (m.Height-popupHeight)/2)
+
+	popupBox := models.ActiveBorderStyle.
+		Width(popupWidth).
+		Height(popupHeight).
+		Padding(1).
+		Render(content.String())
+
+	centeredPopup := strings.Repeat("\n", topMargin) + popupBox
+
+	lines := strings.Split(centeredPopup, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = strings.Repeat(" ", leftMargin) + line
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
 func max(a, b int) int {
 	if a > b {
 		return a
@@ -0,0 +1,295 @@
+package watcher
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/fsnotify/fsnotify"
+)
+
+// DefaultDebounce is how long the watcher waits for more events before reporting changes.
+const DefaultDebounce = 300 * time.Millisecond
+
+// DefaultPollInterval is how often the polling fallback scans the category directories.
+const DefaultPollInterval = 2 * time.Second
+
+// ChangesMsg lists the template directories that changed on disk, sorted. A directory may no
+// longer exist when its template was removed.
+type ChangesMsg struct {
+	Dirs []string
+}
+
+// Watcher watches the category directories of a template repository and reports changed
+// template directories. It uses inotify (or the platform equivalent) through fsnotify and falls
+// back to polling when that is not available.
+type Watcher struct {
+	root         string
+	categories   []string
+	debounce     time.Duration
+	pollInterval time.Duration
+	msgs         chan tea.Msg
+	done         chan struct{}
+	closeOnce    sync.Once
+
+	mu      sync.Mutex
+	pending map[string]bool
+	timer   *time.Timer
+	polling bool
+}
+
+func NewWatcher(root string, categories []string) *Watcher {
+	return &Watcher{
+		root:         root,
+		categories:   categories,
+		debounce:     DefaultDebounce,
+		pollInterval: DefaultPollInterval,
+		msgs:         make(chan tea.Msg, 1),
+		done:         make(chan struct{}),
+		pending:      make(map[string]bool),
+	}
+}
+
+// SetDebounce changes the debounce delay. It must be called before Start.
+func (w *Watcher) SetDebounce(debounce time.Duration) {
+	w.debounce = debounce
+}
+
+// SetPollInterval changes the scan interval of the polling fallback. It must be called before Start.
+func (w *Watcher) SetPollInterval(interval time.Duration) {
+	w.pollInterval = interval
+}
+
+// Polling reports whether the watcher fell back to polling.
+func (w *Watcher) Polling() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.polling
+}
+
+// Start begins watching and returns a command waiting for the first changes. When forcePolling
+// is true fsnotify is not tried.
+func (w *Watcher) Start(forcePolling bool) tea.Cmd {
+	if !forcePolling {
+		if fsWatcher, err := w.newFSWatcher(); err == nil {
+			go w.runFSWatcher(fsWatcher)
+			return w.Next()
+		}
+	}
+
+	w.mu.Lock()
+	w.polling = true
+	w.mu.Unlock()
+	go w.runPoller()
+	return w.Next()
+}
+
+// Next returns a command that waits for the next batch of changes.
+func (w *Watcher) Next() tea.Cmd {
+	msgs := w.msgs
+	done := w.done
+	return func() tea.Msg {
+		select {
+		case msg := <-msgs:
+			return msg
+		case <-done:
+			return nil
+		}
+	}
+}
+
+// Close stops watching. Pending changes are dropped.
+func (w *Watcher) Close() {
+	w.closeOnce.Do(func() {
+		close(w.done)
+		w.mu.Lock()
+		if w.timer != nil {
+			w.timer.Stop()
+		}
+		w.mu.Unlock()
+	})
+}
+
+func (w *Watcher) newFSWatcher() (*fsnotify.Watcher, error) {
+	fsWatcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	// The root is watched so category directories created later are picked up
+	if err := fsWatcher.Add(w.root); err != nil {
+		fsWatcher.Close()
+		return nil, err
+	}
+	for _, category := range w.categories {
+		if err := w.addTree(fsWatcher, filepath.Join(w.root, category)); err != nil {
+			fsWatcher.Close()
+			return nil, err
+		}
+	}
+	return fsWatcher, nil
+}
+
+// addTree watches a directory and every directory below it; fsnotify is not recursive.
+func (w *Watcher) addTree(fsWatcher *fsnotify.Watcher, dir string) error {
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return nil
+	}
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Unreadable directories are reported by the template loader
+			return nil
+		}
+		if d.IsDir() {
+			return fsWatcher.Add(path)
+		}
+		return nil
+	})
+}
+
+func (w *Watcher) runFSWatcher(fsWatcher *fsnotify.Watcher) {
+	defer fsWatcher.Close()
+
+	for {
+		select {
+		case <-w.done:
+			return
+		case event, ok := <-fsWatcher.Events:
+			if !ok {
+				return
+			}
+			if event.Has(fsnotify.Create) {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					w.addTree(fsWatcher, event.Name)
+				}
+			}
+			if dir := w.templateDir(event.Name); dir != "" {
+				w.queue(dir)
+			}
+		case _, ok := <-fsWatcher.Errors:
+			if !ok {
+				return
+			}
+		}
+	}
+}
+
+func (w *Watcher) runPoller() {
+	previous := w.snapshot()
+	ticker := time.NewTicker(w.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+			current := w.snapshot()
+			for path, state := range current {
+				if previous[path] != state {
+					w.queueTemplateOf(path)
+				}
+			}
+			for path := range previous {
+				if _, exists := current[path]; !exists {
+					w.queueTemplateOf(path)
+				}
+			}
+			previous = current
+		}
+	}
+}
+
+func (w *Watcher) queueTemplateOf(path string) {
+	if dir := w.templateDir(path); dir != "" {
+		w.queue(dir)
+	}
+}
+
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// snapshot records the modification time and size of every file below the category directories.
+func (w *Watcher) snapshot() map[string]fileState {
+	files := make(map[string]fileState)
+	for _, category := range w.categories {
+		filepath.WalkDir(filepath.Join(w.root, category), func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+			if d.IsDir() {
+				files[path] = fileState{}
+			} else {
+				files[path] = fileState{modTime: info.ModTime(), size: info.Size()}
+			}
+			return nil
+		})
+	}
+	return files
+}
+
+// templateDir maps a changed path to the directory of the template it belongs to
+// (<root>/<category>/<name>), or "" for paths outside a template.
+func (w *Watcher) templateDir(path string) string {
+	rel, err := filepath.Rel(w.root, path)
+	if err != nil {
+		return ""
+	}
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	if len(parts) < 2 || !w.isCategory(parts[0]) {
+		return ""
+	}
+	return filepath.Join(w.root, parts[0], parts[1])
+}
+
+func (w *Watcher) isCategory(name string) bool {
+	for _, category := range w.categories {
+		if category == name {
+			return true
+		}
+	}
+	return false
+}
+
+// queue adds a template directory to the pending changes and restarts the debounce timer.
+func (w *Watcher) queue(dir string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.pending[dir] = true
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+	w.timer = time.AfterFunc(w.debounce, w.flush)
+}
+
+func (w *Watcher) flush() {
+	w.mu.Lock()
+	if len(w.pending) == 0 {
+		w.mu.Unlock()
+		return
+	}
+	dirs := make([]string, 0, len(w.pending))
+	for dir := range w.pending {
+		dirs = append(dirs, dir)
+	}
+	w.pending = make(map[string]bool)
+	w.mu.Unlock()
+
+	sort.Strings(dirs)
+	select {
+	case w.msgs <- ChangesMsg{Dirs: dirs}:
+	case <-w.done:
+	}
+}
@@ -0,0 +1,98 @@
+package watcher
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func setupRepo(t *testing.T) string {
+	root := t.TempDir()
+	for _, dir := range []string{"account_templates/at_1/text_parts", "shared_parts/sp_1"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func waitForMsg(t *testing.T, cmd tea.Cmd) tea.Msg {
+	t.Helper()
+	msgs := make(chan tea.Msg, 1)
+	go func() { msgs <- cmd() }()
+	select {
+	case msg := <-msgs:
+		return msg
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for changes")
+		return nil
+	}
+}
+
+func TestWatcherReportsChangedTemplates(t *testing.T) {
+	for _, polling := range []bool{false, true} {
+		root := setupRepo(t)
+		w := NewWatcher(root, []string{"account_templates", "shared_parts"})
+		w.SetDebounce(50 * time.Millisecond)
+		w.SetPollInterval(20 * time.Millisecond)
+		cmd := w.Start(polling)
+
+		// Give the poller its first snapshot
+		time.Sleep(50 * time.Millisecond)
+
+		// Several writes within the debounce delay are reported once
+		files := []string{
+			"account_templates/at_1/config.json",
+			"account_templates/at_1/text_parts/part_1.liquid",
+			"shared_parts/sp_1/sp_1.liquid",
+		}
+		for _, file := range files {
+			if err := os.WriteFile(filepath.Join(root, file), []byte("{}"), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		msg, ok := waitForMsg(t, cmd).(ChangesMsg)
+		if !ok {
+			t.Fatalf("polling=%v: expected ChangesMsg, got %T", polling, msg)
+		}
+		expected := []string{filepath.Join(root, "account_templates/at_1"), filepath.Join(root, "shared_parts/sp_1")}
+		if !reflect.DeepEqual(msg.Dirs, expected) {
+			t.Errorf("polling=%v: expected %v, got %v", polling, expected, msg.Dirs)
+		}
+		if w.Polling() != polling {
+			t.Errorf("polling=%v: Polling() = %v", polling, w.Polling())
+		}
+
+		w.Close()
+		if msg := waitForMsg(t, w.Next()); msg != nil {
+			t.Errorf("polling=%v: expected nil after Close, got %v", polling, msg)
+		}
+	}
+}
+
+func TestTemplateDir(t *testing.T) {
+	w := NewWatcher("repo", []string{"account_templates"})
+
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"repo/account_templates/at_1/config.json", "repo/account_templates/at_1"},
+		{"repo/account_templates/at_1/text_parts/part_1.liquid", "repo/account_templates/at_1"},
+		{"repo/account_templates/at_1", "repo/account_templates/at_1"},
+		{"repo/account_templates", ""},
+		{"repo/other/at_1/config.json", ""},
+		{"elsewhere/account_templates/at_1", ""},
+	}
+
+	for _, test := range tests {
+		if result := w.templateDir(test.path); result != test.expected {
+			t.Errorf("templateDir(%s) = %q, expected %q", test.path, result, test.expected)
+		}
+	}
+}
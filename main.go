@@ -1,21 +1,56 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
 
 	tea "github.com/charmbracelet/bubbletea"
 
 	"github.com/rufex/sftui/internal/app"
+	"github.com/rufex/sftui/internal/logging"
 )
 
 func main() {
+	os.Exit(run())
+}
+
+// run starts sftui and returns its exit code, so the deferred cleanup runs on every path.
+func run() int {
+	firm := flag.String("firm", "", "firm ID to use for this session instead of the repository default")
+	partner := flag.String("partner", "", "partner ID to work with in partner mode for this session")
+	flag.Parse()
+	if *firm != "" && *partner != "" {
+		fmt.Fprintln(os.Stderr, "Error: --firm and --partner cannot be combined")
+		return 2
+	}
+
 	application := app.New()
+	if err := application.OpenLogFile(logging.DefaultPath); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not open log file: %v\n", err)
+	}
+	defer application.CloseLogFile()
+	if err := application.OpenRepoHistory(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not load edit history: %v\n", err)
+	}
+	defer application.StopWatcher()
 	application.InitialModel()
+	var err error
+	switch {
+	case *firm != "":
+		err = application.SetFirm(*firm)
+	case *partner != "":
+		err = application.SetPartner(*partner)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
 
 	p := tea.NewProgram(application, tea.WithAltScreen())
 	if _, err := p.Run(); err != nil {
 		fmt.Printf("Error running program: %v", err)
-		os.Exit(1)
+		return 1
 	}
+	return 0
 }
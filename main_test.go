@@ -1,8 +1,13 @@
 package main
 
 import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/rufex/sftui/internal/models"
 	"github.com/rufex/sftui/internal/navigation"
@@ -57,7 +62,7 @@ func TestGetCategoryDisplayName(t *testing.T) {
 
 func TestLoadTemplates(t *testing.T) {
 	manager := template.NewManager()
-	templates := manager.LoadTemplates()
+	templates, _ := manager.LoadTemplates()
 
 	if len(templates) != 12 {
 		t.Errorf("Expected 12 templates from fixtures, got %d", len(templates))
@@ -111,7 +116,7 @@ func TestFuzzyMatch(t *testing.T) {
 
 func TestFilterTemplates(t *testing.T) {
 	manager := template.NewManager()
-	templates := manager.LoadTemplates()
+	templates, _ := manager.LoadTemplates()
 
 	tests := []struct {
 		query    string
@@ -132,6 +137,47 @@ func TestFilterTemplates(t *testing.T) {
 	}
 }
 
+func TestGetTextParts(t *testing.T) {
+	manager := template.NewManager()
+	tmpl := models.Template{
+		Config: map[string]interface{}{
+			"text_parts": map[string]interface{}{
+				"zeta":  "text_parts/zeta.liquid",
+				"alpha": "text_parts/alpha.liquid",
+				"bad":   42,
+			},
+		},
+	}
+
+	parts := manager.GetTextParts(tmpl)
+	if len(parts) != 2 {
+		t.Fatalf("Expected 2 text parts, got %d", len(parts))
+	}
+	if parts[0].Name != "alpha" || parts[1].Name != "zeta" {
+		t.Errorf("Expected text parts sorted by name, got %v", parts)
+	}
+}
+
+func TestGetMainLiquidFile(t *testing.T) {
+	manager := template.NewManager()
+
+	tests := []struct {
+		template models.Template
+		expected string
+	}{
+		{models.Template{Name: "rt", Category: "reconciliation_texts", Config: map[string]interface{}{"text": "custom.liquid"}}, "custom.liquid"},
+		{models.Template{Name: "account_1", Category: "account_templates", Config: map[string]interface{}{}}, "main.liquid"},
+		{models.Template{Name: "shared_part_1", Category: "shared_parts", Config: map[string]interface{}{}}, "shared_part_1.liquid"},
+	}
+
+	for _, test := range tests {
+		result := manager.GetMainLiquidFile(test.template)
+		if result != test.expected {
+			t.Errorf("GetMainLiquidFile(%s) = %s, expected %s", test.template.Name, result, test.expected)
+		}
+	}
+}
+
 // Tests for navigation handler functionality
 func TestNavigationHandler(t *testing.T) {
 	handler := navigation.NewHandler()
@@ -191,7 +237,7 @@ func TestTemplateNavigation(t *testing.T) {
 	handler := navigation.NewHandler()
 	m := &models.Model{
 		CurrentSection:    models.TemplatesSection,
-		FilteredTemplates: []int{0, 1, 2, 3, 4},
+		FilteredTemplates: []models.TemplateID{{Name: "t0"}, {Name: "t1"}, {Name: "t2"}, {Name: "t3"}, {Name: "t4"}},
 		SelectedTemplate:  0,
 		TemplatesOffset:   0,
 		Height:            24,
@@ -224,9 +270,12 @@ func TestUIRenderer(t *testing.T) {
 			{Name: "test1", Category: "account_templates"},
 			{Name: "test2", Category: "export_files"},
 		},
-		FilteredTemplates: []int{0, 1},
+		FilteredTemplates: []models.TemplateID{
+			{Category: "account_templates", Name: "test1"},
+			{Category: "export_files", Name: "test2"},
+		},
 		SelectedTemplate:  0,
-		SelectedTemplates: make(map[int]bool),
+		SelectedTemplates: make(map[models.TemplateID]bool),
 	}
 
 	// Test templates view
@@ -270,7 +319,7 @@ func TestTruncateText(t *testing.T) {
 func TestActionPopup(t *testing.T) {
 	renderer := ui.NewRenderer()
 	m := &models.Model{
-		SelectedTemplates: map[int]bool{0: true, 1: true},
+		SelectedTemplates: map[models.TemplateID]bool{{Name: "a"}: true, {Name: "b"}: true},
 		SelectedAction:    0,
 		Width:             80,
 		Height:            24,
@@ -283,6 +332,9 @@ func TestActionPopup(t *testing.T) {
 	if !strings.Contains(popup, "> create") {
 		t.Errorf("Action popup should highlight 'create' action")
 	}
+	if !strings.Contains(popup, "Press ENTER to select, ESC to cancel") {
+		t.Errorf("Action popup should show the select hint")
+	}
 }
 
 func TestHelpView(t *testing.T) {
@@ -356,6 +408,15 @@ func TestLoadFirmOptions(t *testing.T) {
 	if !hasPartner {
 		t.Errorf("Expected at least one partner option")
 	}
+
+	// Firms come before partners, each ordered by ID
+	var ids []string
+	for _, option := range firmOptions {
+		ids = append(ids, option.ID)
+	}
+	if order := strings.Join(ids, ","); order != "1001,1002,25,26" {
+		t.Errorf("Expected firm options ordered 1001,1002,25,26, got %s", order)
+	}
 }
 
 func TestFirmPopupView(t *testing.T) {
@@ -382,7 +443,7 @@ func TestFirmPopupView(t *testing.T) {
 
 	view = renderer.FirmPopupView(m)
 
-	if !strings.Contains(view, "Select Default Firm/Partner") {
+	if !strings.Contains(view, "Select Firm/Partner") {
 		t.Errorf("Expected popup title")
 	}
 
@@ -398,17 +459,31 @@ func TestFirmPopupView(t *testing.T) {
 		t.Errorf("Expected navigation instructions")
 	}
 
-	if !strings.Contains(view, "Press ENTER to select, ESC to cancel") {
+	if !strings.Contains(view, "ENTER for this session, d to save as default") {
 		t.Errorf("Expected action instructions")
 	}
 }
 
 func TestSetHost(t *testing.T) {
+	// Work on a copy of the fixture so the test does not change the tree
+	fixture, err := os.ReadFile(filepath.Join("fixtures", "silverfin", "config.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	if err := os.MkdirAll(filepath.Join(home, ".silverfin"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(home, ".silverfin", "config.json"), fixture, 0644); err != nil {
+		t.Fatal(err)
+	}
+
 	configManager := template.NewConfigManager()
 
 	// Test setting a new host
 	newHost := "https://new-test-host.com"
-	err := configManager.SetHost(newHost)
+	err = configManager.SetHost(newHost)
 
 	if err != nil {
 		t.Errorf("Expected no error setting host, got %v", err)
@@ -422,6 +497,95 @@ func TestSetHost(t *testing.T) {
 	}
 }
 
+// writeTextPartTemplate creates a template with two text parts in a temp directory
+func writeTextPartTemplate(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"config.json":              `{"handle": "rt", "text_parts": {"part_1": "text_parts/part_1.liquid", "part_2": "text_parts/part_2.liquid"}}`,
+		"main.liquid":              "{% include \"parts/part_1\" %}\n{% include 'parts/part_2' %}\n",
+		"text_parts/part_1.liquid": "Part 1\n",
+		"text_parts/part_2.liquid": "{%- include \"parts/part_1\" -%}\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestRenameTextPart(t *testing.T) {
+	dir := writeTextPartTemplate(t)
+	configManager := template.NewConfigManager()
+	if err := os.Chmod(filepath.Join(dir, "main.liquid"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := template.NewManager().LoadTemplate(dir, "reconciliation_texts")
+	updated, err := configManager.RenameTextPart(loaded, "part_1", "intro", "text_parts/sections/intro.liquid")
+	if err != nil {
+		t.Fatalf("Expected no error renaming text part, got %v", err)
+	}
+	if updated != 2 {
+		t.Errorf("Expected includes updated in 2 files, got %d", updated)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "text_parts/sections/intro.liquid")); err != nil {
+		t.Errorf("Expected text part file to be moved: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "text_parts/part_1.liquid")); !os.IsNotExist(err) {
+		t.Errorf("Expected old text part file to be removed")
+	}
+
+	config, _ := os.ReadFile(filepath.Join(dir, "config.json"))
+	if !strings.Contains(string(config), `"intro": "text_parts/sections/intro.liquid"`) || strings.Contains(string(config), `"part_1"`) {
+		t.Errorf("Expected config.json to register the renamed part, got %s", config)
+	}
+
+	main, _ := os.ReadFile(filepath.Join(dir, "main.liquid"))
+	if !strings.Contains(string(main), `{% include "parts/intro" %}`) || !strings.Contains(string(main), `'parts/part_2'`) {
+		t.Errorf("Expected main.liquid include to be rewritten, got %s", main)
+	}
+	if info, _ := os.Stat(filepath.Join(dir, "main.liquid")); info.Mode().Perm() != 0600 {
+		t.Errorf("Expected main.liquid to keep mode 0600, got %v", info.Mode().Perm())
+	}
+	part2, _ := os.ReadFile(filepath.Join(dir, "text_parts/part_2.liquid"))
+	if !strings.Contains(string(part2), `{%- include "parts/intro" -%}`) {
+		t.Errorf("Expected part_2 include to be rewritten, got %s", part2)
+	}
+}
+
+func TestRenameTextPartCollisions(t *testing.T) {
+	tests := []struct {
+		name    string
+		newName string
+		newPath string
+	}{
+		{"existing name", "part_2", "text_parts/other.liquid"},
+		{"existing path", "other", "text_parts/part_2.liquid"},
+		{"empty name", "", "text_parts/other.liquid"},
+		{"outside template", "other", "../other.liquid"},
+	}
+
+	for _, test := range tests {
+		dir := writeTextPartTemplate(t)
+		configManager := template.NewConfigManager()
+
+		loaded := template.NewManager().LoadTemplate(dir, "reconciliation_texts")
+		if _, err := configManager.RenameTextPart(loaded, "part_1", test.newName, test.newPath); err == nil {
+			t.Errorf("%s: expected an error", test.name)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "text_parts/part_1.liquid")); err != nil {
+			t.Errorf("%s: expected text part file to stay in place", test.name)
+		}
+	}
+}
+
 func TestHostPopupViewWithTextInput(t *testing.T) {
 	renderer := ui.NewRenderer()
 	m := &models.Model{
@@ -454,7 +618,7 @@ func TestHostPopupViewWithTextInput(t *testing.T) {
 
 func TestDetailsNavigation(t *testing.T) {
 	manager := template.NewManager()
-	templates := manager.LoadTemplates()
+	templates, _ := manager.LoadTemplates()
 
 	// Find a reconciliation_text template for testing
 	reconciliationIndex := -1
@@ -474,7 +638,7 @@ func TestDetailsNavigation(t *testing.T) {
 		Templates:         templates,
 		CurrentSection:    models.DetailsSection,
 		SelectedTemplate:  0,
-		FilteredTemplates: []int{reconciliationIndex},
+		FilteredTemplates: []models.TemplateID{templates[reconciliationIndex].ID()},
 	}
 
 	// Test initial state
@@ -519,7 +683,7 @@ func TestDetailsNavigationWithoutReconciliationType(t *testing.T) {
 
 	m := &models.Model{
 		Templates:         templates,
-		FilteredTemplates: []int{0},
+		FilteredTemplates: []models.TemplateID{templates[0].ID()},
 		CurrentSection:    models.DetailsSection,
 	}
 
@@ -535,7 +699,7 @@ func TestDetailsNavigationWithoutReconciliationType(t *testing.T) {
 
 func TestDetailsHighlighting(t *testing.T) {
 	manager := template.NewManager()
-	templates := manager.LoadTemplates()
+	templates, _ := manager.LoadTemplates()
 
 	// Find a reconciliation_text template
 	reconciliationIndex := -1
@@ -554,7 +718,7 @@ func TestDetailsHighlighting(t *testing.T) {
 	m := &models.Model{
 		Templates:           templates,
 		CurrentSection:      models.DetailsSection,
-		FilteredTemplates:   []int{reconciliationIndex},
+		FilteredTemplates:   []models.TemplateID{templates[reconciliationIndex].ID()},
 		SelectedTemplate:    0,
 		SelectedDetailField: 0,
 	}
@@ -577,3 +741,636 @@ func TestDetailsHighlighting(t *testing.T) {
 		t.Errorf("Expected details view to contain reconciliation_type field even when not active")
 	}
 }
+
+func TestAddDuplicateDeleteTextPart(t *testing.T) {
+	dir := writeTextPartTemplate(t)
+	configManager := template.NewConfigManager()
+	// Text part edits are based on the template as loaded, so load it right before each one
+	loaded := func() models.Template { return template.NewManager().LoadTemplate(dir, "reconciliation_texts") }
+
+	if err := configManager.AddTextPart(loaded(), "part_1", "text_parts/new.liquid"); err == nil {
+		t.Errorf("Expected an error adding a text part with an existing name")
+	}
+	stale := loaded()
+	if err := configManager.AddTextPart(loaded(), "footer", "text_parts/extra/footer.liquid"); err != nil {
+		t.Fatalf("Expected no error adding text part, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "text_parts/extra/footer.liquid")); err != nil {
+		t.Errorf("Expected new text part file to be created: %v", err)
+	}
+
+	if err := configManager.AddTextPart(stale, "header", "text_parts/header.liquid"); !errors.Is(err, template.ErrConfigModified) {
+		t.Errorf("Expected ErrConfigModified adding to a template loaded before the last change, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "text_parts/header.liquid")); !os.IsNotExist(err) {
+		t.Errorf("Expected no text part file to be created on a conflict")
+	}
+
+	if err := configManager.DuplicateTextPart(loaded(), "part_1", "part_1_copy", "text_parts/part_1_copy.liquid"); err != nil {
+		t.Fatalf("Expected no error duplicating text part, got %v", err)
+	}
+	content, _ := os.ReadFile(filepath.Join(dir, "text_parts/part_1_copy.liquid"))
+	if string(content) != "Part 1\n" {
+		t.Errorf("Expected duplicated content, got %q", content)
+	}
+
+	includedFrom, err := configManager.TextPartIncludedFrom(dir, "part_1")
+	if err != nil || len(includedFrom) != 2 {
+		t.Errorf("Expected part_1 to be included from 2 files, got %v (%v)", includedFrom, err)
+	}
+
+	if err := configManager.DeleteTextPart(loaded(), "part_1"); err != nil {
+		t.Fatalf("Expected no error deleting text part, got %v", err)
+	}
+	config, _ := os.ReadFile(filepath.Join(dir, "config.json"))
+	if strings.Contains(string(config), `"part_1"`) {
+		t.Errorf("Expected part_1 to be removed from config.json, got %s", config)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "text_parts/part_1.liquid")); !os.IsNotExist(err) {
+		t.Errorf("Expected part_1 file to be deleted")
+	}
+}
+
+func TestMoveTextPart(t *testing.T) {
+	dir := t.TempDir()
+	config := "{\n  \"handle\": \"rt\",\n  \"text_parts\": {\n    \"intro\": \"text_parts/intro.liquid\",\n    \"body\": \"text_parts/body.liquid\",\n    \"footer\": \"text_parts/footer.liquid\"\n  }\n}\n"
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	manager := template.NewManager()
+	configManager := template.NewConfigManager()
+	loaded := func() models.Template { return manager.LoadTemplate(dir, "reconciliation_texts") }
+
+	tests := []struct {
+		name     string
+		part     string
+		delta    int
+		expected []string
+	}{
+		{name: "listed in file order", expected: []string{"intro", "body", "footer"}},
+		{name: "down", part: "intro", delta: 1, expected: []string{"body", "intro", "footer"}},
+		{name: "up", part: "footer", delta: -1, expected: []string{"body", "footer", "intro"}},
+		{name: "stays first", part: "body", delta: -1, expected: []string{"body", "footer", "intro"}},
+		{name: "stays last", part: "intro", delta: 1, expected: []string{"body", "footer", "intro"}},
+	}
+
+	for _, test := range tests {
+		if test.part != "" {
+			if err := configManager.MoveTextPart(loaded(), test.part, test.delta); err != nil {
+				t.Fatalf("%s: MoveTextPart returned error: %v", test.name, err)
+			}
+		}
+		var names []string
+		for _, part := range manager.GetTextParts(loaded()) {
+			names = append(names, part.Name)
+		}
+		if strings.Join(names, ",") != strings.Join(test.expected, ",") {
+			t.Errorf("%s: got %v, expected %v", test.name, names, test.expected)
+		}
+	}
+
+	expected := "{\n  \"handle\": \"rt\",\n  \"text_parts\": {\n    \"body\": \"text_parts/body.liquid\",\n    \"footer\": \"text_parts/footer.liquid\",\n    \"intro\": \"text_parts/intro.liquid\"\n  }\n}\n"
+	if data, _ := os.ReadFile(filepath.Join(dir, "config.json")); string(data) != expected {
+		t.Errorf("Expected only the text_parts order to change, got %s", data)
+	}
+	if err := configManager.MoveTextPart(loaded(), "missing", 1); err == nil {
+		t.Errorf("Expected an error moving an unknown text part")
+	}
+}
+
+func TestScaffoldTemplate(t *testing.T) {
+	manager := template.NewManager()
+	configManager := template.NewConfigManager()
+	var entries []models.HistoryEntry
+	configManager.SetRecorder(func(entry models.HistoryEntry) { entries = append(entries, entry) })
+	root := t.TempDir()
+
+	tests := []struct {
+		category string
+		handle   string
+		files    []string
+		keys     []string
+	}{
+		{"reconciliation_texts", "new_rt", []string{"main.liquid", "text_parts", "tests/new_rt_liquid_test.yml"}, []string{"handle", "name_en", "name_nl", "reconciliation_type", "text_parts", "test"}},
+		{"account_templates", "new_at", []string{"main.liquid", "text_parts"}, []string{"name_en", "account_range", "text_parts"}},
+		{"export_files", "new_ef", []string{"main.liquid", "text_parts"}, []string{"name", "encoding", "text_parts"}},
+		{"shared_parts", "new_sp", []string{"new_sp.liquid"}, []string{"name", "text", "used_in"}},
+	}
+
+	for i, test := range tests {
+		dir, err := configManager.ScaffoldTemplate(root, template.NewTemplate{
+			Category: test.category,
+			Handle:   test.handle,
+			Names:    map[string]string{"en": "New Template", "nl": "Nieuw sjabloon"},
+		})
+		if err != nil {
+			t.Fatalf("ScaffoldTemplate(%s) returned error: %v", test.category, err)
+		}
+		created := manager.LoadTemplate(dir, test.category)
+
+		// Every file is recorded as added, so undoing the edit removes them
+		if len(entries) != i+1 {
+			t.Fatalf("%s: expected the scaffold to be recorded, got %d entries", test.category, len(entries))
+		}
+		for _, file := range entries[i].Files {
+			if file.Before != nil || file.After == nil {
+				t.Errorf("%s: expected %s to be recorded as added", test.category, file.Path)
+			}
+		}
+
+		if created.Name != test.handle || created.Category != test.category {
+			t.Errorf("Expected template %s/%s, got %s/%s", test.category, test.handle, created.Category, created.Name)
+		}
+		for _, file := range test.files {
+			if _, err := os.Stat(filepath.Join(created.Path, file)); err != nil {
+				t.Errorf("%s: expected %s to exist", test.category, file)
+			}
+		}
+		for _, key := range test.keys {
+			if _, ok := created.Config[key]; !ok {
+				t.Errorf("%s: expected config key %s", test.category, key)
+			}
+		}
+	}
+
+	rt := manager.LoadTemplate(filepath.Join(root, "reconciliation_texts", "new_rt"), "reconciliation_texts")
+	if rt.Config["name_fr"] != "New Template" || rt.Config["name_nl"] != "Nieuw sjabloon" {
+		t.Errorf("Expected missing translations to fall back to English, got %v / %v", rt.Config["name_fr"], rt.Config["name_nl"])
+	}
+
+	if _, err := configManager.ScaffoldTemplate(root, template.NewTemplate{Category: "reconciliation_texts", Handle: "new_rt"}); err == nil {
+		t.Errorf("Expected an error scaffolding an existing template")
+	}
+	if _, err := configManager.ScaffoldTemplate(root, template.NewTemplate{Category: "reconciliation_texts", Handle: "bad handle"}); err == nil {
+		t.Errorf("Expected an error for an invalid handle")
+	}
+}
+
+func TestLinkSharedPart(t *testing.T) {
+	dir := t.TempDir()
+	config := `{"name": "shared_part_1", "used_in": [{"type": "reconciliationText", "handle": "rt_1", "id": {"1001": 5}}, {"type": "reconciliationText", "handle": "legacy_dir"}]}`
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	configManager := template.NewConfigManager()
+	rt := models.Template{Name: "rt_1", Category: "reconciliation_texts"}
+	export := models.Template{Name: "export_1", Category: "export_files"}
+	// Reconciliation texts are listed by their handle, which can differ from their directory
+	renamed := models.Template{Name: "rt_2_dir", Category: "reconciliation_texts", Config: map[string]interface{}{"handle": "rt_2"}}
+	legacy := models.Template{Name: "legacy_dir", Category: "reconciliation_texts", Config: map[string]interface{}{"handle": "legacy"}}
+
+	loaded := func() models.Template { return template.NewManager().LoadTemplate(dir, "shared_parts") }
+
+	tests := []struct {
+		name     string
+		edit     func() (bool, error)
+		expected bool
+	}{
+		{name: "already linked", edit: func() (bool, error) { return configManager.LinkSharedPart(loaded(), rt) }},
+		{name: "already linked by directory", edit: func() (bool, error) { return configManager.LinkSharedPart(loaded(), legacy) }},
+		{name: "new link", edit: func() (bool, error) { return configManager.LinkSharedPart(loaded(), export) }, expected: true},
+		{name: "new link by handle", edit: func() (bool, error) { return configManager.LinkSharedPart(loaded(), renamed) }, expected: true},
+		{name: "unlink", edit: func() (bool, error) { return configManager.UnlinkSharedPart(loaded(), rt) }, expected: true},
+		{name: "unlink by directory", edit: func() (bool, error) { return configManager.UnlinkSharedPart(loaded(), legacy) }, expected: true},
+		{name: "unlink not linked", edit: func() (bool, error) { return configManager.UnlinkSharedPart(loaded(), rt) }},
+	}
+	for _, test := range tests {
+		changed, err := test.edit()
+		if err != nil || changed != test.expected {
+			t.Errorf("%s: got %v (%v), expected %v", test.name, changed, err, test.expected)
+		}
+	}
+	if _, err := configManager.LinkSharedPart(loaded(), models.Template{Name: "sp", Category: "shared_parts"}); err == nil {
+		t.Errorf("Expected an error linking a shared part to a shared part")
+	}
+
+	sharedPart := template.NewManager().LoadTemplate(dir, "shared_parts")
+	usedIn, _ := sharedPart.Config["used_in"].([]interface{})
+	if len(usedIn) != 2 {
+		t.Fatalf("Expected 2 used_in entries, got %v", usedIn)
+	}
+	if entry := usedIn[0].(map[string]interface{}); entry["type"] != "exportFile" || entry["handle"] != "export_1" {
+		t.Errorf("Expected exportFile entry for export_1, got %v", entry)
+	}
+	if entry := usedIn[1].(map[string]interface{}); entry["type"] != "reconciliationText" || entry["handle"] != "rt_2" {
+		t.Errorf("Expected reconciliationText entry for handle rt_2, got %v", entry)
+	}
+
+	usage := template.NewRegistry([]models.Template{sharedPart, renamed}).SharedPartsUsage()
+	if parts := usage[renamed.ID()]; len(parts) != 1 || parts[0] != sharedPart.Name {
+		t.Errorf("Expected the handle entry to resolve to %s, got %v", renamed.ID(), usage)
+	}
+}
+
+func TestBuildDependencyGraph(t *testing.T) {
+	manager := template.NewManager()
+	templates, _ := manager.LoadTemplates()
+	rt1ID := models.TemplateID{Category: "reconciliation_texts", Name: "reconciliation_text_1"}
+	rt2ID := models.TemplateID{Category: "reconciliation_texts", Name: "reconciliation_text_2"}
+	usage := map[models.TemplateID][]string{
+		rt1ID: {"shared_part_1"},
+		rt2ID: {"shared_part_1", "shared_part_2"},
+	}
+
+	graph := manager.BuildDependencyGraph(templates, usage)
+
+	rt1 := graph.Links[rt1ID]
+	if len(rt1) != 1 || !rt1[0].InUsedIn || !rt1[0].InLiquid || rt1[0].Mismatch() {
+		t.Errorf("Expected reconciliation_text_1 to consistently use shared_part_1, got %+v", rt1)
+	}
+
+	rt2 := graph.Links[rt2ID]
+	if len(rt2) != 2 || !rt2[0].Mismatch() || rt2[0].InLiquid {
+		t.Errorf("Expected reconciliation_text_2 links to exist only in used_in, got %+v", rt2)
+	}
+
+	nested := graph.Nested["shared_part_2"]
+	if len(nested) != 1 || nested[0].Name != "shared_part_3" {
+		t.Errorf("Expected shared_part_2 to include shared_part_3, got %+v", nested)
+	}
+
+	if unused := graph.UnusedSharedParts(); len(unused) != 0 {
+		t.Errorf("Expected no unused shared parts, got %v", unused)
+	}
+
+	users := template.SharedPartUsers(usage, "shared_part_1")
+	if len(users) != 2 || users[0] != rt1ID {
+		t.Errorf("Expected shared_part_1 to be used by both reconciliation texts, got %v", users)
+	}
+}
+
+func TestGraphRows(t *testing.T) {
+	manager := template.NewManager()
+	renderer := ui.NewRenderer()
+	templates, _ := manager.LoadTemplates()
+	usage := map[models.TemplateID][]string{
+		{Category: "reconciliation_texts", Name: "reconciliation_text_2"}: {"shared_part_2"},
+	}
+
+	rows := renderer.GraphRows(manager.BuildDependencyGraph(templates, usage))
+
+	var labels []string
+	for _, row := range rows {
+		labels = append(labels, row.Prefix+row.Name+" "+row.Note)
+	}
+	graph := strings.Join(labels, "\n")
+
+	for _, expected := range []string{
+		"reconciliation_text_1 ",
+		"└─ shared_part_1 included in Liquid, missing from used_in",
+		"└─ shared_part_2 in used_in, not included in Liquid",
+		"   └─ shared_part_3 ",
+	} {
+		if !strings.Contains(graph, expected) {
+			t.Errorf("Expected graph row %q, got:\n%s", expected, graph)
+		}
+	}
+}
+
+func TestCheckConsistency(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"reconciliation_texts/rt_1/config.json":              `{"text_parts": {"part_1": "text_parts/part_1.liquid"}}`,
+		"reconciliation_texts/rt_1/main.liquid":              `{% include "parts/part_1" %}`,
+		"reconciliation_texts/rt_1/text_parts/part_1.liquid": `{% include "shared/shared_part_2" %}{% include "shared/ghost" %}`,
+		"shared_parts/shared_part_1/config.json":             `{"used_in": [{"type": "reconciliationText", "handle": "rt_1"}, {"type": "accountTemplate", "handle": "gone"}]}`,
+		"shared_parts/shared_part_2/config.json":             `{"used_in": []}`,
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	manager := template.NewManager()
+	var templates []models.Template
+	for _, dir := range []string{"reconciliation_texts/rt_1", "shared_parts/shared_part_1", "shared_parts/shared_part_2"} {
+		category := strings.Split(dir, "/")[0]
+		templates = append(templates, manager.LoadTemplate(filepath.Join(root, dir), category))
+	}
+	rt1 := models.TemplateID{Category: "reconciliation_texts", Name: "rt_1"}
+	gone := models.TemplateID{Category: "account_templates", Name: "gone"}
+	usage := map[models.TemplateID][]string{
+		rt1:  {"shared_part_1"},
+		gone: {"shared_part_1"},
+	}
+
+	issues := manager.CheckConsistency(templates, usage)
+	expected := []models.ConsistencyIssue{
+		{Kind: models.UnknownTemplate, Template: gone, SharedPart: "shared_part_1"},
+		{Kind: models.UnknownSharedPart, Template: rt1, SharedPart: "ghost"},
+		{Kind: models.OrphanLink, Template: rt1, SharedPart: "shared_part_1"},
+		{Kind: models.MissingLink, Template: rt1, SharedPart: "shared_part_2"},
+	}
+	if len(issues) != len(expected) {
+		t.Fatalf("Expected %d issues, got %+v", len(expected), issues)
+	}
+	for i := range expected {
+		if issues[i] != expected[i] {
+			t.Errorf("Issue %d = %+v, expected %+v", i, issues[i], expected[i])
+		}
+	}
+
+	fixed, err := template.NewConfigManager().FixConsistency(templates, issues)
+	if err != nil || fixed != 3 {
+		t.Fatalf("Expected 3 fixes, got %d (%v)", fixed, err)
+	}
+
+	sharedPart1, _ := os.ReadFile(filepath.Join(root, "shared_parts/shared_part_1/config.json"))
+	if strings.Contains(string(sharedPart1), "rt_1") || strings.Contains(string(sharedPart1), "gone") {
+		t.Errorf("Expected orphan links to be removed, got %s", sharedPart1)
+	}
+	sharedPart2, _ := os.ReadFile(filepath.Join(root, "shared_parts/shared_part_2/config.json"))
+	if !strings.Contains(string(sharedPart2), `"handle": "rt_1"`) {
+		t.Errorf("Expected missing link to be added, got %s", sharedPart2)
+	}
+
+	// Fixing again changes nothing, so nothing counts as fixed
+	for i, fixedTemplate := range templates {
+		templates[i] = manager.LoadTemplate(fixedTemplate.Path, fixedTemplate.Category)
+	}
+	if fixed, err := template.NewConfigManager().FixConsistency(templates, issues); err != nil || fixed != 0 {
+		t.Errorf("Expected no fixes on a consistent repository, got %d (%v)", fixed, err)
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		category string
+		config   map[string]interface{}
+		expected []models.ValidationIssue
+	}{
+		{
+			name:     "valid shared part",
+			category: "shared_parts",
+			config:   map[string]interface{}{"name": "sp", "text": "sp.liquid", "used_in": []interface{}{map[string]interface{}{"type": "reconciliationText", "handle": "rt"}}},
+		},
+		{
+			name:     "missing required keys",
+			category: "account_templates",
+			config:   map[string]interface{}{},
+			expected: []models.ValidationIssue{{Field: "text_parts", Message: "required key is missing", Severity: models.LogError}},
+		},
+		{
+			name:     "wrong types",
+			category: "export_files",
+			config:   map[string]interface{}{"text_parts": map[string]interface{}{}, "published": "yes", "id": map[string]interface{}{"firm": 1.0}},
+			expected: []models.ValidationIssue{
+				{Field: "id", Message: `key "firm" is not a numeric ID`, Severity: models.LogError},
+				{Field: "published", Message: "expected true or false, got a string", Severity: models.LogError},
+			},
+		},
+		{
+			name:     "missing translations and bad reconciliation type",
+			category: "reconciliation_texts",
+			config: map[string]interface{}{
+				"handle": "rt", "name_en": "RT", "text": "main.liquid", "text_parts": map[string]interface{}{},
+				"name_nl": "RT", "name_fr": "RT", "name_de": "RT", "reconciliation_type": "sometimes",
+			},
+			expected: []models.ValidationIssue{
+				{Field: "name_es", Message: "translation is missing", Severity: models.LogWarn},
+				{Field: "reconciliation_type", Message: `"sometimes" is not one of [can_be_reconciled_without_data reconciliation_not_necessary only_reconciled_with_data]`, Severity: models.LogError},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		issues := template.ValidateConfig(test.category, test.config)
+		if len(issues) != len(test.expected) {
+			t.Errorf("%s: expected %d issues, got %+v", test.name, len(test.expected), issues)
+			continue
+		}
+		for i := range test.expected {
+			if issues[i] != test.expected[i] {
+				t.Errorf("%s: issue %d = %+v, expected %+v", test.name, i, issues[i], test.expected[i])
+			}
+		}
+	}
+}
+
+func TestLoadTemplateInvalidJSON(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "broken")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(`{"text_parts": `), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := template.NewManager().LoadTemplate(dir, "account_templates")
+	errors, warnings := loaded.ValidationCounts()
+	if errors != 1 || warnings != 0 {
+		t.Fatalf("Expected 1 error and no warnings, got %d and %d", errors, warnings)
+	}
+	if loaded.Validation[0].Field != "config.json" || !strings.HasPrefix(loaded.Validation[0].Message, "invalid JSON") {
+		t.Errorf("Unexpected issue %+v", loaded.Validation[0])
+	}
+
+	m := &models.Model{
+		Templates:         []models.Template{loaded},
+		FilteredTemplates: []models.TemplateID{loaded.ID()},
+		SelectedTemplates: make(map[models.TemplateID]bool),
+	}
+	renderer := ui.NewRenderer()
+	if view := renderer.TemplatesView(m); !strings.Contains(view, "✗1") {
+		t.Errorf("Expected error badge in templates view, got %q", view)
+	}
+	if details := renderer.DetailsView(m); !strings.Contains(details, "Problems:") {
+		t.Errorf("Expected Problems section in details view, got %q", details)
+	}
+}
+
+func TestLoadTemplatesProblems(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"account_templates/good/config.json":              `{"text_parts": {"part_1": "text_parts/part_1.liquid"}}`,
+		"account_templates/good/main.liquid":              ``,
+		"account_templates/good/text_parts/part_1.liquid": ``,
+		"account_templates/broken/config.json":            "{\n  \"text_parts\": {,\n}",
+		"export_files/no_liquid/config.json":              `{"text_parts": {"part_1": "text_parts/part_1.liquid"}}`,
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Chdir(root)
+
+	templates, problems := template.NewManager().LoadTemplates()
+	if len(templates) != 3 {
+		t.Fatalf("Expected 3 templates, got %d", len(templates))
+	}
+
+	expected := []models.LoadProblem{
+		{Kind: models.InvalidConfig, Path: "account_templates/broken/config.json", Template: "account_templates/broken", Line: 2, Column: 18},
+		{Kind: models.MissingLiquid, Path: "export_files/no_liquid/main.liquid", Template: "export_files/no_liquid"},
+		{Kind: models.MissingTextPart, Path: "export_files/no_liquid/text_parts/part_1.liquid", Template: "export_files/no_liquid"},
+	}
+	if len(problems) != len(expected) {
+		t.Fatalf("Expected %d problems, got %+v", len(expected), problems)
+	}
+	for i, want := range expected {
+		got := problems[i]
+		if got.Kind != want.Kind || got.Path != want.Path || got.Template != want.Template || got.Line != want.Line || got.Column != want.Column {
+			t.Errorf("Problem %d = %+v, expected %+v", i, got, want)
+		}
+	}
+	if !strings.Contains(problems[0].Message, "line 2, column 18") {
+		t.Errorf("Expected position in invalid JSON message, got %q", problems[0].Message)
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	templates := []models.Template{
+		{Name: "rt_dir", Category: "reconciliation_texts", Config: map[string]interface{}{"handle": "rt_handle"}},
+		{Name: "shared", Category: "account_templates"},
+		{Name: "shared", Category: "export_files"},
+	}
+	registry := template.NewRegistry(templates)
+
+	if found, ok := registry.Get(models.TemplateID{Category: "export_files", Name: "shared"}); !ok || found.Category != "export_files" {
+		t.Errorf("Expected to find export_files/shared, got %+v", found)
+	}
+	if _, ok := registry.Get(models.TemplateID{Category: "export_files", Name: "rt_dir"}); ok {
+		t.Errorf("Expected no template for an ID with the wrong category")
+	}
+	if index := registry.Index(templates[1].ID()); index != 1 {
+		t.Errorf("Index(account_templates/shared) = %d, expected 1", index)
+	}
+
+	if found, ok := registry.Lookup("reconciliation_texts", "rt_handle"); !ok || found.Name != "rt_dir" {
+		t.Errorf("Expected the reconciliation text to be found by its handle, got %+v", found)
+	}
+	if byHandle := registry.ByHandle("shared"); len(byHandle) != 2 {
+		t.Errorf("Expected two templates with handle shared, got %d", len(byHandle))
+	}
+
+	if !registry.Indexes(templates) {
+		t.Errorf("Expected the registry to index the slice it was built from")
+	}
+	if registry.Indexes(append([]models.Template(nil), templates...)) {
+		t.Errorf("Expected a copied slice to need a new registry")
+	}
+}
+
+func TestSortTemplates(t *testing.T) {
+	now := time.Now()
+	templates := []models.Template{
+		{Name: "b_at", Category: "account_templates", ModTime: now.Add(-time.Hour)},
+		{Name: "z_rt", Category: "reconciliation_texts", ModTime: now, Config: map[string]interface{}{"handle": "a"}},
+		{Name: "a_ef", Category: "export_files", ModTime: now.Add(-2 * time.Hour)},
+	}
+	registry := template.NewRegistry(templates)
+	gitStatus := map[models.TemplateID]string{templates[2].ID(): "M"}
+
+	tests := []struct {
+		order    models.TemplateSort
+		expected []string
+	}{
+		{models.SortByCategory, []string{"b_at", "z_rt", "a_ef"}},
+		{models.SortByName, []string{"a_ef", "b_at", "z_rt"}},
+		{models.SortByHandle, []string{"z_rt", "a_ef", "b_at"}},
+		{models.SortByModified, []string{"z_rt", "b_at", "a_ef"}},
+		{models.SortByGitStatus, []string{"a_ef", "b_at", "z_rt"}},
+	}
+
+	for _, test := range tests {
+		ids := []models.TemplateID{templates[1].ID(), templates[2].ID(), templates[0].ID()}
+		template.SortTemplates(registry, ids, test.order, gitStatus)
+
+		var names []string
+		for _, id := range ids {
+			names = append(names, id.Name)
+		}
+		if strings.Join(names, ",") != strings.Join(test.expected, ",") {
+			t.Errorf("Sort by %s = %v, expected %v", test.order, names, test.expected)
+		}
+	}
+}
+
+func TestGroupTemplates(t *testing.T) {
+	ids := []models.TemplateID{
+		{Category: "shared_parts", Name: "a_sp"},
+		{Category: "account_templates", Name: "b_at"},
+		{Category: "shared_parts", Name: "c_sp"},
+		{Category: "account_templates", Name: "d_at"},
+	}
+
+	visible, rows := template.GroupTemplates(ids, map[string]bool{"shared_parts": true})
+	if len(visible) != 2 || visible[0].Name != "b_at" || visible[1].Name != "d_at" {
+		t.Errorf("Expected only the account templates to be visible, got %v", visible)
+	}
+
+	expected := []models.TemplateRow{
+		{Category: "account_templates", Count: 2, Template: -1},
+		{Template: 0},
+		{Template: 1},
+		{Category: "shared_parts", Count: 2, Collapsed: true, Template: -1},
+	}
+	if len(rows) != len(expected) {
+		t.Fatalf("Expected %d rows, got %+v", len(expected), rows)
+	}
+	for i := range expected {
+		if rows[i] != expected[i] {
+			t.Errorf("Row %d = %+v, expected %+v", i, rows[i], expected[i])
+		}
+	}
+}
+
+func TestGitStatus(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	root := t.TempDir()
+	t.Chdir(root)
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, output)
+		}
+	}
+	write := func(path, content string) {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("account_templates/clean/config.json", "{}")
+	write("account_templates/changed/config.json", "{}")
+	git("init", "-q")
+	git("add", ".")
+	git("commit", "-q", "-m", "init")
+	write("account_templates/changed/config.json", `{"published": true}`)
+	write("account_templates/added/config.json", "{}")
+
+	manager := template.NewManager()
+	templates, _ := manager.LoadTemplates()
+	status, err := manager.GitStatus(templates)
+	if err != nil {
+		t.Fatalf("GitStatus failed: %v", err)
+	}
+
+	expected := map[string]string{"changed": "M", "added": "??"}
+	if len(status) != len(expected) {
+		t.Errorf("Expected %d changed templates, got %v", len(expected), status)
+	}
+	for id, code := range status {
+		if expected[id.Name] != code {
+			t.Errorf("Status of %s = %q, expected %q", id, code, expected[id.Name])
+		}
+	}
+}